@@ -5,10 +5,7 @@ package aggregator
 
 import (
 	"context"
-	"encoding/json"
-	"io/ioutil"
 	"math"
-	"os"
 	"sort"
 	"strconv"
 	"time"
@@ -29,8 +26,8 @@ type IAggregator interface {
 }
 
 type Config struct {
-	LogLevel       string
-	ManualDataFile string
+	LogLevel    string
+	CacheBucket format.Duration `help:"results for the same query within this time bucket are calculated once and shared by all components, 0 disables the cache"`
 }
 
 type Aggregator struct {
@@ -39,6 +36,7 @@ type Aggregator struct {
 	tsDB         storage.SampleAndChunkQueryable
 	promqlEngine *promql.Engine
 	cfg          Config
+	cache        *cache
 }
 
 func New(
@@ -69,44 +67,75 @@ func New(
 		tsDB:         tsDB,
 		promqlEngine: engine,
 		cfg:          cfg,
+		cache:        newCache(),
 	}, nil
 }
 
-func (self *Aggregator) ManualValue(oracleName string, reqID int64, ts time.Time) (float64, error) {
-	jsonFile, err := os.Open(self.cfg.ManualDataFile)
-	if err != nil {
-		return 0, errors.Wrapf(err, "manual data file read Error")
-	}
-	defer jsonFile.Close()
-	byteValue, _ := ioutil.ReadAll(jsonFile)
-	var result map[string]map[string]map[string]float64
-	err = json.Unmarshal([]byte(byteValue), &result)
+func (self *Aggregator) MedianAt(symbol string, at time.Time) (float64, float64, error) {
+	return self.cached("median", symbol, "", at, func(at time.Time) (float64, float64, error) {
+		return self.medianAt(symbol, at)
+	})
+}
+
+func (self *Aggregator) medianAt(symbol string, at time.Time) (float64, float64, error) {
+	vals, confidence, err := self.valsAtWithConfidence(symbol, at)
 	if err != nil {
-		return 0, errors.Wrap(err, "unmarshal manual data file")
+		return 0, 0, err
 	}
-
-	oracleManualVals, ok := result[oracleName]
-	if !ok {
-		return 0, errors.Wrapf(err, "malformatted json file for oracle:%v", oracleName)
+	if len(vals) == 0 {
+		return 0, 0, errors.Errorf("no vals at:%v", at)
 	}
-	val := oracleManualVals[strconv.FormatInt(reqID, 10)]["VALUE"]
-	if val != 0 {
-		_timestamp := int64(oracleManualVals[strconv.FormatInt(reqID, 10)]["DATE"])
-		timestamp := time.Unix(_timestamp, 0)
-		if ts.After(timestamp) {
-			return 0, errors.Errorf("manual entry value has expired:%v", ts)
-		}
+	median, confidenceM := self.median(vals)
+	if confidenceM < confidence {
+		confidence = confidenceM
 	}
-	return val, nil
+
+	return median, confidence, nil
 }
 
-func (self *Aggregator) MedianAt(symbol string, at time.Time) (float64, float64, error) {
-	vals, confidence, err := self.valsAtWithConfidence(symbol, at)
+// MedianOver returns the median of all values from all sources within the look back period.
+// It is used when the latest values don't reach the required confidence
+// and a longer period is acceptable.
+func (self *Aggregator) MedianOver(symbol string, at time.Time, lookBack time.Duration) (float64, float64, error) {
+	return self.cached("medianOver", symbol, lookBack.String(), at, func(at time.Time) (float64, float64, error) {
+		return self.medianOver(symbol, at, lookBack)
+	})
+}
+
+func (self *Aggregator) medianOver(symbol string, at time.Time, lookBack time.Duration) (float64, float64, error) {
+	resolution, err := self.resolution(symbol, at)
+	if err != nil {
+		return 0, 0, err
+	}
+	if lookBack < resolution {
+		return 0, 0, errors.Errorf("look back:%v shorter than the tracker interval:%v", lookBack, resolution)
+	}
+	query, err := self.promqlEngine.NewInstantQuery(
+		self.tsDB,
+		index.ValueMetricName+`{symbol="`+format.SanitizeMetricName(symbol)+`"}[`+lookBack.String()+`]`,
+		at,
+	)
 	if err != nil {
 		return 0, 0, err
 	}
+	defer query.Close()
+	result := query.Exec(self.ctx)
+	if result.Err != nil {
+		return 0, 0, errors.Wrapf(result.Err, "error evaluating query:%v", query.Statement())
+	}
+	var vals []float64
+	for _, series := range result.Value.(promql.Matrix) {
+		for _, point := range series.Points {
+			vals = append(vals, point.V)
+		}
+	}
 	if len(vals) == 0 {
-		return 0, 0, errors.Errorf("no vals at:%v", at)
+		return 0, 0, errors.Errorf("no vals at:%v, look back:%v", at, lookBack)
+	}
+
+	confidence, err := self.confidence(symbol, at, lookBack, resolution)
+	if err != nil {
+		return 0, 0, err
 	}
 	median, confidenceM := self.median(vals)
 	if confidenceM < confidence {
@@ -116,13 +145,22 @@ func (self *Aggregator) MedianAt(symbol string, at time.Time) (float64, float64,
 	return median, confidence, nil
 }
 
-func (self *Aggregator) MedianAtEOD(symbol string, at time.Time) (float64, float64, error) {
-	d := 24 * time.Hour
-	eod := time.Now().Truncate(d)
+// MedianAtEOD returns the median at the last market close before the given time.
+func (self *Aggregator) MedianAtEOD(symbol string, at time.Time, calendar Calendar) (float64, float64, error) {
+	eod, err := calendar.LastClose(at)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "getting the last market close")
+	}
 	return self.MedianAt(symbol, eod)
 }
 
 func (self *Aggregator) MeanAt(symbol string, at time.Time) (float64, float64, error) {
+	return self.cached("mean", symbol, "", at, func(at time.Time) (float64, float64, error) {
+		return self.meanAt(symbol, at)
+	})
+}
+
+func (self *Aggregator) meanAt(symbol string, at time.Time) (float64, float64, error) {
 	vals, confidence, err := self.valsAtWithConfidence(symbol, at)
 	if err != nil {
 		return 0, 0, err
@@ -167,6 +205,16 @@ func (self *Aggregator) TimeWeightedAvg(
 	symbol string,
 	start time.Time,
 	lookBack time.Duration,
+) (float64, float64, error) {
+	return self.cached("twap", symbol, lookBack.String(), start, func(start time.Time) (float64, float64, error) {
+		return self.timeWeightedAvg(symbol, start, lookBack)
+	})
+}
+
+func (self *Aggregator) timeWeightedAvg(
+	symbol string,
+	start time.Time,
+	lookBack time.Duration,
 ) (float64, float64, error) {
 	resolution, err := self.resolution(symbol, start)
 	if err != nil {
@@ -242,6 +290,18 @@ func (self *Aggregator) VolumWeightedAvg(
 	start time.Time,
 	end time.Time,
 	aggrWindow time.Duration,
+) (float64, float64, error) {
+	window := end.Sub(start)
+	return self.cached("vwap", symbol, window.String()+"/"+aggrWindow.String(), end, func(end time.Time) (float64, float64, error) {
+		return self.volumWeightedAvg(symbol, end.Add(-window), end, aggrWindow)
+	})
+}
+
+func (self *Aggregator) volumWeightedAvg(
+	symbol string,
+	start time.Time,
+	end time.Time,
+	aggrWindow time.Duration,
 ) (float64, float64, error) {
 	_timeWindow := end.Sub(start).Round(time.Minute).Seconds()
 	timeWindow := strconv.Itoa(int(_timeWindow)) + "s"
@@ -395,7 +455,17 @@ func (self *Aggregator) valsAtWithConfidence(symbol string, at time.Time) ([]flo
 		prices = append(prices, price.V)
 	}
 
-	// Confidence level.
+	confidence, err := self.confidence(symbol, at, lookBack, resolution)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return prices, confidence, nil
+}
+
+// confidence returns the percentage of the actual over the max possible data points
+// from all sources within the look back period.
+func (self *Aggregator) confidence(symbol string, at time.Time, lookBack, resolution time.Duration) (float64, error) {
 	query, err := self.promqlEngine.NewInstantQuery(
 		self.tsDB,
 		`avg(
@@ -406,18 +476,44 @@ func (self *Aggregator) valsAtWithConfidence(symbol string, at time.Time) ([]flo
 		at,
 	)
 	if err != nil {
-		return nil, 0, err
+		return 0, err
 	}
 	defer query.Close()
 	confidence := query.Exec(self.ctx)
 	if confidence.Err != nil {
-		return nil, 0, errors.Wrapf(confidence.Err, "error evaluating query:%v", query.Statement())
+		return 0, errors.Wrapf(confidence.Err, "error evaluating query:%v", query.Statement())
 	}
 	if len(confidence.Value.(promql.Vector)) == 0 {
-		return nil, 0, errors.Errorf("no vals for confidence at:%v, query:%v", at, query.Statement())
+		return 0, errors.Errorf("no vals for confidence at:%v, query:%v", at, query.Statement())
 	}
 
-	return prices, confidence.Value.(promql.Vector)[0].V * 100, nil
+	return confidence.Value.(promql.Vector)[0].V * 100, nil
+}
+
+// Sources returns the sorted sources with values for a given symbol within the look back period.
+// A zero look back uses the tracker interval the same way as the methods that use the latest values.
+func (self *Aggregator) Sources(symbol string, at time.Time, lookBack time.Duration) ([]string, error) {
+	if lookBack == 0 {
+		resolution, err := self.resolution(symbol, at)
+		if err != nil {
+			return nil, err
+		}
+		lookBack = time.Duration(resolution + 1e+9)
+	}
+	vals, err := self.valsAt(symbol, at, lookBack)
+	if err != nil {
+		return nil, err
+	}
+	uniq := make(map[string]struct{})
+	for _, val := range vals {
+		uniq[val.Metric.Get("source")] = struct{}{}
+	}
+	sources := make([]string, 0, len(uniq))
+	for source := range uniq {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources, nil
 }
 
 // valsAt returns all vals from all indexes at a given time.
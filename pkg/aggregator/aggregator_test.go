@@ -3,8 +3,197 @@
 
 package aggregator
 
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	promTestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/testutil"
+	"github.com/tellor-io/telliot/pkg/tracker/index"
+)
+
 // TODO Add tests:
 // Check confidence should be 50% when one provider doesn't return any data for the entyre window.
 // Check confidence when one provider returns values much different then the other providers.
 
 // Confidence is not right when the provider has no values at all for the entyre period
+
+// newTestAggregator creates an aggregator with a DB that has
+// the given values for a symbol recorded by a single source every 30 seconds.
+func newTestAggregator(t *testing.T, cfg Config, symbol string, vals map[time.Time]float64) *Aggregator {
+	ctx := context.Background()
+	db, err := tsdb.Open(t.TempDir(), nil, nil, tsdb.DefaultOptions())
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, db.Close()) })
+
+	interval := 30 * time.Second
+	// Append in order as the DB rejects samples far before the first one.
+	var tss []time.Time
+	for ts := range vals {
+		tss = append(tss, ts)
+	}
+	sort.Slice(tss, func(i, j int) bool { return tss[i].Before(tss[j]) })
+
+	appender := db.Appender(ctx)
+	for _, ts := range tss {
+		val := vals[ts]
+		for name, v := range map[string]float64{
+			index.ValueMetricName:    val,
+			index.IntervalMetricName: float64(interval),
+		} {
+			lbls := labels.Labels{
+				labels.Label{Name: "__name__", Value: name},
+				labels.Label{Name: "source", Value: "test"},
+				labels.Label{Name: "domain", Value: "test"},
+				labels.Label{Name: "symbol", Value: symbol},
+			}
+			sort.Sort(lbls)
+			_, err := appender.Append(0, lbls, timestamp(ts), v)
+			testutil.Ok(t, err)
+		}
+	}
+	testutil.Ok(t, appender.Commit())
+
+	aggr, err := New(log.NewNopLogger(), ctx, cfg, db)
+	testutil.Ok(t, err)
+	return aggr
+}
+
+func timestamp(ts time.Time) int64 {
+	return ts.UnixNano() / int64(time.Millisecond)
+}
+
+func TestMedianAtEOD(t *testing.T) {
+	date := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		testutil.Ok(t, err)
+		return ts
+	}
+
+	aggr := newTestAggregator(t, Config{LogLevel: "info"}, "VIXEOD", map[time.Time]float64{
+		date("2021-07-16T00:00:00Z"): 10,
+		date("2021-07-16T20:14:50Z"): 18, // Friday close in New York.
+		date("2021-07-16T21:00:00Z"): 30,
+		date("2021-07-17T00:00:00Z"): 11,
+		date("2021-07-19T20:14:50Z"): 19, // Monday, a holiday in the calendar below.
+		date("2021-07-20T20:14:50Z"): 25,
+	})
+
+	us := Calendar{
+		Close:       "16:15",
+		TimeZone:    "America/New_York",
+		TradingDays: []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
+		Holidays:    []string{"2021-07-19"},
+	}
+
+	type testcase struct {
+		name     string
+		at       time.Time
+		calendar Calendar
+		expected float64
+	}
+	for _, tc := range []testcase{
+		{name: "default is midnight UTC", at: date("2021-07-16T23:59:59Z"), expected: 10},
+		{name: "default is every day", at: date("2021-07-17T12:00:00Z"), expected: 11},
+		{name: "exactly at the close", at: date("2021-07-16T20:15:00Z"), calendar: us, expected: 18},
+		{name: "weekends carry the last close", at: date("2021-07-18T12:00:00Z"), calendar: us, expected: 18},
+		{name: "holidays carry the last close", at: date("2021-07-19T23:00:00Z"), calendar: us, expected: 18},
+		{name: "before the close uses the previous day", at: date("2021-07-20T15:00:00Z"), calendar: us, expected: 18},
+		{name: "after the close", at: date("2021-07-20T21:00:00Z"), calendar: us, expected: 25},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			val, conf, err := aggr.MedianAtEOD("VIXEOD", tc.at, tc.calendar)
+			testutil.Ok(t, err)
+			testutil.Equals(t, tc.expected, val)
+			testutil.Assert(t, conf > 0, "confidence should be positive")
+		})
+	}
+
+	_, err := Calendar{TradingDays: []string{"Funday"}}.LastClose(time.Now())
+	testutil.NotOk(t, err)
+	_, err = Calendar{TimeZone: "Mars/Olympus"}.LastClose(time.Now())
+	testutil.NotOk(t, err)
+}
+
+func TestSources(t *testing.T) {
+	ctx := context.Background()
+	db, err := tsdb.Open(t.TempDir(), nil, nil, tsdb.DefaultOptions())
+	testutil.Ok(t, err)
+	defer func() { testutil.Ok(t, db.Close()) }()
+
+	// Source b stopped reporting half an hour ago.
+	now := time.Date(2021, 7, 16, 12, 0, 0, 0, time.UTC)
+	appender := db.Appender(ctx)
+	for ts := now.Add(-time.Hour); !ts.After(now); ts = ts.Add(30 * time.Second) {
+		for _, source := range []string{"a", "b"} {
+			if source == "b" && ts.After(now.Add(-30*time.Minute)) {
+				continue
+			}
+			for name, v := range map[string]float64{
+				index.ValueMetricName:    2000,
+				index.IntervalMetricName: float64(30 * time.Second),
+			} {
+				lbls := labels.Labels{
+					labels.Label{Name: "__name__", Value: name},
+					labels.Label{Name: "source", Value: source},
+					labels.Label{Name: "domain", Value: source},
+					labels.Label{Name: "symbol", Value: "ETH_USD"},
+				}
+				sort.Sort(lbls)
+				_, err := appender.Append(0, lbls, timestamp(ts), v)
+				testutil.Ok(t, err)
+			}
+		}
+	}
+	testutil.Ok(t, appender.Commit())
+
+	aggr, err := New(log.NewNopLogger(), ctx, Config{LogLevel: "info"}, db)
+	testutil.Ok(t, err)
+
+	sources, err := aggr.Sources("ETH/USD", now, 0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"a"}, sources)
+
+	sources, err = aggr.Sources("ETH/USD", now, time.Hour)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"a", "b"}, sources)
+}
+
+func TestCache(t *testing.T) {
+	at := time.Date(2021, 7, 16, 12, 0, 0, 0, time.UTC)
+	aggr := newTestAggregator(t, Config{LogLevel: "info", CacheBucket: format.Duration{Duration: 10 * time.Second}}, "ETH_USD", map[time.Time]float64{
+		at.Add(-5 * time.Second): 2000,
+		at.Add(25 * time.Second): 2100,
+	})
+
+	misses := promTestutil.ToFloat64(cacheMisses.WithLabelValues("median"))
+	hits := promTestutil.ToFloat64(cacheHits.WithLabelValues("median"))
+
+	// Concurrent calls within the same bucket share a single evaluation.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			val, _, err := aggr.MedianAt("ETH/USD", at.Add(time.Duration(i)*time.Second))
+			testutil.Ok(t, err)
+			testutil.Equals(t, float64(2000), val)
+		}(i)
+	}
+	wg.Wait()
+	testutil.Equals(t, misses+1, promTestutil.ToFloat64(cacheMisses.WithLabelValues("median")))
+	testutil.Equals(t, hits+4, promTestutil.ToFloat64(cacheHits.WithLabelValues("median")))
+
+	// The next bucket is a new evaluation.
+	val, _, err := aggr.MedianAt("ETH/USD", at.Add(30*time.Second))
+	testutil.Ok(t, err)
+	testutil.Equals(t, float64(2100), val)
+	testutil.Equals(t, misses+2, promTestutil.ToFloat64(cacheMisses.WithLabelValues("median")))
+}
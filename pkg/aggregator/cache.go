@@ -0,0 +1,109 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package aggregator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	cacheHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "cache_hits_total",
+		Help:      "The total number of results served from the cache",
+	},
+		[]string{"method"},
+	)
+	cacheMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "cache_misses_total",
+		Help:      "The total number of results calculated from the DB",
+	},
+		[]string{"method"},
+	)
+)
+
+type cacheKey struct {
+	method string
+	symbol string
+	params string
+	bucket int64
+}
+
+type cacheEntry struct {
+	done    chan struct{}
+	created time.Time
+	val     float64
+	conf    float64
+	err     error
+}
+
+type cache struct {
+	mtx     sync.Mutex
+	entries map[cacheKey]*cacheEntry
+}
+
+func newCache() *cache {
+	return &cache{entries: make(map[cacheKey]*cacheEntry)}
+}
+
+// cached returns the result for the time bucket of the given time.
+// The first caller for a bucket calculates the result at the start of the bucket
+// and all concurrent and later callers for the same bucket wait for and reuse it.
+// Errors are cached as well so that a failing query isn't repeated by every caller.
+func (self *Aggregator) cached(
+	method string,
+	symbol string,
+	params string,
+	at time.Time,
+	f func(time.Time) (float64, float64, error),
+) (float64, float64, error) {
+	bucket := self.cfg.CacheBucket.Duration
+	if bucket <= 0 {
+		return f(at)
+	}
+	at = at.Truncate(bucket)
+	key := cacheKey{method: method, symbol: symbol, params: params, bucket: at.UnixNano()}
+
+	self.cache.mtx.Lock()
+	entry, ok := self.cache.entries[key]
+	if !ok {
+		entry = &cacheEntry{done: make(chan struct{}), created: time.Now()}
+		self.cache.entries[key] = entry
+		self.cache.prune(2 * bucket)
+	}
+	self.cache.mtx.Unlock()
+
+	if ok {
+		cacheHits.With(prometheus.Labels{"method": method}).Inc()
+		<-entry.done
+		return entry.val, entry.conf, entry.err
+	}
+
+	cacheMisses.With(prometheus.Labels{"method": method}).Inc()
+	entry.val, entry.conf, entry.err = f(at)
+	close(entry.done)
+	return entry.val, entry.conf, entry.err
+}
+
+// prune removes the completed entries older than the given age.
+// It should be called with the lock held.
+func (self *cache) prune(age time.Duration) {
+	for key, entry := range self.entries {
+		if time.Since(entry.created) < age {
+			continue
+		}
+		select {
+		case <-entry.done:
+			delete(self.entries, key)
+		default:
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package aggregator
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	// Embeds the time zone database so that the calendars work on systems without one.
+	_ "time/tzdata"
+)
+
+// Calendar describes the trading days and the close time of a market.
+// The zero value is a market that closes at midnight UTC every day.
+type Calendar struct {
+	Close       string   `help:"close time in 15:04 format, defaults to 00:00"`
+	TimeZone    string   `help:"IANA time zone of the close time, defaults to UTC"`
+	TradingDays []string `help:"week days in which the market is open, defaults to all"`
+	Holidays    []string `help:"dates in 2006-01-02 format in which the market is closed"`
+}
+
+// maxClosedDays limits how far back to look for the last close.
+const maxClosedDays = 30
+
+// LastClose returns the last market close at or before the given time.
+// Weekends and holidays carry the last close forward.
+func (self Calendar) LastClose(at time.Time) (time.Time, error) {
+	loc := time.UTC
+	if self.TimeZone != "" {
+		var err error
+		loc, err = time.LoadLocation(self.TimeZone)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "loading time zone")
+		}
+	}
+	var closeAt time.Time
+	if self.Close != "" {
+		var err error
+		closeAt, err = time.Parse("15:04", self.Close)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "parsing close time")
+		}
+	}
+	tradingDays := make(map[time.Weekday]bool)
+	for _, day := range self.TradingDays {
+		weekday, err := parseWeekday(day)
+		if err != nil {
+			return time.Time{}, err
+		}
+		tradingDays[weekday] = true
+	}
+	holidays := make(map[string]bool)
+	for _, day := range self.Holidays {
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			return time.Time{}, errors.Wrapf(err, "parsing holiday:%v", day)
+		}
+		holidays[day] = true
+	}
+
+	at = at.In(loc)
+	for i := 0; i <= maxClosedDays; i++ {
+		day := at.AddDate(0, 0, -i)
+		closeTime := time.Date(day.Year(), day.Month(), day.Day(), closeAt.Hour(), closeAt.Minute(), 0, 0, loc)
+		if closeTime.After(at) {
+			continue
+		}
+		if len(tradingDays) > 0 && !tradingDays[closeTime.Weekday()] {
+			continue
+		}
+		if holidays[closeTime.Format("2006-01-02")] {
+			continue
+		}
+		return closeTime, nil
+	}
+	return time.Time{}, errors.Errorf("no market close in the last %v days before:%v", maxClosedDays, at)
+}
+
+func parseWeekday(day string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), day) {
+			return d, nil
+		}
+	}
+	return 0, errors.Errorf("invalid week day:%v", day)
+}
@@ -0,0 +1,338 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package backtest
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/tellor-io/telliot/pkg/aggregator"
+	"github.com/tellor-io/telliot/pkg/contracts/lens"
+	"github.com/tellor-io/telliot/pkg/manual"
+	psrTellor "github.com/tellor-io/telliot/pkg/psr/tellor"
+	"github.com/tellor-io/telliot/pkg/tracker/dispute"
+)
+
+const ComponentName = "backtest"
+
+// Recorded is the name of the results for the PSR values
+// recorded by the dispute tracker at the time of the submits.
+const Recorded = "recorded"
+
+// MethodFailed is the method of the results for which the PSR returned an error.
+const MethodFailed = "failed"
+
+type Config struct {
+	// DisputeThreshold is the percent deviation from the accepted value
+	// above which a value would have been disputable.
+	DisputeThreshold float64
+}
+
+// Result holds the error statistics of a PSR config
+// for a single request ID and aggregation method.
+type Result struct {
+	Config     string
+	ReqID      int64
+	Method     string
+	Samples    int
+	MeanAbsDev float64 // In percents of the accepted value.
+	MaxDev     float64 // In percents of the accepted value.
+	Disputable int
+}
+
+// accepted is an accepted on-chain value and the values recorded by the PSR at the same time.
+type accepted struct {
+	reqID    int64
+	ts       time.Time
+	value    float64
+	recorded float64
+	hasRec   bool
+}
+
+type Backtest struct {
+	logger     log.Logger
+	ctx        context.Context
+	cfg        Config
+	tsDB       storage.Queryable
+	aggregator *aggregator.Aggregator
+	manual     *manual.Store
+}
+
+func New(
+	logger log.Logger,
+	ctx context.Context,
+	cfg Config,
+	tsDB storage.Queryable,
+	aggregator *aggregator.Aggregator,
+	manual *manual.Store,
+) *Backtest {
+	return &Backtest{
+		logger:     log.With(logger, "component", ComponentName),
+		ctx:        ctx,
+		cfg:        cfg,
+		tsDB:       tsDB,
+		aggregator: aggregator,
+		manual:     manual,
+	}
+}
+
+// Run replays all accepted values within the time range through the given PSR configs.
+// The results are sorted by config name, request ID and method.
+func (self *Backtest) Run(from, to time.Time, psrs map[string]psrTellor.Config) ([]Result, error) {
+	vals, err := self.accepted(from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading accepted values")
+	}
+	level.Info(self.logger).Log("msg", "loaded accepted values", "count", len(vals))
+
+	stats := make(statSet)
+	for _, val := range vals {
+		if val.hasRec {
+			stats.add(key{config: Recorded, reqID: val.reqID, method: Recorded}, val.value, val.recorded, self.cfg.DisputeThreshold)
+		}
+	}
+
+	for name, cfg := range psrs {
+		onChain := &onChain{vals: vals}
+		psr, err := psrTellor.New(log.NewNopLogger(), cfg, self.aggregator, self.manual, onChain)
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating psr config:%v", name)
+		}
+		for _, val := range vals {
+			onChain.at = val.ts
+			act, info, err := psr.GetValue(val.reqID, val.ts.Add(-dispute.ReorgEventWait))
+			if err != nil {
+				level.Debug(self.logger).Log("msg", "psr value", "config", name, "reqID", val.reqID, "ts", val.ts, "err", err)
+				stats.fail(key{config: name, reqID: val.reqID, method: MethodFailed})
+				continue
+			}
+			stats.add(key{config: name, reqID: val.reqID, method: info.Method}, val.value, float64(act), self.cfg.DisputeThreshold)
+		}
+	}
+
+	var results []Result
+	for k, s := range stats {
+		r := Result{
+			Config:     k.config,
+			ReqID:      k.reqID,
+			Method:     k.method,
+			Samples:    s.samples,
+			MaxDev:     s.maxDev,
+			Disputable: s.disputable,
+		}
+		if s.samples > 0 && k.method != MethodFailed {
+			r.MeanAbsDev = s.sumDev / float64(s.samples)
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Config != results[j].Config {
+			return results[i].Config < results[j].Config
+		}
+		if results[i].ReqID != results[j].ReqID {
+			return results[i].ReqID < results[j].ReqID
+		}
+		return results[i].Method < results[j].Method
+	})
+	return results, nil
+}
+
+// Tellor accepts the median of the values of this many miners for every challenge.
+const minersPerRound = 5
+
+// roundWindow is the max time between the first and the last recorded miner value of a round.
+// The dispute tracker records every event when it is received so
+// the values of a round are a few blocks apart.
+const roundWindow = 2 * time.Minute
+
+// accepted returns the accepted on-chain values recorded by the dispute tracker sorted by time.
+// The miner values for the same request ID are a single round until the round has all miners,
+// a miner submits again or the value is more than the round window after the first one.
+// The accepted value is the median of the round and the round time is the time of its first value.
+func (self *Backtest) accepted(from, to time.Time) ([]accepted, error) {
+	type minerValue struct {
+		miner string
+		ts    int64
+		val   float64
+	}
+	oracle := make(map[int64][]minerValue)
+	err := self.selectSeries(from, to, "oracle_value", func(lbls labels.Labels, ts int64, v float64) error {
+		reqID, err := strconv.ParseInt(lbls.Get("id"), 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "parsing request ID:%v", lbls.Get("id"))
+		}
+		oracle[reqID] = append(oracle[reqID], minerValue{miner: lbls.Get("miner"), ts: ts, val: v})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type round struct {
+		reqID  int64
+		ts     int64
+		miners map[string]bool
+		vals   []float64
+	}
+	var rounds []*round
+	for reqID, mvals := range oracle {
+		sort.Slice(mvals, func(i, j int) bool { return mvals[i].ts < mvals[j].ts })
+		var r *round
+		for _, mv := range mvals {
+			if r == nil || len(r.vals) == minersPerRound || r.miners[mv.miner] || mv.ts-r.ts > roundWindow.Milliseconds() {
+				r = &round{reqID: reqID, ts: mv.ts, miners: make(map[string]bool)}
+				rounds = append(rounds, r)
+			}
+			r.miners[mv.miner] = true
+			r.vals = append(r.vals, mv.val)
+		}
+	}
+
+	type sample struct {
+		reqID int64
+		ts    int64
+	}
+	recorded := make(map[sample]float64)
+	err = self.selectSeries(from, to, "psr_value", func(lbls labels.Labels, ts int64, v float64) error {
+		reqID, err := strconv.ParseInt(lbls.Get("id"), 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "parsing request ID:%v", lbls.Get("id"))
+		}
+		recorded[sample{reqID: reqID, ts: ts}] = v
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var vals []accepted
+	for _, r := range rounds {
+		rec, ok := recorded[sample{reqID: r.reqID, ts: r.ts}]
+		vals = append(vals, accepted{
+			reqID:    r.reqID,
+			ts:       timestamp.Time(r.ts),
+			value:    median(r.vals),
+			recorded: rec,
+			hasRec:   ok,
+		})
+	}
+	sort.Slice(vals, func(i, j int) bool {
+		if !vals[i].ts.Equal(vals[j].ts) {
+			return vals[i].ts.Before(vals[j].ts)
+		}
+		return vals[i].reqID < vals[j].reqID
+	})
+	return vals, nil
+}
+
+func (self *Backtest) selectSeries(from, to time.Time, name string, f func(labels.Labels, int64, float64) error) error {
+	q, err := self.tsDB.Querier(self.ctx, timestamp.FromTime(from), timestamp.FromTime(to))
+	if err != nil {
+		return errors.Wrap(err, "creating querier")
+	}
+	defer q.Close()
+
+	ss := q.Select(false, nil,
+		labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, name),
+		labels.MustNewMatcher(labels.MatchEqual, "contract", "tellor"),
+	)
+	for ss.Next() {
+		series := ss.At()
+		it := series.Iterator()
+		for it.Next() {
+			ts, v := it.At()
+			if err := f(series.Labels(), ts, v); err != nil {
+				return err
+			}
+		}
+		if it.Err() != nil {
+			return errors.Wrapf(it.Err(), "iterating series:%v", series.Labels())
+		}
+	}
+	return errors.Wrapf(ss.Err(), "selecting series:%v", name)
+}
+
+type key struct {
+	config string
+	reqID  int64
+	method string
+}
+
+type stat struct {
+	samples    int
+	sumDev     float64
+	maxDev     float64
+	disputable int
+}
+
+type statSet map[key]*stat
+
+func (self statSet) get(k key) *stat {
+	s, ok := self[k]
+	if !ok {
+		s = &stat{}
+		self[k] = s
+	}
+	return s
+}
+
+func (self statSet) add(k key, accepted, act, threshold float64) {
+	s := self.get(k)
+	var dev float64
+	if accepted != act {
+		dev = math.Abs(act-accepted) / math.Abs(accepted) * 100
+	}
+	s.samples++
+	s.sumDev += dev
+	if dev > s.maxDev {
+		s.maxDev = dev
+	}
+	if dev > threshold {
+		s.disputable++
+	}
+}
+
+func (self statSet) fail(k key) {
+	self.get(k).samples++
+}
+
+func median(vals []float64) float64 {
+	sort.Float64s(vals)
+	mid := len(vals) / 2
+	if len(vals)%2 == 0 {
+		return (vals[mid-1] + vals[mid]) / 2
+	}
+	return vals[mid]
+}
+
+// onChain replays the accepted values as the last on-chain values for the PSR fallbacks.
+type onChain struct {
+	vals []accepted
+	at   time.Time
+}
+
+func (self *onChain) GetLastValues(_ *bind.CallOpts, _dataID *big.Int, _count *big.Int) ([]lens.MainValue, error) {
+	for i := len(self.vals) - 1; i >= 0; i-- {
+		val := self.vals[i]
+		if val.reqID != _dataID.Int64() || !val.ts.Before(self.at) {
+			continue
+		}
+		return []lens.MainValue{{
+			Timestamp: big.NewInt(val.ts.Unix()),
+			Value:     big.NewInt(int64(val.value)),
+		}}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package backtest
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/tellor-io/telliot/pkg/aggregator"
+	"github.com/tellor-io/telliot/pkg/manual"
+	psrTellor "github.com/tellor-io/telliot/pkg/psr/tellor"
+	"github.com/tellor-io/telliot/pkg/testutil"
+	"github.com/tellor-io/telliot/pkg/tracker/dispute"
+	"github.com/tellor-io/telliot/pkg/tracker/index"
+)
+
+func TestBacktest(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	dir := t.TempDir()
+
+	db, err := tsdb.Open(filepath.Join(dir, "db"), nil, nil, tsdb.DefaultOptions())
+	testutil.Ok(t, err)
+	defer func() { testutil.Ok(t, db.Close()) }()
+
+	start := time.Date(2021, 7, 16, 12, 0, 0, 0, time.UTC)
+	rounds := []struct {
+		ts       time.Time
+		index    float64
+		miners   []float64
+		recorded float64
+	}{
+		{ts: start, index: 2010, miners: []float64{2000e6, 2010e6, 2020e6}, recorded: 2000e6},
+		{ts: start.Add(time.Hour), index: 2200, miners: []float64{2000e6, 2000e6, 2000e6}, recorded: 2000e6},
+	}
+
+	appender := db.Appender(ctx)
+	add := func(ts time.Time, v float64, lbls ...string) {
+		l := labels.FromStrings(lbls...)
+		sort.Sort(l)
+		_, err := appender.Append(0, l, timestamp.FromTime(ts), v)
+		testutil.Ok(t, err)
+	}
+	for _, r := range rounds {
+		indexTs := r.ts.Add(-dispute.ReorgEventWait).Add(-10 * time.Second)
+		add(indexTs, r.index, "__name__", index.ValueMetricName, "source", "test", "domain", "test", "symbol", "ETH_USD")
+		add(indexTs, float64(30*time.Second), "__name__", index.IntervalMetricName, "source", "test", "domain", "test", "symbol", "ETH_USD")
+		// The miner events of a round arrive in different blocks.
+		for i, v := range r.miners {
+			add(r.ts.Add(time.Duration(i)*5*time.Second), v, "__name__", "oracle_value", "contract", "tellor", "id", "1", "miner", string(rune('a'+i)))
+		}
+		add(r.ts, r.recorded, "__name__", "psr_value", "contract", "tellor", "id", "1")
+	}
+	testutil.Ok(t, appender.Commit())
+
+	aggr, err := aggregator.New(logger, ctx, aggregator.Config{LogLevel: "info"}, db)
+	testutil.Ok(t, err)
+	manualStore, err := manual.New(logger, manual.Config{LogLevel: "info", File: filepath.Join(dir, "manualData.json"), AuditFile: filepath.Join(dir, "manualDataAudit.log")})
+	testutil.Ok(t, err)
+
+	bt := New(logger, ctx, Config{DisputeThreshold: 5}, db, aggr, manualStore)
+	results, err := bt.Run(start.Add(-time.Minute), start.Add(2*time.Hour), map[string]psrTellor.Config{
+		"current":    {MinConfidence: 70},
+		"impossible": {MinConfidence: 101},
+	})
+	testutil.Ok(t, err)
+
+	testutil.Equals(t, []Result{
+		{Config: "current", ReqID: 1, Method: "default", Samples: 2, MeanAbsDev: 5, MaxDev: 10, Disputable: 1},
+		{Config: "impossible", ReqID: 1, Method: MethodFailed, Samples: 2},
+		{Config: Recorded, ReqID: 1, Method: Recorded, Samples: 2, MeanAbsDev: 0.24875621890547264, MaxDev: 0.4975124378109453},
+	}, results)
+}
@@ -0,0 +1,158 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/logging"
+)
+
+type accountsListCmd struct {
+	cfg
+}
+
+func (self *accountsListCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	accounts, err := ethereum.GetAccounts(cfg.Accounts)
+	if err != nil {
+		return errors.Wrap(err, "getting accounts")
+	}
+
+	for i, account := range accounts {
+		level.Info(logger).Log("msg", "account", "no", i, "address", account.Address.String())
+	}
+
+	return nil
+}
+
+type accountsNewCmd struct {
+	cfg
+}
+
+func (self *accountsNewCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	ks, err := ethereum.NewKeystore(cfg.Accounts)
+	if err != nil {
+		return errors.Wrap(err, "creating keystore")
+	}
+	passphrase, err := ethereum.ReadPassphrase(cfg.Accounts.PassphraseFile, true)
+	if err != nil {
+		return err
+	}
+	account, err := ks.NewAccount(passphrase)
+	if err != nil {
+		return errors.Wrap(err, "creating account")
+	}
+	level.Info(logger).Log("msg", "account created", "address", account.Address.Hex(), "file", account.URL.Path)
+	return nil
+}
+
+type accountsImportCmd struct {
+	cfg
+	KeyFile string `arg:"" optional:"" type:"existingfile" help:"file with the hex encoded private key, the key is prompted for when not set"`
+}
+
+func (self *accountsImportCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	var hexKey string
+	if self.KeyFile != "" {
+		data, err := ioutil.ReadFile(self.KeyFile)
+		if err != nil {
+			return errors.Wrap(err, "read key file")
+		}
+		hexKey = string(data)
+	} else {
+		hexKey, err = prompt.Stdin.PromptPassword("Private key: ")
+		if err != nil {
+			return errors.Wrap(err, "read private key")
+		}
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil {
+		return errors.Wrap(err, "parse private key")
+	}
+
+	ks, err := ethereum.NewKeystore(cfg.Accounts)
+	if err != nil {
+		return errors.Wrap(err, "creating keystore")
+	}
+	passphrase, err := ethereum.ReadPassphrase(cfg.Accounts.PassphraseFile, true)
+	if err != nil {
+		return err
+	}
+	account, err := ks.ImportECDSA(key, passphrase)
+	if err != nil {
+		return errors.Wrap(err, "import private key")
+	}
+	level.Info(logger).Log("msg", "account imported", "address", account.Address.Hex(), "file", account.URL.Path)
+	return nil
+}
+
+type accountsExportCmd struct {
+	cfg
+	addr
+	Out string `required:"" help:"file to write the hex encoded private key to"`
+}
+
+func (self *accountsExportCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	if !common.IsHexAddress(self.Addr) {
+		return errors.Errorf("invalid address:%v", self.Addr)
+	}
+	ks, err := ethereum.NewKeystore(cfg.Accounts)
+	if err != nil {
+		return errors.Wrap(err, "creating keystore")
+	}
+	account, err := ks.Find(accounts.Account{Address: common.HexToAddress(self.Addr)})
+	if err != nil {
+		return errors.Wrapf(err, "finding account:%v", self.Addr)
+	}
+	passphrase, err := ethereum.ReadPassphrase(cfg.Accounts.PassphraseFile, false)
+	if err != nil {
+		return err
+	}
+	key, err := ethereum.DecryptKeystoreFile(account.URL.Path, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(self.Out, []byte(common.Bytes2Hex(crypto.FromECDSA(key))), 0600); err != nil {
+		return errors.Wrap(err, "write private key")
+	}
+	level.Info(logger).Log("msg", "private key exported", "address", account.Address.Hex(), "file", self.Out)
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/tellor-io/telliot/pkg/aggregator"
+	"github.com/tellor-io/telliot/pkg/backtest"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/manual"
+	psrTellor "github.com/tellor-io/telliot/pkg/psr/tellor"
+)
+
+type backtestCmd struct {
+	cfg
+	From             time.Time `required:"" help:"start of the time range in RFC3339 format"`
+	To               time.Time `optional:"" help:"end of the time range in RFC3339 format, defaults to now"`
+	Psr              string    `type:"existingfile" optional:"" help:"json file with named PsrTellor configs to compare, each one overrides the PsrTellor config from the config file"`
+	DisputeThreshold float64   `default:"5" help:"percent deviation from the accepted value above which a value would be disputable"`
+	Db               string    `optional:"" help:"path to the DB, defaults to the Db.Path from the config file, telliot needs to be stopped or use a copy of the DB"`
+}
+
+func (self *backtestCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	to := self.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if !self.From.Before(to) {
+		return errors.New("from should be before to")
+	}
+
+	psrs, err := self.psrConfigs(cfg.PsrTellor)
+	if err != nil {
+		return errors.Wrap(err, "loading psr configs")
+	}
+
+	path := self.Db
+	if path == "" {
+		path = cfg.Db.Path
+	}
+	tsDB, err := tsdb.Open(path, nil, nil, tsdb.DefaultOptions())
+	if err != nil {
+		return errors.Wrap(err, "opening tsdb DB")
+	}
+	defer func() {
+		if err := tsDB.Close(); err != nil {
+			level.Error(logger).Log("msg", "closing the tsdb", "err", err)
+		}
+	}()
+
+	ctx := context.Background()
+	aggregator, err := aggregator.New(logger, ctx, cfg.Aggregator, tsDB)
+	if err != nil {
+		return errors.Wrap(err, "creating aggregator")
+	}
+	manualStore, err := manual.New(logger, cfg.Manual)
+	if err != nil {
+		return errors.Wrap(err, "creating manual values store")
+	}
+
+	bt := backtest.New(logger, ctx, backtest.Config{DisputeThreshold: self.DisputeThreshold}, tsDB, aggregator, manualStore)
+	results, err := bt.Run(self.From, to, psrs)
+	if err != nil {
+		return errors.Wrap(err, "running backtest")
+	}
+	for _, r := range results {
+		level.Info(logger).Log(
+			"msg", "backtest result",
+			"config", r.Config,
+			"id", r.ReqID,
+			"method", r.Method,
+			"samples", r.Samples,
+			"meanAbsDev", r.MeanAbsDev,
+			"maxDev", r.MaxDev,
+			"disputable", r.Disputable,
+		)
+	}
+	return nil
+}
+
+// psrConfigs returns the PSR configs to compare.
+// The config from the config file is always included with the name "current".
+func (self *backtestCmd) psrConfigs(current psrTellor.Config) (map[string]psrTellor.Config, error) {
+	psrs := map[string]psrTellor.Config{"current": current}
+	if self.Psr == "" {
+		return psrs, nil
+	}
+
+	base, err := json.Marshal(current)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal current psr config")
+	}
+	data, err := ioutil.ReadFile(self.Psr)
+	if err != nil {
+		return nil, errors.Wrap(err, "read psr configs file")
+	}
+	var overrides map[string]json.RawMessage
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, errors.Wrap(err, "parse psr configs file")
+	}
+	for name, override := range overrides {
+		var cfg psrTellor.Config
+		if err := json.Unmarshal(base, &cfg); err != nil {
+			return nil, errors.Wrap(err, "copy current psr config")
+		}
+		dec := json.NewDecoder(bytes.NewReader(override))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&cfg); err != nil {
+			return nil, errors.Wrapf(err, "parse psr config:%v", name)
+		}
+		psrs[name] = cfg
+	}
+	return psrs, nil
+}
@@ -4,11 +4,11 @@
 package cli
 
 import (
-	"github.com/go-kit/kit/log/level"
-	"github.com/pkg/errors"
-	"github.com/tellor-io/telliot/pkg/config"
+	"context"
+	"math/big"
+
 	"github.com/tellor-io/telliot/pkg/ethereum"
-	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/transactor"
 )
 
 const VersionMessage = `
@@ -21,9 +21,14 @@ const VersionMessage = `
 var CLI struct {
 	Transfer transferCmd `cmd:"" help:"Transfer tokens"`
 	Approve  approveCmd  `cmd:"" help:"Approve tokens"`
-	Accounts accountsCmd `cmd:"" help:"Show accounts"`
-	Balance  balanceCmd  `cmd:"" help:"Check the balance of an address"`
-	Stake    struct {
+	Accounts struct {
+		List   accountsListCmd   `cmd:"" help:"list the accounts of the configured source"`
+		New    accountsNewCmd    `cmd:"" help:"create a new account in the keystore dir"`
+		Import accountsImportCmd `cmd:"" help:"import a private key into the keystore dir"`
+		Export accountsExportCmd `cmd:"" help:"export the private key of a keystore account"`
+	} `cmd:"" help:"Manage accounts"`
+	Balance balanceCmd `cmd:"" help:"Check the balance of an address"`
+	Stake   struct {
 		Deposit  depositCmd  `cmd:"" help:"deposit a stake"`
 		Request  requestCmd  `cmd:"" help:"request to withdraw stake"`
 		Withdraw withdrawCmd `cmd:"" help:"withdraw stake"`
@@ -35,8 +40,24 @@ var CLI struct {
 		List  listCmd       `cmd:"" help:"list open disputes"`
 		Tally tallyCmd      `cmd:"" help:"tally votes for a dispute ID"`
 	} `cmd:"" help:"Perform commands related to disputes"`
+	Manual struct {
+		Set    manualSetCmd    `cmd:"" help:"add a manual value that overrides the aggregated one"`
+		List   manualListCmd   `cmd:"" help:"list manual values"`
+		Expire manualExpireCmd `cmd:"" help:"expire all active manual values for a data ID"`
+	} `cmd:"" help:"Manage manual override values"`
+	Tx struct {
+		Pending txPendingCmd `cmd:"" help:"list the pending transactions of an account"`
+		Speedup txSpeedupCmd `cmd:"" help:"resend a pending transaction with higher fees"`
+		Cancel  txCancelCmd  `cmd:"" help:"cancel a pending transaction with a 0 value self transfer with the same nonce"`
+	} `cmd:"" help:"Inspect and replace pending transactions"`
+	Submissions struct {
+		List submissionsListCmd `cmd:"" help:"list the received solutions and submit decisions"`
+	} `cmd:"" help:"Show the submissions ledger"`
+	Backtest   backtestCmd   `cmd:"" help:"Replay the recorded oracle values through alternative PSR configs"`
 	Dataserver dataserverCmd `cmd:"" help:"launch only a dataserver instance"`
 	Mine       mineCmd       `cmd:"" help:"Submit data to oracle contracts"`
+	Pool       poolCmd       `cmd:"" help:"Submit data to oracle contracts and hand out the mining work to remote workers"`
+	Worker     workerCmd     `cmd:"" help:"Check the mining work of a pool"`
 	Version    VersionCmd    `cmd:"" help:"Show the CLI version information"`
 }
 
@@ -62,6 +83,18 @@ type cfgGas struct {
 	GasPrice int `optional:"" help:"gas price to use when running the command"`
 }
 
+// dynamicFees returns the fees of dynamic fee transactions
+// calculated the same way as the transactor with the given config.
+func dynamicFees(client *ethereum.Client, cfg transactor.Config) ethereum.DynamicFees {
+	return func(ctx context.Context) (*big.Int, *big.Int, error) {
+		fees, err := transactor.DynamicFees(ctx, client, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return fees.GasFeeCap, fees.GasTipCap, nil
+	}
+}
+
 type cfgGasAddr struct {
 	cfgGas
 	addr
@@ -73,27 +106,3 @@ type cfgAddr struct {
 }
 
 type configPath string
-
-type accountsCmd struct {
-	cfg
-}
-
-func (self *accountsCmd) Run() error {
-	logger := logging.NewLogger()
-
-	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
-	if err != nil {
-		return errors.Wrap(err, "creating config")
-	}
-
-	accounts, err := ethereum.GetAccounts()
-	if err != nil {
-		return errors.Wrap(err, "getting accounts")
-	}
-
-	for i, account := range accounts {
-		level.Info(logger).Log("msg", "account", "no", i, "address", account.Address.String())
-	}
-
-	return nil
-}
@@ -18,6 +18,7 @@ import (
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/manual"
 	psrTellor "github.com/tellor-io/telliot/pkg/psr/tellor"
 	"github.com/tellor-io/telliot/pkg/tracker/dispute"
 	"github.com/tellor-io/telliot/pkg/tracker/index"
@@ -93,11 +94,22 @@ func (self dataserverCmd) Run() error {
 			return errors.Wrap(err, "creating aggregator")
 		}
 
+		// Manual override values.
+		manualStore, err := manual.New(logger, cfg.Manual)
+		if err != nil {
+			return errors.Wrap(err, "creating manual values store")
+		}
+
 		contractTellor, err := contracts.NewITellor(client)
 		if err != nil {
 			return errors.Wrap(err, "create tellor contract instance")
 		}
 
+		psr, err := psrTellor.New(logger, cfg.PsrTellor, aggregator, manualStore, contractTellor)
+		if err != nil {
+			return errors.Wrap(err, "creating tellor psr")
+		}
+
 		disputeTracker, err := dispute.New(
 			logger,
 			ctx,
@@ -105,7 +117,7 @@ func (self dataserverCmd) Run() error {
 			tsDB,
 			client,
 			contractTellor,
-			psrTellor.New(logger, cfg.PsrTellor, aggregator),
+			psr,
 		)
 		if err != nil {
 			return errors.Wrap(err, "creating profit tracker")
@@ -120,7 +132,7 @@ func (self dataserverCmd) Run() error {
 
 		// Web/Api server.
 		{
-			srv, err := web.New(logger, ctx, tsDB, cfg.Web)
+			srv, err := web.New(logger, ctx, tsDB, cfg.Web, manualStore)
 			if err != nil {
 				return errors.Wrap(err, "create web server")
 			}
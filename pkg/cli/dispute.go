@@ -34,7 +34,7 @@ func (self newDisputeCmd) Run() error {
 	logger := logging.NewLogger()
 	ctx := context.Background()
 
-	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	cfg, err := config.ParseConfig(logger, string(self.Config))
 	if err != nil {
 		return errors.Wrap(err, "creating config")
 	}
@@ -44,7 +44,7 @@ func (self newDisputeCmd) Run() error {
 		return errors.Wrap(err, "creating ethereum client")
 	}
 
-	account, err := ethereum.GetAccountByPubAddess(self.Addr)
+	account, err := ethereum.GetAccountByPubAddess(cfg.Accounts, self.Addr)
 	if err != nil {
 		return err
 	}
@@ -78,7 +78,7 @@ func (self newDisputeCmd) Run() error {
 		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
 	}
 
-	auth, err := tEthereum.PrepareEthTransaction(ctx, client, account, gasPrice)
+	auth, err := tEthereum.PrepareEthTransaction(ctx, client, account, gasPrice, dynamicFees(client, cfg.Transactor))
 	if err != nil {
 		return errors.Wrapf(err, "prepare ethereum transaction")
 	}
@@ -101,7 +101,7 @@ func (self voteCmd) Run() error {
 	logger := logging.NewLogger()
 	ctx := context.Background()
 
-	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	cfg, err := config.ParseConfig(logger, string(self.Config))
 	if err != nil {
 		return errors.Wrap(err, "creating config")
 	}
@@ -111,7 +111,7 @@ func (self voteCmd) Run() error {
 		return errors.Wrap(err, "creating ethereum client")
 	}
 
-	account, err := ethereum.GetAccountByPubAddess(self.Addr)
+	account, err := ethereum.GetAccountByPubAddess(cfg.Accounts, self.Addr)
 	if err != nil {
 		return err
 	}
@@ -134,7 +134,7 @@ func (self voteCmd) Run() error {
 		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
 	}
 
-	auth, err := tEthereum.PrepareEthTransaction(ctx, client, account, gasPrice)
+	auth, err := tEthereum.PrepareEthTransaction(ctx, client, account, gasPrice, dynamicFees(client, cfg.Transactor))
 	if err != nil {
 		return errors.Wrapf(err, "prepare ethereum transaction")
 	}
@@ -156,7 +156,7 @@ func (self tallyCmd) Run() error {
 	logger := logging.NewLogger()
 	ctx := context.Background()
 
-	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	cfg, err := config.ParseConfig(logger, string(self.Config))
 	if err != nil {
 		return errors.Wrap(err, "creating config")
 	}
@@ -166,7 +166,7 @@ func (self tallyCmd) Run() error {
 		return errors.Wrap(err, "creating ethereum client")
 	}
 
-	accounts, err := ethereum.GetAccounts()
+	accounts, err := ethereum.GetAccounts(cfg.Accounts)
 	if err != nil {
 		return err
 	}
@@ -180,7 +180,7 @@ func (self tallyCmd) Run() error {
 		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
 	}
 
-	auth, err := tEthereum.PrepareEthTransaction(ctx, client, accounts[0], gasPrice)
+	auth, err := tEthereum.PrepareEthTransaction(ctx, client, accounts[0], gasPrice, dynamicFees(client, cfg.Transactor))
 	if err != nil {
 		return errors.Wrapf(err, "prepare ethereum transaction")
 	}
@@ -213,7 +213,7 @@ func (self listCmd) Run() error {
 	// if err != nil {
 	// 	return errors.Wrap(err, "creating ethereum client")
 	// }
-	// account, err := ethereum.GetAccountByPubAddess(self.Addr)
+	// account, err := ethereum.GetAccountByPubAddess(cfg.Accounts, self.Addr)
 	// if err != nil {
 	// 	return err
 	// }
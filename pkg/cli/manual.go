@@ -0,0 +1,167 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/manual"
+)
+
+type manualID struct {
+	Oracle string `arg:"" required:"" enum:"tellor,tellorMesosphere" help:"the oracle contract name (tellor or tellorMesosphere)"`
+	ID     int64  `arg:"" required:"" help:"the data ID"`
+}
+
+type manualAuthor struct {
+	Author string `required:"" env:"USER" help:"who makes the change, recorded in the audit log"`
+	Reason string `required:"" help:"why the change is made, recorded in the audit log"`
+}
+
+type manualSetCmd struct {
+	cfg
+	manualID
+	manualAuthor
+	Value      float64       `arg:"" required:"" help:"the value to submit instead of the aggregated one"`
+	ValidFrom  time.Time     `optional:"" help:"when the value becomes active in RFC3339 format, defaults to now"`
+	ValidUntil time.Time     `optional:"" help:"when the value expires in RFC3339 format"`
+	ValidFor   time.Duration `optional:"" help:"how long the value is active, used when valid-until is not set"`
+}
+
+func (self *manualSetCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	store, err := manual.New(logger, cfg.Manual)
+	if err != nil {
+		return errors.Wrap(err, "creating manual values store")
+	}
+
+	validFrom := self.ValidFrom
+	if validFrom.IsZero() {
+		validFrom = time.Now()
+	}
+	validUntil := self.ValidUntil
+	if validUntil.IsZero() {
+		if self.ValidFor == 0 {
+			return errors.New("either valid-until or valid-for is required")
+		}
+		validUntil = validFrom.Add(self.ValidFor)
+	}
+
+	entry := manual.Entry{
+		Value:      self.Value,
+		ValidFrom:  validFrom,
+		ValidUntil: validUntil,
+		Author:     self.Author,
+		Reason:     self.Reason,
+	}
+	if err := store.Set(self.Oracle, self.ID, entry); err != nil {
+		return errors.Wrap(err, "set manual value")
+	}
+	return nil
+}
+
+type manualListCmd struct {
+	cfg
+	Audit bool `optional:"" help:"show the audit log instead of the current values"`
+}
+
+func (self *manualListCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	store, err := manual.New(logger, cfg.Manual)
+	if err != nil {
+		return errors.Wrap(err, "creating manual values store")
+	}
+
+	if self.Audit {
+		records, err := store.Audit()
+		if err != nil {
+			return errors.Wrap(err, "get audit log")
+		}
+		for _, r := range records {
+			keyvals := []interface{}{
+				"msg", "audit record",
+				"time", r.Time.Format(time.RFC3339),
+				"action", r.Action,
+				"oracle", r.Oracle,
+				"id", r.ReqID,
+				"author", r.Author,
+				"reason", r.Reason,
+			}
+			if r.Entry != nil {
+				keyvals = append(keyvals,
+					"value", r.Entry.Value,
+					"validFrom", r.Entry.ValidFrom.Format(time.RFC3339),
+					"validUntil", r.Entry.ValidUntil.Format(time.RFC3339),
+				)
+			}
+			level.Info(logger).Log(keyvals...)
+		}
+		return nil
+	}
+
+	overrides, err := store.List()
+	if err != nil {
+		return errors.Wrap(err, "list manual values")
+	}
+	now := time.Now()
+	for _, o := range overrides {
+		level.Info(logger).Log(
+			"msg", "manual value",
+			"oracle", o.Oracle,
+			"id", o.ReqID,
+			"value", o.Value,
+			"active", o.ActiveAt(now),
+			"validFrom", o.ValidFrom.Format(time.RFC3339),
+			"validUntil", o.ValidUntil.Format(time.RFC3339),
+			"author", o.Author,
+			"reason", o.Reason,
+		)
+	}
+	return nil
+}
+
+type manualExpireCmd struct {
+	cfg
+	manualID
+	manualAuthor
+}
+
+func (self *manualExpireCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	store, err := manual.New(logger, cfg.Manual)
+	if err != nil {
+		return errors.Wrap(err, "creating manual values store")
+	}
+
+	count, err := store.Expire(self.Oracle, self.ID, time.Now(), self.Author, self.Reason)
+	if err != nil {
+		return errors.Wrap(err, "expire manual values")
+	}
+	if count == 0 {
+		level.Info(logger).Log("msg", "no active manual values", "oracle", self.Oracle, "id", self.ID)
+	}
+	return nil
+}
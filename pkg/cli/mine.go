@@ -5,9 +5,13 @@ package cli
 
 import (
 	"context"
+	"fmt"
+	"math/big"
+	"runtime"
 	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -20,12 +24,15 @@ import (
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/ethereum"
-	"github.com/tellor-io/telliot/pkg/gasPrice/gasStation"
+	"github.com/tellor-io/telliot/pkg/gasPrice/oracle"
 	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/manual"
 	"github.com/tellor-io/telliot/pkg/mining"
+	"github.com/tellor-io/telliot/pkg/pool"
 	psrTellor "github.com/tellor-io/telliot/pkg/psr/tellor"
 	psrTellorMesosphere "github.com/tellor-io/telliot/pkg/psr/tellorMesosphere"
 	"github.com/tellor-io/telliot/pkg/reward"
+	"github.com/tellor-io/telliot/pkg/submissions"
 	"github.com/tellor-io/telliot/pkg/submitter/tellor"
 	"github.com/tellor-io/telliot/pkg/submitter/tellorMesosphere"
 	"github.com/tellor-io/telliot/pkg/tasker"
@@ -36,14 +43,106 @@ import (
 	"github.com/tellor-io/telliot/pkg/web"
 )
 
+// The subcommands are first so that the docs generator finds them.
 type mineCmd struct {
-	Config configPath `type:"existingfile" help:"path to config file"`
+	Run    mineRunCmd   `cmd:"" default:"1" hidden:"" help:"Submit data to oracle contracts"`
+	Bench  mineBenchCmd `cmd:"" help:"Benchmark the hashers with synthetic challenges"`
+	Config configPath   `type:"existingfile" help:"path to config file"`
 }
 
-func (self mineCmd) Run() error {
+// mineRunCmd is the default subcommand so that mine runs without a subcommand.
+type mineRunCmd struct{}
+
+func (self mineRunCmd) Run(parent *mineCmd) error {
+	return mine(parent.Config, false)
+}
+
+type mineBenchCmd struct {
+	Hashers    []string      `default:"cpu,fastCpu" help:"hashers to benchmark"`
+	Threads    []int         `optional:"" help:"thread counts to benchmark, defaults to 1 and all CPUs"`
+	Difficulty string        `default:"1000000000000" help:"difficulty of the synthetic challenges"`
+	Duration   time.Duration `default:"10s" help:"how long to run each hasher and thread count"`
+}
+
+func (self mineBenchCmd) Run(parent *mineCmd) error {
+	logger := logging.NewLogger()
+
+	if _, err := config.ParseConfig(logger, string(parent.Config)); err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+	difficulty, ok := new(big.Int).SetString(self.Difficulty, 10)
+	if !ok {
+		return errors.Errorf("invalid difficulty:%v", self.Difficulty)
+	}
+	threads := self.Threads
+	if len(threads) == 0 {
+		threads = []int{1}
+		if runtime.NumCPU() > 1 {
+			threads = append(threads, runtime.NumCPU())
+		}
+	}
+
+	ctx := context.Background()
+
+	// The expected solve time is shown only when a node is available.
+	onchainDifficulty, err := currentDifficulty(ctx, logger)
+	if err != nil {
+		level.Warn(logger).Log("msg", "skipping the expected solve time", "err", err)
+	} else {
+		level.Info(logger).Log("msg", "current on-chain difficulty", "difficulty", onchainDifficulty)
+	}
+
+	for _, hasher := range self.Hashers {
+		for _, t := range threads {
+			result, err := mining.Bench(ctx, hasher, t, difficulty, self.Duration)
+			if err != nil {
+				return errors.Wrapf(err, "benchmarking hasher:%v threads:%v", hasher, t)
+			}
+			keyvals := []interface{}{
+				"msg", "bench result",
+				"hasher", result.Hasher,
+				"threads", result.Threads,
+				"hashRate", fmt.Sprintf("%.0f", result.HashRate),
+				"hashes", result.Hashes,
+				"solutions", result.Solutions,
+				"chunkSize", result.ChunkSize,
+			}
+			if onchainDifficulty != nil && result.HashRate > 0 {
+				// Each hash is a solution with a probability of 1/difficulty.
+				seconds, _ := new(big.Float).Quo(new(big.Float).SetInt(onchainDifficulty), big.NewFloat(result.HashRate)).Float64()
+				keyvals = append(keyvals, "expectedSolveTime", time.Duration(seconds*float64(time.Second)).Round(time.Second))
+			}
+			level.Info(logger).Log(keyvals...)
+		}
+	}
+	return nil
+}
+
+// currentDifficulty returns the difficulty of the current challenge of the tellor contract.
+func currentDifficulty(ctx context.Context, logger log.Logger) (*big.Int, error) {
+	client, err := ethereum.NewClient(ctx, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating ethereum client")
+	}
+	defer client.Close()
+	contract, err := contracts.NewITellor(client)
+	if err != nil {
+		return nil, errors.Wrap(err, "create tellor contract instance")
+	}
+	vars, err := contract.GetNewCurrentVariables(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the current difficulty")
+	}
+	return vars.Difficutly, nil
+}
+
+// mine runs all the components for submitting to the oracle contracts.
+// With a remote pool the mining work of the tellor accounts is handed out to pool workers
+// instead of mining it in process.
+func mine(configPath configPath, remote bool) error {
 	logger := logging.NewLogger()
 
-	cfg, err := config.ParseConfig(logger, string(self.Config))
+	cfg, err := config.ParseConfig(logger, string(configPath))
 	if err != nil {
 		return errors.Wrap(err, "creating config")
 	}
@@ -56,7 +155,7 @@ func (self mineCmd) Run() error {
 		return errors.Wrap(err, "creating ethereum client")
 	}
 
-	accounts, err := ethereum.GetAccounts()
+	accounts, err := ethereum.GetAccounts(cfg.Accounts)
 	if err != nil {
 		return errors.Wrap(err, "getting accounts")
 	}
@@ -95,9 +194,21 @@ func (self mineCmd) Run() error {
 			level.Warn(logger).Log("msg", "FOR NEW DB INSTANCES IT IS NORMAL TO SEE SOME QUERY ERRORS AS THE DATABASE IS NOT YET POPULATED WITH VALUES")
 		}
 
+		// Manual override values.
+		manualStore, err := manual.New(logger, cfg.Manual)
+		if err != nil {
+			return errors.Wrap(err, "creating manual values store")
+		}
+
+		// Ledger of all submitter decisions.
+		ledger, err := submissions.New(logger, cfg.Submissions)
+		if err != nil {
+			return errors.Wrap(err, "creating submissions ledger")
+		}
+
 		// Web/Api server.
 		{
-			srv, err := web.New(logger, ctx, tsDB, cfg.Web)
+			srv, err := web.New(logger, ctx, tsDB, cfg.Web, manualStore, ledger)
 			if err != nil {
 				return errors.Wrap(err, "create web server")
 			}
@@ -116,6 +227,12 @@ func (self mineCmd) Run() error {
 			return errors.Wrap(err, "creating aggregator")
 		}
 
+		// Submitters in shadow mode record their decisions only when using a local DB.
+		var shadowDB storage.Appendable
+		if _tsDB, ok := tsDB.(*tsdb.DB); ok {
+			shadowDB = _tsDB
+		}
+
 		// Index tracker.
 		// Run only when not using remote DB as it needs to write to the local db.
 		if cfg.Db.RemoteHost == "" {
@@ -154,6 +271,11 @@ func (self mineCmd) Run() error {
 					return errors.Wrap(err, "create tellor contract instance")
 				}
 
+				psr, err := psrTellor.New(logger, cfg.PsrTellor, aggregator, manualStore, contractTellor)
+				if err != nil {
+					return errors.Wrap(err, "creating tellor psr")
+				}
+
 				disputeTracker, err := dispute.New(
 					logger,
 					ctx,
@@ -161,7 +283,7 @@ func (self mineCmd) Run() error {
 					_tsDB,
 					client,
 					contractTellor,
-					psrTellor.New(logger, cfg.PsrTellor, aggregator),
+					psr,
 				)
 				if err != nil {
 					return errors.Wrap(err, "creating profit tracker")
@@ -177,11 +299,24 @@ func (self mineCmd) Run() error {
 
 		}
 
-		gasPriceQuerier, err := gasStation.New(logger, cfg.GasStation, client)
+		// Journal of the sent transactions shared by all transactors.
+		var journal *transactor.Journal
+		if cfg.Transactor.JournalFile != "" {
+			journal, err = transactor.NewJournal(logger, cfg.Transactor)
+			if err != nil {
+				return errors.Wrap(err, "creating transactions journal")
+			}
+		}
+
+		gasPriceQuerier, err := oracle.New(logger, cfg.GasPrice, cfg.GasStation, client)
 		if err != nil {
 			return errors.Wrap(err, "creating gas price tracker")
 		}
 
+		if remote && !cfg.SubmitterTellor.Enabled {
+			return errors.New("the mining pool needs the tellor submitter")
+		}
+
 		if cfg.SubmitterTellor.Enabled {
 			// Profit tracker.
 			var accountAddrs []common.Address
@@ -219,16 +354,58 @@ func (self mineCmd) Run() error {
 				tasker.Stop()
 			})
 
+			// The gas used per slot is the same for all accounts so they share the samples.
+			reward, err := reward.New(logger, cfg.Reward, aggregator, contractTellor)
+			if err != nil {
+				return errors.Wrap(err, "creating reward")
+			}
+			go func() {
+				if err := reward.Bootstrap(ctx, contractTellor, client); err != nil {
+					level.Error(logger).Log("msg", "bootstrapping gas used from chain history", "err", err)
+				}
+			}()
+
+			bump, err := transactor.NewBumpStrategy(cfg.SubmitterTellor.Bump)
+			if err != nil {
+				return errors.Wrap(err, "creating tellor bump strategy")
+			}
+			if cfg.SubmitterTellor.Bump.RewardCap {
+				bump = transactor.NewRewardCapped(bump, reward, int64(cfg.SubmitterTellor.ProfitThreshold))
+			}
+
+			// The pool for the remote workers.
+			var miningPool *pool.Pool
+			if remote {
+				miningPool, err = pool.New(logger, ctx, cfg.Pool, contractTellor)
+				if err != nil {
+					return errors.Wrap(err, "creating mining pool")
+				}
+				g.Add(func() error {
+					err := miningPool.Start()
+					level.Info(logger).Log("msg", "mining pool shutdown complete")
+					return err
+				}, func(error) {
+					miningPool.Stop()
+				})
+			}
+
 			// Create a submitter for each account.
 			for _, account := range accounts {
 				loggerWithAddr := log.With(logger, "addr", account.Address.String()[:6])
 
-				transactor, err := transactor.New(loggerWithAddr, cfg.Transactor, gasPriceQuerier, client, account)
+				transactor, err := transactor.New(loggerWithAddr, cfg.Transactor, gasPriceQuerier, client, account, bump, journal, profitTracker)
 				if err != nil {
 					return errors.Wrap(err, "creating transactor")
 				}
+				// Pending transactions of a previous run are resumed before the new submits.
+				if err := transactor.Resume(ctx); err != nil {
+					return errors.Wrap(err, "resuming pending transactions")
+				}
 
-				psr := psrTellor.New(loggerWithAddr, cfg.PsrTellor, aggregator)
+				psr, err := psrTellor.New(loggerWithAddr, cfg.PsrTellor, aggregator, manualStore, contractTellor)
+				if err != nil {
+					return errors.Wrap(err, "creating tellor psr")
+				}
 
 				// Get a channel on which it listens for new data to submit.
 				submitter, submitterCh, err := tellor.New(
@@ -238,10 +415,12 @@ func (self mineCmd) Run() error {
 					client,
 					contractTellor,
 					account,
-					reward.New(loggerWithAddr, aggregator, contractTellor),
+					reward,
 					transactor,
 					gasPriceQuerier,
 					psr,
+					shadowDB,
+					ledger,
 				)
 				if err != nil {
 					return errors.Wrap(err, "creating tellor submitter")
@@ -257,6 +436,11 @@ func (self mineCmd) Run() error {
 				// Will be used to cancel pending submissions.
 				tasker.AddSubmitCanceler(submitter)
 
+				if remote {
+					miningPool.AddAccount(account.Address, taskerChs[account.Address.String()], submitterCh)
+					continue
+				}
+
 				// The Miner component.
 				miner, err := mining.NewMiningManager(loggerWithAddr, ctx, cfg.Mining, contractTellor, taskerChs[account.Address.String()], submitterCh, client)
 				if err != nil {
@@ -278,14 +462,28 @@ func (self mineCmd) Run() error {
 				return errors.Wrap(err, "create contract instance")
 			}
 
+			if cfg.SubmitterTellorMesosphere.Bump.RewardCap {
+				return errors.New("the reward cap isn't supported by the tellor mesosphere submitter")
+			}
+			bump, err := transactor.NewBumpStrategy(cfg.SubmitterTellorMesosphere.Bump)
+			if err != nil {
+				return errors.Wrap(err, "creating tellor mesosphere bump strategy")
+			}
+
 			// Create a submitter for each account.
 			for _, account := range accounts {
 				loggerWithAddr := log.With(logger, "addr", account.Address.String()[:6])
-				psr := psrTellorMesosphere.New(loggerWithAddr, cfg.PsrTellorMesosphere, aggregator)
-				transactor, err := transactor.New(loggerWithAddr, cfg.Transactor, gasPriceQuerier, client, account)
+				psr, err := psrTellorMesosphere.New(loggerWithAddr, cfg.PsrTellorMesosphere, aggregator, manualStore, contract)
+				if err != nil {
+					return errors.Wrap(err, "creating tellorMesosphere psr")
+				}
+				transactor, err := transactor.New(loggerWithAddr, cfg.Transactor, gasPriceQuerier, client, account, bump, journal, nil)
 				if err != nil {
 					return errors.Wrap(err, "creating transactor")
 				}
+				if err := transactor.Resume(ctx); err != nil {
+					return errors.Wrap(err, "resuming pending transactions")
+				}
 
 				submitter, err := tellorMesosphere.New(
 					ctx,
@@ -296,6 +494,8 @@ func (self mineCmd) Run() error {
 					account,
 					transactor,
 					psr,
+					shadowDB,
+					ledger,
 				)
 				if err != nil {
 					return errors.Wrap(err, "creating tellor mesosphere submitter")
@@ -0,0 +1,64 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"context"
+	"syscall"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/oklog/run"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/pool"
+)
+
+type poolCmd struct {
+	Config configPath `type:"existingfile" help:"path to config file"`
+}
+
+func (self poolCmd) Run() error {
+	return mine(self.Config, true)
+}
+
+type workerCmd struct {
+	Config configPath `type:"existingfile" help:"path to config file"`
+	URL    string     `optional:"" help:"URL of the pool, overrides PoolWorker.URL"`
+}
+
+func (self workerCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+	if self.URL != "" {
+		cfg.PoolWorker.URL = self.URL
+	}
+
+	worker, err := pool.NewWorker(logger, context.Background(), cfg.PoolWorker)
+	if err != nil {
+		return errors.Wrap(err, "creating pool worker")
+	}
+
+	var g run.Group
+	g.Add(run.SignalHandler(context.Background(), syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM))
+	g.Add(func() error {
+		err := worker.Start()
+		level.Info(logger).Log("msg", "pool worker shutdown complete")
+		return err
+	}, func(error) {
+		worker.Stop()
+	})
+
+	if err := g.Run(); err != nil {
+		level.Error(logger).Log("msg", "main exited with error", "err", err)
+		return err
+	}
+
+	level.Info(logger).Log("msg", "main shutdown complete")
+	return nil
+}
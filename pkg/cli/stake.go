@@ -29,7 +29,7 @@ func (self depositCmd) Run() error {
 	logger := logging.NewLogger()
 	ctx := context.Background()
 
-	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	cfg, err := config.ParseConfig(logger, string(self.Config))
 	if err != nil {
 		return errors.Wrap(err, "creating config")
 	}
@@ -38,7 +38,7 @@ func (self depositCmd) Run() error {
 		return errors.Wrap(err, "creating ethereum client")
 	}
 
-	account, err := ethereum.GetAccountByPubAddess(self.Addr)
+	account, err := ethereum.GetAccountByPubAddess(cfg.Accounts, self.Addr)
 	if err != nil {
 		return err
 	}
@@ -78,7 +78,7 @@ func (self depositCmd) Run() error {
 		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
 	}
 
-	auth, err := ethereum.PrepareEthTransaction(ctx, client, account, gasPrice)
+	auth, err := ethereum.PrepareEthTransaction(ctx, client, account, gasPrice, dynamicFees(client, cfg.Transactor))
 	if err != nil {
 		return errors.Wrap(err, "prepare ethereum transaction")
 	}
@@ -99,7 +99,7 @@ func (self withdrawCmd) Run() error {
 	logger := logging.NewLogger()
 	ctx := context.Background()
 
-	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	cfg, err := config.ParseConfig(logger, string(self.Config))
 	if err != nil {
 		return errors.Wrap(err, "creating config")
 	}
@@ -109,7 +109,7 @@ func (self withdrawCmd) Run() error {
 		return errors.Wrap(err, "creating ethereum client")
 	}
 
-	account, err := ethereum.GetAccountByPubAddess(self.Addr)
+	account, err := ethereum.GetAccountByPubAddess(cfg.Accounts, self.Addr)
 	if err != nil {
 		return err
 	}
@@ -133,7 +133,7 @@ func (self withdrawCmd) Run() error {
 		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
 	}
 
-	auth, err := ethereum.PrepareEthTransaction(ctx, client, account, gasPrice)
+	auth, err := ethereum.PrepareEthTransaction(ctx, client, account, gasPrice, dynamicFees(client, cfg.Transactor))
 	if err != nil {
 		return errors.Wrap(err, "prepare ethereum transaction")
 	}
@@ -155,7 +155,7 @@ func (self requestCmd) Run() error {
 	logger := logging.NewLogger()
 	ctx := context.Background()
 
-	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	cfg, err := config.ParseConfig(logger, string(self.Config))
 	if err != nil {
 		return errors.Wrap(err, "creating config")
 	}
@@ -164,7 +164,7 @@ func (self requestCmd) Run() error {
 	if err != nil {
 		return errors.Wrap(err, "creating ethereum client")
 	}
-	account, err := ethereum.GetAccountByPubAddess(self.Addr)
+	account, err := ethereum.GetAccountByPubAddess(cfg.Accounts, self.Addr)
 	if err != nil {
 		return err
 	}
@@ -187,7 +187,7 @@ func (self requestCmd) Run() error {
 		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
 	}
 
-	auth, err := ethereum.PrepareEthTransaction(ctx, client, account, gasPrice)
+	auth, err := ethereum.PrepareEthTransaction(ctx, client, account, gasPrice, dynamicFees(client, cfg.Transactor))
 	if err != nil {
 		return errors.Wrap(err, "prepare ethereum transaction")
 	}
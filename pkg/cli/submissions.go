@@ -0,0 +1,86 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/submissions"
+)
+
+type submissionsListCmd struct {
+	cfg
+	From     time.Time `optional:"" help:"show only records after this time in RFC3339 format"`
+	To       time.Time `optional:"" help:"show only records before this time in RFC3339 format"`
+	Contract string    `optional:"" enum:",tellor,tellorMesosphere" default:"" help:"show only records for this oracle contract (tellor or tellorMesosphere)"`
+	Account  string    `optional:"" help:"show only records for this account address"`
+	Decision string    `optional:"" enum:",received,skipped,reverted,failed,submitted" default:"" help:"show only records with this decision (received, skipped, reverted, failed or submitted)"`
+	Limit    int       `default:"100" help:"show only the most recent records, 0 shows all"`
+}
+
+func (self *submissionsListCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	ledger, err := submissions.New(logger, cfg.Submissions)
+	if err != nil {
+		return errors.Wrap(err, "creating submissions ledger")
+	}
+
+	records, err := ledger.List(submissions.Filter{
+		From:     self.From,
+		To:       self.To,
+		Contract: self.Contract,
+		Account:  self.Account,
+		Decision: self.Decision,
+		Limit:    self.Limit,
+	})
+	if err != nil {
+		return errors.Wrap(err, "list submissions")
+	}
+	for _, r := range records {
+		keyvals := []interface{}{
+			"msg", "submission",
+			"time", r.Time.Format(time.RFC3339),
+			"contract", r.Contract,
+			"account", r.Account,
+			"decision", r.Decision,
+			"shadow", r.Shadow,
+			"received", r.Received.Format(time.RFC3339),
+			"elapsed", r.Time.Sub(r.Received),
+		}
+		if r.Reason != "" {
+			keyvals = append(keyvals, "reason", r.Reason)
+		}
+		if r.Challenge != "" {
+			keyvals = append(keyvals, "challenge", r.Challenge, "solution", r.Solution)
+		}
+		if len(r.Values) > 0 {
+			keyvals = append(keyvals, "values", fmt.Sprintf("%+v", r.Values))
+		}
+		if r.TxHash != "" {
+			keyvals = append(keyvals, "txHash", r.TxHash, "gasPrice", r.GasPrice)
+			if r.MaxFee != "" {
+				keyvals = append(keyvals, "maxFee", r.MaxFee, "priorityFee", r.PriorityFee)
+			}
+		}
+		if r.ReceiptStatus != nil {
+			keyvals = append(keyvals, "receiptStatus", *r.ReceiptStatus, "gasUsed", r.GasUsed)
+		}
+		if r.Slot != nil {
+			keyvals = append(keyvals, "slot", *r.Slot)
+		}
+		level.Info(logger).Log(keyvals...)
+	}
+	return nil
+}
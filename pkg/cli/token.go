@@ -32,7 +32,7 @@ func (self *transferCmd) Run() error {
 	logger := logging.NewLogger()
 	ctx := context.Background()
 
-	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	cfg, err := config.ParseConfig(logger, string(self.Config))
 	if err != nil {
 		return errors.Wrap(err, "creating config")
 	}
@@ -74,11 +74,11 @@ func (self *transferCmd) Run() error {
 		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
 	}
 
-	acc, err := ethereum.GetAccountByPubAddess(self.From)
+	acc, err := ethereum.GetAccountByPubAddess(cfg.Accounts, self.From)
 	if err != nil {
 		return errors.Wrap(err, "getting auth account")
 	}
-	fromAuth, err := ethereum.PrepareEthTransaction(ctx, client, acc, gasPrice)
+	fromAuth, err := ethereum.PrepareEthTransaction(ctx, client, acc, gasPrice, dynamicFees(client, cfg.Transactor))
 	if err != nil {
 		return errors.Wrap(err, "preparing ethereum transaction")
 	}
@@ -108,7 +108,7 @@ func (self *approveCmd) Run() error {
 	logger := logging.NewLogger()
 	ctx := context.Background()
 
-	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	cfg, err := config.ParseConfig(logger, string(self.Config))
 	if err != nil {
 		return errors.Wrap(err, "creating config")
 	}
@@ -149,12 +149,12 @@ func (self *approveCmd) Run() error {
 		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
 	}
 
-	acc, err := ethereum.GetAccountByPubAddess(self.From)
+	acc, err := ethereum.GetAccountByPubAddess(cfg.Accounts, self.From)
 	if err != nil {
 		return errors.Wrap(err, "getting auth account")
 	}
 
-	fromAuth, err := ethereum.PrepareEthTransaction(ctx, client, acc, gasPrice)
+	fromAuth, err := ethereum.PrepareEthTransaction(ctx, client, acc, gasPrice, dynamicFees(client, cfg.Transactor))
 	if err != nil {
 		return errors.Wrap(err, "preparing ethereum transaction")
 	}
@@ -0,0 +1,185 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/transactor"
+)
+
+type txAddr struct {
+	cfg
+	Addr string `required:"" help:"the account address"`
+}
+
+type txHash struct {
+	txAddr
+	Hash string `optional:"" help:"hash of the pending transaction, the transaction with the lowest pending nonce is used when not set"`
+}
+
+type txPendingCmd struct {
+	txAddr
+}
+
+func (self *txPendingCmd) Run() error {
+	logger := logging.NewLogger()
+	ctx := context.Background()
+
+	if _, err := config.ParseConfig(logger, string(self.Config)); err != nil { // Load the env file.
+		return errors.Wrap(err, "creating config")
+	}
+	client, err := ethereum.NewClient(ctx, logger)
+	if err != nil {
+		return errors.Wrap(err, "creating ethereum client")
+	}
+	addr := common.HexToAddress(self.Addr)
+
+	mined, err := client.NonceAt(ctx, addr, nil)
+	if err != nil {
+		return errors.Wrap(err, "getting nonce")
+	}
+	pending, err := client.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return errors.Wrap(err, "getting pending nonce")
+	}
+	level.Info(logger).Log("msg", "nonces", "address", addr.Hex(), "mined", mined, "pending", pending)
+
+	txs, err := txpoolPending(ctx, client, addr)
+	if err != nil {
+		level.Warn(logger).Log("msg", "the node doesn't support listing the pending transactions", "err", err)
+		return nil
+	}
+	if len(txs) == 0 {
+		level.Info(logger).Log("msg", "no pending transactions")
+	}
+	for _, tx := range txs {
+		logTx(logger, "pending transaction", tx)
+	}
+	return nil
+}
+
+type txSpeedupCmd struct {
+	txHash
+}
+
+func (self *txSpeedupCmd) Run() error {
+	return replaceTx(self.txHash, func(tx *types.Transaction, _ common.Address) *types.Transaction {
+		return transactor.SpeedUp(tx, transactor.MinReplacement(tx, nil))
+	})
+}
+
+type txCancelCmd struct {
+	txHash
+}
+
+func (self *txCancelCmd) Run() error {
+	return replaceTx(self.txHash, func(tx *types.Transaction, from common.Address) *types.Transaction {
+		return transactor.Cancel(tx, from, transactor.MinReplacement(tx, nil))
+	})
+}
+
+// replaceTx sends the replacement of a pending transaction of the account.
+func replaceTx(args txHash, replacement func(*types.Transaction, common.Address) *types.Transaction) error {
+	logger := logging.NewLogger()
+	ctx := context.Background()
+
+	cfg, err := config.ParseConfig(logger, string(args.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+	client, err := ethereum.NewClient(ctx, logger)
+	if err != nil {
+		return errors.Wrap(err, "creating ethereum client")
+	}
+	account, err := ethereum.GetAccountByPubAddess(cfg.Accounts, common.HexToAddress(args.Addr).Hex())
+	if err != nil {
+		return err
+	}
+
+	tx, err := pendingTx(ctx, client, account.Address, args.Hash)
+	if err != nil {
+		return err
+	}
+	logTx(logger, "replacing transaction", tx)
+
+	netID, err := client.NetworkID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "getting network id")
+	}
+	signed, err := account.Signer.SignTx(ctx, replacement(tx, account.Address), netID)
+	if err != nil {
+		return err
+	}
+	if err := client.SendTransaction(ctx, signed); err != nil {
+		return errors.Wrap(err, "send transaction")
+	}
+	logTx(logger, "replacement sent", signed)
+	return nil
+}
+
+// pendingTx returns the pending transaction with the hash or
+// the one with the lowest nonce when the hash is not set.
+func pendingTx(ctx context.Context, client *ethereum.Client, addr common.Address, hash string) (*types.Transaction, error) {
+	if hash != "" {
+		tx, isPending, err := client.TransactionByHash(ctx, common.HexToHash(hash))
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting transaction:%v", hash)
+		}
+		if !isPending {
+			return nil, errors.Errorf("transaction is already mined:%v", hash)
+		}
+		return tx, nil
+	}
+	txs, err := txpoolPending(ctx, client, addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing pending transactions, use --hash for nodes that don't support it")
+	}
+	if len(txs) == 0 {
+		return nil, errors.Errorf("no pending transactions for:%v", addr.Hex())
+	}
+	return txs[0], nil
+}
+
+// txpoolPending returns the pending transactions of the account sorted by nonce.
+// It uses txpool_contentFrom which is supported by geth and some other nodes.
+func txpoolPending(ctx context.Context, client *ethereum.Client, addr common.Address) ([]*types.Transaction, error) {
+	var content struct {
+		Pending map[string]*types.Transaction `json:"pending"`
+		Queued  map[string]*types.Transaction `json:"queued"`
+	}
+	if err := client.CallContext(ctx, &content, "txpool_contentFrom", addr); err != nil {
+		return nil, errors.Wrap(err, "txpool_contentFrom")
+	}
+
+	var txs []*types.Transaction
+	for _, set := range []map[string]*types.Transaction{content.Pending, content.Queued} {
+		for _, tx := range set {
+			txs = append(txs, tx)
+		}
+	}
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce() < txs[j].Nonce() })
+	return txs, nil
+}
+
+func logTx(logger log.Logger, msg string, tx *types.Transaction) {
+	level.Info(logger).Log(
+		"msg", msg,
+		"tx", tx.Hash(),
+		"nonce", tx.Nonce(),
+		"gasPrice", tx.GasPrice(),
+		"maxFee", tx.GasFeeCap(),
+		"priorityFee", tx.GasTipCap(),
+		"gas", tx.Gas(),
+	)
+}
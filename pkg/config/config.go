@@ -16,11 +16,19 @@ import (
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/aggregator"
 	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/gasPrice/feeHistory"
 	"github.com/tellor-io/telliot/pkg/gasPrice/gasStation"
+	"github.com/tellor-io/telliot/pkg/gasPrice/oracle"
+	"github.com/tellor-io/telliot/pkg/manual"
 	"github.com/tellor-io/telliot/pkg/mining"
+	"github.com/tellor-io/telliot/pkg/pool"
 	psrTellor "github.com/tellor-io/telliot/pkg/psr/tellor"
 	psrTellorMesosphere "github.com/tellor-io/telliot/pkg/psr/tellorMesosphere"
+	"github.com/tellor-io/telliot/pkg/reward"
+	"github.com/tellor-io/telliot/pkg/submissions"
+	"github.com/tellor-io/telliot/pkg/submitter/guard"
 	"github.com/tellor-io/telliot/pkg/submitter/tellor"
 	"github.com/tellor-io/telliot/pkg/submitter/tellorMesosphere"
 	"github.com/tellor-io/telliot/pkg/tasker"
@@ -31,10 +39,21 @@ import (
 	"github.com/tellor-io/telliot/pkg/web"
 )
 
+var defaultBump = transactor.BumpConfig{
+	Strategy:      transactor.BumpLinear,
+	Step:          11,
+	Delay:         format.Duration{Duration: 15 * time.Second},
+	Window:        format.Duration{Duration: 15 * time.Minute},
+	MaxMultiplier: 2,
+}
+
 // Config is the top-level configuration that holds configs for all components.
 type Config struct {
 	Web                       web.Config
+	Accounts                  ethereum.AccountsConfig
 	Mining                    mining.Config
+	Pool                      pool.Config
+	PoolWorker                pool.WorkerConfig
 	SubmitterTellor           tellor.Config
 	SubmitterTellorMesosphere tellorMesosphere.Config
 	ProfitTracker             profit.Config
@@ -43,10 +62,14 @@ type Config struct {
 	IndexTracker              index.Config
 	DisputeTracker            dispute.Config
 	Aggregator                aggregator.Config
+	Manual                    manual.Config
+	Submissions               submissions.Config
+	Reward                    reward.Config
 	PsrTellor                 psrTellor.Config
 	PsrTellorMesosphere       psrTellorMesosphere.Config
 	Db                        db.Config
 	GasStation                gasStation.Config
+	GasPrice                  oracle.Config
 	// EnvFile location that include all private details like private key etc.
 	EnvFile string `json:"envFile"`
 }
@@ -55,6 +78,19 @@ var DefaultConfig = Config{
 	Mining: mining.Config{
 		LogLevel:  "info",
 		Heartbeat: time.Minute,
+		Threads:   1,
+	},
+	Pool: pool.Config{
+		LogLevel:      "info",
+		ListenHost:    "", // Listen on all addresses.
+		ListenPort:    9091,
+		JobDuration:   format.Duration{Duration: 5 * time.Second},
+		WorkerTimeout: format.Duration{Duration: time.Minute},
+	},
+	PoolWorker: pool.WorkerConfig{
+		LogLevel:     "info",
+		URL:          "http://localhost:9091",
+		PollInterval: format.Duration{Duration: time.Second},
 	},
 	Web: web.Config{
 		LogLevel:   "info",
@@ -76,30 +112,89 @@ var DefaultConfig = Config{
 		LogLevel: "info",
 	},
 	Transactor: transactor.Config{
-		LogLevel:      "info",
-		GasMax:        10,
-		GasMultiplier: 1,
+		LogLevel:              "info",
+		GasMax:                10,
+		GasMultiplier:         1,
+		TxType:                transactor.TxTypeAuto,
+		FeeHistoryBlocks:      10,
+		PriorityFeePercentile: 50,
+		BaseFeeMultiplier:     2,
+		GasLimitMargin:        20,
+		GasLimitMax:           3000000,
+		GasLimitCaps: map[string]uint64{
+			"submitMiningSolution": 3000000,
+			"submitValue":          500000,
+		},
+		StuckTimeout:  format.Duration{Duration: 5 * time.Minute},
+		StuckAction:   transactor.StuckActionBump,
+		StuckMaxBumps: 3,
+		JournalFile:   "configs/txJournal.log",
+		Relay: transactor.RelayConfig{
+			Mode:           transactor.RelayModeBundle,
+			FallbackBlocks: 3,
+		},
 	},
 	SubmitterTellor: tellor.Config{
 		Enabled:  true,
 		LogLevel: "info",
 		// With a 1 second delay here as a workaround to prevent a race condition in the oracle contract check.
 		MinSubmitPeriod: format.Duration{Duration: 15*time.Minute + 1*time.Second},
+		Guard: guard.Config{
+			MaxDeviation:   10,
+			LookBackBlocks: 100,
+		},
+		Bump: defaultBump,
 	},
 	SubmitterTellorMesosphere: tellorMesosphere.Config{
 		LogLevel:             "info",
 		MinSubmitPeriod:      format.Duration{Duration: 15 * time.Second},
 		MinSubmitPriceChange: 0.05,
+		Bump:                 defaultBump,
 	},
 	PsrTellor: psrTellor.Config{
-		MinConfidence: 70,
+		MinConfidence:         70,
+		AbsoluteMinConfidence: 50,
+	},
+	PsrTellorMesosphere: psrTellorMesosphere.Config{
+		AbsoluteMinConfidence: 50,
 	},
 	Aggregator: aggregator.Config{
-		LogLevel:       "info",
-		ManualDataFile: "configs/manualData.json",
+		LogLevel:    "info",
+		CacheBucket: format.Duration{Duration: 10 * time.Second},
+	},
+	Manual: manual.Config{
+		LogLevel:  "info",
+		File:      "configs/manualData.json",
+		AuditFile: "configs/manualDataAudit.log",
+	},
+	Reward: reward.Config{
+		LogLevel:        "info",
+		File:            "configs/gasUsed.json",
+		Window:          20,
+		Percentile:      75,
+		BootstrapBlocks: 1000,
+	},
+	Accounts: ethereum.AccountsConfig{
+		Source:          ethereum.AccountsSourceEnv,
+		KeystoreDir:     "configs/keystore",
+		DerivationPaths: []string{"m/44'/60'/0'/0/0"},
+	},
+	Submissions: submissions.Config{
+		LogLevel: "info",
+		File:     "configs/submissions.log",
 	},
 	GasStation: gasStation.Config{
-		TimeWait: format.Duration{Duration: time.Minute},
+		TimeWait: format.Duration{Duration: 5 * time.Minute},
+	},
+	GasPrice: oracle.Config{
+		LogLevel: "info",
+		Oracle:   oracle.OracleGasStation,
+		CacheTTL: format.Duration{Duration: 15 * time.Second},
+		FeeHistory: feeHistory.Config{
+			LogLevel:   "info",
+			Blocks:     10,
+			Percentile: 50,
+		},
 	},
 	IndexTracker: index.Config{
 		LogLevel:  "info",
@@ -37,5 +37,7 @@ func TestConfig(t *testing.T) {
 	//Asserting Default Values
 	testutil.Assert(t, cfg.Transactor.GasMax > 0, "GasMax should have value")
 	testutil.Assert(t, cfg.Transactor.GasMultiplier > 0, "GasMultiplier should have value")
+	testutil.Assert(t, cfg.PsrTellor.AbsoluteMinConfidence > 0, "fallbacks require an absolute min confidence")
+	testutil.Assert(t, cfg.PsrTellorMesosphere.AbsoluteMinConfidence > 0, "fallbacks require an absolute min confidence")
 
 }
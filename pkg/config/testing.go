@@ -25,7 +25,8 @@ func OpenTestConfig(dir string) (*Config, error) {
 
 	cfg.IndexTracker.IndexFile = filepath.Join(rootDir, cfg.IndexTracker.IndexFile)
 	cfg.EnvFile = filepath.Join(rootDir, cfg.EnvFile+".example")
-	cfg.Aggregator.ManualDataFile = filepath.Join(rootDir, cfg.Aggregator.ManualDataFile)
+	cfg.Manual.File = filepath.Join(rootDir, cfg.Manual.File)
+	cfg.Manual.AuditFile = filepath.Join(rootDir, cfg.Manual.AuditFile)
 
 	return &cfg, nil
 
@@ -2,9 +2,10 @@ package contracts
 
 import (
 	"context"
+	"math/big"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/contracts/balancer"
 	"github.com/tellor-io/telliot/pkg/contracts/lens"
@@ -40,6 +41,12 @@ const (
 	ITellorABI        = tellor.ITellorABI
 )
 
+// Backend is the node client used by the contract instances.
+type Backend interface {
+	bind.ContractBackend
+	NetworkID(ctx context.Context) (*big.Int, error)
+}
+
 type ITellorMesosphere struct {
 	Address common.Address
 	*tellorMesosphere.TellorMesosphere
@@ -52,7 +59,7 @@ type ITellor struct {
 	Address common.Address
 }
 
-func NewITellor(client *ethclient.Client) (*ITellor, error) {
+func NewITellor(client Backend) (*ITellor, error) {
 	conractAddr, err := GetTellorAddress(client)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting contract address")
@@ -74,7 +81,7 @@ func NewITellor(client *ethclient.Client) (*ITellor, error) {
 	return &ITellor{Address: common.HexToAddress(TellorAddress), ITellor: tellorInstance, Main: lensInstance}, nil
 }
 
-func NewITellorMesosphere(client *ethclient.Client) (*ITellorMesosphere, error) {
+func NewITellorMesosphere(client Backend) (*ITellorMesosphere, error) {
 	conractAddr, err := GetTellorMesosphereAddress(client)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting contract address")
@@ -87,7 +94,7 @@ func NewITellorMesosphere(client *ethclient.Client) (*ITellorMesosphere, error)
 	return &ITellorMesosphere{Address: common.HexToAddress(TellorMesosphereAddress), TellorMesosphere: tellorInstance}, nil
 }
 
-func GetTellorMesosphereAddress(client *ethclient.Client) (common.Address, error) {
+func GetTellorMesosphereAddress(client Backend) (common.Address, error) {
 	networkID, err := client.NetworkID(context.Background())
 	if err != nil {
 		return common.Address{}, err
@@ -106,7 +113,7 @@ func GetTellorMesosphereAddress(client *ethclient.Client) (common.Address, error
 	}
 }
 
-func GetTellorAddress(client *ethclient.Client) (common.Address, error) {
+func GetTellorAddress(client Backend) (common.Address, error) {
 	networkID, err := client.NetworkID(context.Background())
 	if err != nil {
 		return common.Address{}, err
@@ -126,7 +133,7 @@ func GetTellorAddress(client *ethclient.Client) (common.Address, error) {
 	}
 }
 
-func GetLensAddress(client *ethclient.Client) (common.Address, error) {
+func GetLensAddress(client Backend) (common.Address, error) {
 	networkID, err := client.NetworkID(context.Background())
 	if err != nil {
 		return common.Address{}, err
@@ -0,0 +1,185 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const MnemonicEnvName = "ETH_MNEMONIC"
+
+// hardenedKeyStart is the first hardened child index in a BIP-32 derivation path.
+const hardenedKeyStart = 0x80000000
+
+// Account sources.
+const (
+	AccountsSourceEnv      = "env"
+	AccountsSourceKeystore = "keystore"
+	AccountsSourceMnemonic = "mnemonic"
+)
+
+type AccountsConfig struct {
+	Source          string   `help:"Where to load the accounts from - env(the ETH_PRIVATE_KEYS env variable), keystore(encrypted keystore files in KeystoreDir) or mnemonic(the ETH_MNEMONIC env variable). Remote signer accounts are added for all sources when REMOTE_SIGNER_URL is set."`
+	KeystoreDir     string   `help:"Directory with geth style encrypted keystore files."`
+	PassphraseFile  string   `help:"File with the passphrase for the keystore files. The passphrase is prompted for when not set."`
+	DerivationPaths []string `help:"BIP-32 derivation paths of the mnemonic accounts."`
+}
+
+// ReadPassphrase returns the passphrase from the file or
+// prompts for it when the file is not set.
+func ReadPassphrase(file string, confirm bool) (string, error) {
+	if file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", errors.Wrap(err, "read passphrase file")
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	passphrase, err := prompt.Stdin.PromptPassword("Passphrase: ")
+	if err != nil {
+		return "", errors.Wrap(err, "read passphrase")
+	}
+	if confirm {
+		again, err := prompt.Stdin.PromptPassword("Repeat passphrase: ")
+		if err != nil {
+			return "", errors.Wrap(err, "read passphrase")
+		}
+		if again != passphrase {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+// NewKeystore returns the keystore for the configured directory.
+func NewKeystore(cfg AccountsConfig) (*keystore.KeyStore, error) {
+	if cfg.KeystoreDir == "" {
+		return nil, errors.New("missing keystore dir")
+	}
+	return keystore.NewKeyStore(cfg.KeystoreDir, keystore.StandardScryptN, keystore.StandardScryptP), nil
+}
+
+// getKeystoreAccounts decrypts all keys in the keystore dir with the same passphrase.
+func getKeystoreAccounts(cfg AccountsConfig) ([]*Account, error) {
+	ks, err := NewKeystore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(ks.Accounts()) == 0 {
+		return nil, errors.Errorf("no keys in the keystore dir:%v", cfg.KeystoreDir)
+	}
+	passphrase, err := ReadPassphrase(cfg.PassphraseFile, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []*Account
+	for _, acc := range ks.Accounts() {
+		key, err := DecryptKeystoreFile(acc.URL.Path, passphrase)
+		if err != nil {
+			return nil, errors.Wrapf(err, "account:%v", acc.Address.Hex())
+		}
+		accounts = append(accounts, &Account{Address: acc.Address, PrivateKey: key, Signer: NewKeySigner(key)})
+	}
+	return accounts, nil
+}
+
+// DecryptKeystoreFile returns the private key from an encrypted keystore file.
+func DecryptKeystoreFile(path, passphrase string) (*ecdsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read keystore file")
+	}
+	key, err := keystore.DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypt keystore file")
+	}
+	return key.PrivateKey, nil
+}
+
+// getMnemonicAccounts derives an account for every configured derivation path.
+func getMnemonicAccounts(cfg AccountsConfig) ([]*Account, error) {
+	mnemonic := strings.TrimSpace(os.Getenv(MnemonicEnvName))
+	if mnemonic == "" {
+		return nil, errors.Errorf("missing %v env variable", MnemonicEnvName)
+	}
+	paths := cfg.DerivationPaths
+	if len(paths) == 0 {
+		paths = []string{accounts.DefaultBaseDerivationPath.String()}
+	}
+
+	var accs []*Account
+	for _, p := range paths {
+		key, err := DeriveKey(mnemonic, p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "derivation path:%v", p)
+		}
+		accs = append(accs, &Account{Address: crypto.PubkeyToAddress(key.PublicKey), PrivateKey: key, Signer: NewKeySigner(key)})
+	}
+	return accs, nil
+}
+
+// DeriveKey returns the private key for a BIP-39 mnemonic and a BIP-32 derivation path.
+func DeriveKey(mnemonic string, path string) (*ecdsa.PrivateKey, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, errors.New("invalid mnemonic")
+	}
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse derivation path")
+	}
+
+	seed := bip39.NewSeed(mnemonic, "")
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+
+	n := crypto.S256().Params().N
+	for _, index := range derivationPath {
+		data := make([]byte, 0, 37)
+		if index >= hardenedKeyStart {
+			data = append(data, 0)
+			data = append(data, math.PaddedBigBytes(key, 32)...)
+		} else {
+			priv, err := crypto.ToECDSA(math.PaddedBigBytes(key, 32))
+			if err != nil {
+				return nil, errors.Wrap(err, "convert key")
+			}
+			data = append(data, crypto.CompressPubkey(&priv.PublicKey)...)
+		}
+		var i [4]byte
+		binary.BigEndian.PutUint32(i[:], index)
+		data = append(data, i[:]...)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+		il := new(big.Int).SetBytes(sum[:32])
+		if il.Cmp(n) >= 0 {
+			return nil, errors.Errorf("invalid child key at index:%v", index)
+		}
+		key = il.Add(il, key).Mod(il, n)
+		if key.Sign() == 0 {
+			return nil, errors.Errorf("invalid child key at index:%v", index)
+		}
+		chainCode = sum[32:]
+	}
+	return crypto.ToECDSA(math.PaddedBigBytes(key, 32))
+}
@@ -0,0 +1,56 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestDeriveKey(t *testing.T) {
+	mnemonic := "test test test test test test test test test test test junk"
+
+	key, err := DeriveKey(mnemonic, "m/44'/60'/0'/0/0")
+	testutil.Ok(t, err)
+	testutil.Equals(t, common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"), crypto.PubkeyToAddress(key.PublicKey))
+
+	key, err = DeriveKey(mnemonic, "m/44'/60'/0'/0/1")
+	testutil.Ok(t, err)
+	testutil.Equals(t, common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"), crypto.PubkeyToAddress(key.PublicKey))
+
+	_, err = DeriveKey("test test junk", "m/44'/60'/0'/0/0")
+	testutil.NotOk(t, err)
+}
+
+func TestKeystoreAccounts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	testutil.Ok(t, err)
+	defer os.RemoveAll(dir)
+
+	passFile := filepath.Join(dir, "passphrase")
+	testutil.Ok(t, ioutil.WriteFile(passFile, []byte("secret\n"), 0600))
+	cfg := AccountsConfig{Source: AccountsSourceKeystore, KeystoreDir: filepath.Join(dir, "keys"), PassphraseFile: passFile}
+
+	key, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	ks, err := NewKeystore(cfg)
+	testutil.Ok(t, err)
+	_, err = ks.ImportECDSA(key, "secret")
+	testutil.Ok(t, err)
+
+	defer os.Setenv(RemoteSignerURLEnvName, os.Getenv(RemoteSignerURLEnvName))
+	testutil.Ok(t, os.Unsetenv(RemoteSignerURLEnvName))
+
+	accounts, err := GetAccounts(cfg)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(accounts))
+	testutil.Equals(t, crypto.PubkeyToAddress(key.PublicKey), accounts[0].Address)
+	testutil.Equals(t, key.D, accounts[0].PrivateKey.D)
+}
@@ -0,0 +1,578 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// healthCheckInterval is how often all endpoints are checked.
+	healthCheckInterval = 15 * time.Second
+	// maxHeadLag is how many blocks an endpoint can be behind the highest head of all endpoints
+	// before it is considered unhealthy.
+	maxHeadLag = 3
+	// resubscribeDelay is the delay between attempts to re-create a subscription.
+	resubscribeDelay = time.Second
+)
+
+var (
+	endpointActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "endpoint_active",
+		Help:      "1 for the node endpoint used for all requests and 0 for the others",
+	},
+		[]string{"endpoint"},
+	)
+	endpointHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "endpoint_healthy",
+		Help:      "1 when the node endpoint is reachable, synced and not lagging behind",
+	},
+		[]string{"endpoint"},
+	)
+	endpointHead = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "endpoint_head",
+		Help:      "The last head block number of the node endpoint",
+	},
+		[]string{"endpoint"},
+	)
+	failovers = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "failovers_total",
+		Help:      "The total number of switches of the active node endpoint",
+	})
+)
+
+var errSyncing = errors.New("the node is still syncing with the network")
+
+type endpoint struct {
+	url string
+	// name is used in logs and metrics as the url can contain an api key.
+	name   string
+	client *ethclient.Client
+	rpc    *rpc.Client
+	// skipSync is for nodes that don't support the sync checking like Arbitrum.
+	skipSync bool
+	healthy  bool
+	head     uint64
+}
+
+// Client is an ethereum client that fails over between multiple node endpoints.
+// The endpoints are used in the order of priority and the first healthy one is the active one.
+// Requests that fail because of the connection to the node are retried with the next healthy endpoint
+// and subscriptions are re-created on the active endpoint without returning an error.
+type Client struct {
+	logger    log.Logger
+	endpoints []*endpoint
+
+	mtx    sync.RWMutex
+	active *endpoint
+	// switched is closed and replaced every time the active endpoint changes.
+	switched chan struct{}
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewClient connects to the comma separated list of node URLs in the NODE_URL env variable.
+// At least one of them must be synced with the network.
+func NewClient(ctx context.Context, logger log.Logger) (*Client, error) {
+	var urls []string
+	for _, u := range strings.Split(os.Getenv(NodeURLEnvName), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		return nil, errors.Errorf("missing node url in the %v env variable", NodeURLEnvName)
+	}
+
+	self := &Client{
+		logger:   log.With(logger, "component", ComponentName),
+		switched: make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+	names := make(map[string]bool)
+	for i, u := range urls {
+		name := u
+		if parsed, err := url.Parse(u); err == nil && parsed.Host != "" {
+			name = parsed.Host
+		}
+		if names[name] {
+			name = fmt.Sprintf("%v#%d", name, i)
+		}
+		names[name] = true
+		self.endpoints = append(self.endpoints, &endpoint{
+			url:      u,
+			name:     name,
+			skipSync: strings.Contains(strings.ToLower(u), "arbitrum"),
+		})
+	}
+
+	self.check(ctx)
+	if self.active == nil {
+		for _, e := range self.endpoints {
+			e.close()
+		}
+		return nil, errors.New("no node endpoint is reachable and synced with the network")
+	}
+
+	id, err := self.NetworkID(ctx)
+	if err != nil {
+		self.Close()
+		return nil, errors.Wrap(err, "get network ID")
+	}
+	level.Info(self.logger).Log("msg", "client created", "netID", id.String(), "endpoints", len(self.endpoints), "active", self.active.name)
+
+	go self.healthCheck()
+	return self, nil
+}
+
+// Close stops the health check and closes the connections to all endpoints.
+func (self *Client) Close() {
+	self.once.Do(func() {
+		close(self.stop)
+		self.mtx.Lock()
+		defer self.mtx.Unlock()
+		for _, e := range self.endpoints {
+			e.close()
+		}
+	})
+}
+
+func (self *endpoint) close() {
+	if self.client != nil {
+		self.client.Close()
+	}
+	self.client, self.rpc = nil, nil
+}
+
+func (self *Client) healthCheck() {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-self.stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cncl := context.WithTimeout(context.Background(), healthCheckInterval)
+		self.check(ctx)
+		cncl()
+	}
+}
+
+// check redials the endpoints that are down, updates the health of all endpoints
+// and selects the active one.
+func (self *Client) check(ctx context.Context) {
+	type status struct {
+		client *ethclient.Client
+		rpc    *rpc.Client
+		head   uint64
+		err    error
+	}
+	self.mtx.RLock()
+	statuses := make([]status, len(self.endpoints))
+	for i, e := range self.endpoints {
+		statuses[i] = status{client: e.client, rpc: e.rpc}
+	}
+	self.mtx.RUnlock()
+
+	// The requests are done without the lock as they can be slow.
+	var wg sync.WaitGroup
+	for i, e := range self.endpoints {
+		wg.Add(1)
+		go func(s *status, e *endpoint) {
+			defer wg.Done()
+			if s.client == nil {
+				s.rpc, s.err = rpc.DialContext(ctx, e.url)
+				if s.err != nil {
+					return
+				}
+				s.client = ethclient.NewClient(s.rpc)
+			}
+			if !e.skipSync {
+				progress, err := s.client.SyncProgress(ctx)
+				if err != nil {
+					s.err = errors.Wrap(err, "determining if the node is syncing")
+					return
+				}
+				if progress != nil {
+					s.err = errSyncing
+					return
+				}
+			}
+			s.head, s.err = s.client.BlockNumber(ctx)
+		}(&statuses[i], e)
+	}
+	wg.Wait()
+
+	var maxHead uint64
+	for _, s := range statuses {
+		if s.err == nil && s.head > maxHead {
+			maxHead = s.head
+		}
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	select {
+	case <-self.stop:
+		for _, s := range statuses {
+			if s.client != nil {
+				s.client.Close()
+			}
+		}
+		return
+	default:
+	}
+	for i, e := range self.endpoints {
+		s := statuses[i]
+		if e.client != nil && e.client != s.client {
+			e.client.Close()
+		}
+		e.client, e.rpc, e.head = s.client, s.rpc, s.head
+		healthy := s.err == nil && s.head+maxHeadLag >= maxHead
+		if !healthy && e.healthy {
+			level.Warn(self.logger).Log("msg", "node endpoint is unhealthy", "endpoint", e.name, "head", s.head, "maxHead", maxHead, "err", s.err)
+		}
+		if s.err != nil && s.err != errSyncing && isNodeError(s.err) && e.client != nil {
+			// Redial on the next check.
+			e.close()
+		}
+		e.healthy = healthy
+		endpointHead.With(prometheus.Labels{"endpoint": e.name}).Set(float64(s.head))
+		endpointHealthy.With(prometheus.Labels{"endpoint": e.name}).Set(boolToFloat(healthy))
+	}
+	self.selectActive()
+}
+
+// selectActive makes the first healthy endpoint the active one.
+// The caller must hold the lock.
+func (self *Client) selectActive() {
+	var active *endpoint
+	for _, e := range self.endpoints {
+		if e.healthy && e.client != nil {
+			active = e
+			break
+		}
+	}
+	if active == nil || active == self.active {
+		return
+	}
+	if self.active != nil {
+		failovers.Inc()
+		level.Warn(self.logger).Log("msg", "switched node endpoint", "from", self.active.name, "to", active.name)
+	}
+	self.active = active
+	for _, e := range self.endpoints {
+		endpointActive.With(prometheus.Labels{"endpoint": e.name}).Set(boolToFloat(e == active))
+	}
+	close(self.switched)
+	self.switched = make(chan struct{})
+}
+
+// markDown marks an endpoint unhealthy after a failed request and switches to the next one.
+func (self *Client) markDown(e *endpoint, err error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if !e.healthy {
+		return
+	}
+	level.Warn(self.logger).Log("msg", "node request failed", "endpoint", e.name, "err", err)
+	e.healthy = false
+	endpointHealthy.With(prometheus.Labels{"endpoint": e.name}).Set(0)
+	self.selectActive()
+}
+
+// conn is the connection of an endpoint at the time of a request
+// as the health check can replace it during the request.
+type conn struct {
+	endpoint *endpoint
+	client   *ethclient.Client
+	rpc      *rpc.Client
+}
+
+// candidates returns the active endpoint followed by the other healthy ones in the order of priority.
+// When none of them is healthy it returns all connected endpoints.
+func (self *Client) candidates() []conn {
+	self.mtx.RLock()
+	defer self.mtx.RUnlock()
+	var healthy, connected []conn
+	if self.active != nil && self.active.healthy && self.active.client != nil {
+		healthy = append(healthy, conn{self.active, self.active.client, self.active.rpc})
+	}
+	for _, e := range self.endpoints {
+		if e.client == nil {
+			continue
+		}
+		connected = append(connected, conn{e, e.client, e.rpc})
+		if e.healthy && e != self.active {
+			healthy = append(healthy, conn{e, e.client, e.rpc})
+		}
+	}
+	if len(healthy) == 0 {
+		return connected
+	}
+	return healthy
+}
+
+// do runs the request on the active endpoint and
+// retries it on the next ones when it fails because of the connection to the node.
+func (self *Client) do(ctx context.Context, f func(conn) error) error {
+	err := errors.New("no connected node endpoint")
+	for _, c := range self.candidates() {
+		if err = f(c); err == nil || !isNodeError(err) || ctx.Err() != nil {
+			return err
+		}
+		self.markDown(c.endpoint, err)
+	}
+	return err
+}
+
+// isNodeError returns true when the error is caused by the connection to the node
+// and not by a response of the node like a revert or a missing transaction.
+func isNodeError(err error) bool {
+	if err == nil ||
+		errors.Is(err, ethereum.NotFound) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// subscription keeps a subscription on the active endpoint.
+// It is re-created when the subscription fails or the active endpoint changes.
+type subscription struct {
+	err   chan error
+	unsub chan struct{}
+	once  sync.Once
+}
+
+func (self *subscription) Unsubscribe() {
+	self.once.Do(func() { close(self.unsub) })
+}
+
+func (self *subscription) Err() <-chan error {
+	return self.err
+}
+
+func (self *Client) subscribe(ctx context.Context, sub func(*ethclient.Client) (ethereum.Subscription, error)) (ethereum.Subscription, error) {
+	var s ethereum.Subscription
+	if err := self.do(ctx, func(c conn) (err error) {
+		s, err = sub(c.client)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	fs := &subscription{err: make(chan error), unsub: make(chan struct{})}
+	go func() {
+		defer close(fs.err)
+		for {
+			self.mtx.RLock()
+			switched := self.switched
+			self.mtx.RUnlock()
+
+			select {
+			case <-fs.unsub:
+				s.Unsubscribe()
+				return
+			case err := <-s.Err():
+				level.Warn(self.logger).Log("msg", "subscription failed so re-subscribing", "err", err)
+			case <-switched:
+				level.Info(self.logger).Log("msg", "node endpoint switched so re-subscribing")
+			}
+			s.Unsubscribe()
+
+			for {
+				ctx, cncl := context.WithTimeout(context.Background(), healthCheckInterval)
+				err := self.do(ctx, func(c conn) (err error) {
+					s, err = sub(c.client)
+					return err
+				})
+				cncl()
+				if err == nil {
+					break
+				}
+				level.Error(self.logger).Log("msg", "re-subscribing", "err", err)
+				select {
+				case <-fs.unsub:
+					return
+				case <-time.After(resubscribeDelay):
+				}
+			}
+		}
+	}()
+	return fs, nil
+}
+
+func (self *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return self.subscribe(ctx, func(c *ethclient.Client) (ethereum.Subscription, error) {
+		return c.SubscribeNewHead(ctx, ch)
+	})
+}
+
+func (self *Client) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return self.subscribe(ctx, func(c *ethclient.Client) (ethereum.Subscription, error) {
+		return c.SubscribeFilterLogs(ctx, q, ch)
+	})
+}
+
+// CallContext does a raw JSON-RPC call for methods without a typed wrapper.
+func (self *Client) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return self.do(ctx, func(c conn) error {
+		return c.rpc.CallContext(ctx, result, method, args...)
+	})
+}
+
+func (self *Client) ChainID(ctx context.Context) (r *big.Int, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.ChainID(ctx); return err })
+	return r, err
+}
+
+func (self *Client) NetworkID(ctx context.Context) (r *big.Int, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.NetworkID(ctx); return err })
+	return r, err
+}
+
+func (self *Client) BlockNumber(ctx context.Context) (r uint64, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.BlockNumber(ctx); return err })
+	return r, err
+}
+
+func (self *Client) BlockByNumber(ctx context.Context, number *big.Int) (r *types.Block, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.BlockByNumber(ctx, number); return err })
+	return r, err
+}
+
+func (self *Client) HeaderByNumber(ctx context.Context, number *big.Int) (r *types.Header, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.HeaderByNumber(ctx, number); return err })
+	return r, err
+}
+
+func (self *Client) HeaderByHash(ctx context.Context, hash common.Hash) (r *types.Header, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.HeaderByHash(ctx, hash); return err })
+	return r, err
+}
+
+func (self *Client) TransactionByHash(ctx context.Context, hash common.Hash) (r *types.Transaction, isPending bool, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, isPending, err = c.client.TransactionByHash(ctx, hash); return err })
+	return r, isPending, err
+}
+
+func (self *Client) TransactionReceipt(ctx context.Context, hash common.Hash) (r *types.Receipt, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.TransactionReceipt(ctx, hash); return err })
+	return r, err
+}
+
+func (self *Client) SyncProgress(ctx context.Context) (r *ethereum.SyncProgress, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.SyncProgress(ctx); return err })
+	return r, err
+}
+
+func (self *Client) BalanceAt(ctx context.Context, account common.Address, number *big.Int) (r *big.Int, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.BalanceAt(ctx, account, number); return err })
+	return r, err
+}
+
+func (self *Client) StorageAt(ctx context.Context, account common.Address, key common.Hash, number *big.Int) (r []byte, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.StorageAt(ctx, account, key, number); return err })
+	return r, err
+}
+
+func (self *Client) CodeAt(ctx context.Context, account common.Address, number *big.Int) (r []byte, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.CodeAt(ctx, account, number); return err })
+	return r, err
+}
+
+func (self *Client) NonceAt(ctx context.Context, account common.Address, number *big.Int) (r uint64, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.NonceAt(ctx, account, number); return err })
+	return r, err
+}
+
+func (self *Client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) (r []types.Log, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.FilterLogs(ctx, q); return err })
+	return r, err
+}
+
+func (self *Client) PendingCodeAt(ctx context.Context, account common.Address) (r []byte, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.PendingCodeAt(ctx, account); return err })
+	return r, err
+}
+
+func (self *Client) PendingNonceAt(ctx context.Context, account common.Address) (r uint64, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.PendingNonceAt(ctx, account); return err })
+	return r, err
+}
+
+func (self *Client) CallContract(ctx context.Context, msg ethereum.CallMsg, number *big.Int) (r []byte, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.CallContract(ctx, msg, number); return err })
+	return r, err
+}
+
+func (self *Client) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) (r []byte, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.PendingCallContract(ctx, msg); return err })
+	return r, err
+}
+
+func (self *Client) SuggestGasPrice(ctx context.Context) (r *big.Int, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.SuggestGasPrice(ctx); return err })
+	return r, err
+}
+
+func (self *Client) SuggestGasTipCap(ctx context.Context) (r *big.Int, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.SuggestGasTipCap(ctx); return err })
+	return r, err
+}
+
+func (self *Client) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (r *ethereum.FeeHistory, err error) {
+	err = self.do(ctx, func(c conn) (err error) {
+		r, err = c.client.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+		return err
+	})
+	return r, err
+}
+
+func (self *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (r uint64, err error) {
+	err = self.do(ctx, func(c conn) (err error) { r, err = c.client.EstimateGas(ctx, msg); return err })
+	return r, err
+}
+
+func (self *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return self.do(ctx, func(c conn) error { return c.client.SendTransaction(ctx, tx) })
+}
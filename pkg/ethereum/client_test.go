@@ -0,0 +1,95 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/go-kit/kit/log"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// fakeNode is a JSON-RPC node that answers only the requests used by the health check.
+type fakeNode struct {
+	head     uint64
+	down     int32
+	requests int32
+}
+
+func (self *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&self.down) == 1 {
+		http.Error(w, "node is down", http.StatusServiceUnavailable)
+		return
+	}
+	atomic.AddInt32(&self.requests, 1)
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var result string
+	switch req.Method {
+	case "eth_syncing":
+		result = "false"
+	case "eth_blockNumber":
+		result = fmt.Sprintf(`"0x%x"`, atomic.LoadUint64(&self.head))
+	case "net_version":
+		result = `"1"`
+	default:
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":3,"message":"execution reverted"}}`, req.ID)
+		return
+	}
+	fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+}
+
+func TestClientFailover(t *testing.T) {
+	ctx := context.Background()
+	primary, backup := &fakeNode{head: 100}, &fakeNode{head: 100}
+	srvPrimary, srvBackup := httptest.NewServer(primary), httptest.NewServer(backup)
+	defer srvPrimary.Close()
+	defer srvBackup.Close()
+
+	defer os.Setenv(NodeURLEnvName, os.Getenv(NodeURLEnvName))
+	testutil.Ok(t, os.Setenv(NodeURLEnvName, srvPrimary.URL+", "+srvBackup.URL))
+	client, err := NewClient(ctx, log.NewNopLogger())
+	testutil.Ok(t, err)
+	defer client.Close()
+	testutil.Equals(t, client.endpoints[0], client.active)
+
+	// Node responses like reverts are returned without a failover.
+	_, err = client.CallContract(ctx, ethereum.CallMsg{}, nil)
+	testutil.NotOk(t, err)
+	testutil.Equals(t, client.endpoints[0], client.active)
+
+	// Requests are retried on the backup when the primary is down.
+	atomic.StoreInt32(&primary.down, 1)
+	requests := atomic.LoadInt32(&backup.requests)
+	head, err := client.BlockNumber(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(100), head)
+	testutil.Equals(t, requests+1, atomic.LoadInt32(&backup.requests))
+	testutil.Equals(t, client.endpoints[1], client.active)
+
+	// The primary stays inactive while it lags behind.
+	atomic.StoreInt32(&primary.down, 0)
+	atomic.StoreUint64(&backup.head, 110)
+	client.check(ctx)
+	testutil.Equals(t, client.endpoints[1], client.active)
+
+	// and is used again when it catches up.
+	atomic.StoreUint64(&primary.head, 109)
+	client.check(ctx)
+	testutil.Equals(t, client.endpoints[0], client.active)
+}
@@ -11,13 +11,13 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/log/level"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/pkg/errors"
 )
 
@@ -76,22 +76,36 @@ func DecodeHex(s string) []byte {
 	return b
 }
 
+// DynamicFees returns the fee cap and the tip of a dynamic fee transaction.
+type DynamicFees func(ctx context.Context) (gasFeeCap, gasTipCap *big.Int, err error)
+
+// PrepareEthTransaction returns the options for a transaction from the account.
+// A dynamic fee transaction with the fees from dynamicFees is used
+// when the chain has a base fee and the gas price is not set.
 func PrepareEthTransaction(
 	ctx context.Context,
-	client *ethclient.Client,
+	client *Client,
 	account *Account,
 	gasPrice *big.Int,
+	dynamicFees DynamicFees,
 ) (*bind.TransactOpts, error) {
 
-	nonce, err := client.PendingNonceAt(ctx, account.GetAddress())
-	if err != nil {
-		return nil, errors.Wrap(err, "getting pending nonce")
-	}
-
+	var gasFeeCap, gasTipCap *big.Int
 	if gasPrice == nil {
-		gasPrice, err = client.SuggestGasPrice(ctx)
+		head, err := client.HeaderByNumber(ctx, nil)
 		if err != nil {
-			return nil, errors.Wrap(err, "getting gas price")
+			return nil, errors.Wrap(err, "getting last block header")
+		}
+		if head.BaseFee != nil {
+			gasFeeCap, gasTipCap, err = dynamicFees(ctx)
+			if err != nil {
+				return nil, errors.Wrap(err, "getting dynamic fees")
+			}
+		} else {
+			gasPrice, err = client.SuggestGasPrice(ctx)
+			if err != nil {
+				return nil, errors.Wrap(err, "getting gas price")
+			}
 		}
 	}
 
@@ -100,25 +114,34 @@ func PrepareEthTransaction(
 		return nil, errors.Wrap(err, "getting balance")
 	}
 
-	cost := new(big.Int)
-	cost.Mul(gasPrice, big.NewInt(700000))
-	if ethBalance.Cmp(cost) < 0 {
-		return nil, errors.Errorf("insufficient ethereum to send a transaction: %v < %v", ethBalance, cost)
-	}
-
 	netID, err := client.NetworkID(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting network id")
 	}
 
-	auth, err := bind.NewKeyedTransactorWithChainID(account.GetPrivateKey(), netID)
+	auth, err := account.TransactOpts(ctx, netID)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating transactor")
 	}
+	nonce, err := account.Nonces(client).Next(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting nonce")
+	}
 	auth.Nonce = big.NewInt(int64(nonce))
-	auth.Value = big.NewInt(0)        // in wei
-	auth.GasLimit = uint64(3_000_000) // in units
+	auth.Value = big.NewInt(0) // in wei
 	auth.GasPrice = gasPrice
+	auth.GasFeeCap = gasFeeCap
+	auth.GasTipCap = gasTipCap
+
+	// The gas limit is left empty so that the contract bindings estimate it and
+	// the balance is checked against the cost with the estimated gas before signing.
+	signer := auth.Signer
+	auth.Signer = func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if ethBalance.Cmp(tx.Cost()) < 0 {
+			return nil, errors.Errorf("insufficient ethereum to send a transaction: %v < %v", ethBalance, tx.Cost())
+		}
+		return signer(address, tx)
+	}
 	return auth, nil
 }
 
@@ -131,8 +154,23 @@ func Keccak256(input []byte) [32]byte {
 }
 
 type Account struct {
-	Address    common.Address
+	Address common.Address
+	// PrivateKey is set only for accounts with a key held in memory.
 	PrivateKey *ecdsa.PrivateKey
+	Signer     Signer
+
+	noncesOnce sync.Once
+	nonces     *NonceManager
+}
+
+// Nonces returns the nonce manager of the account which is shared by
+// all transactions of the account in the process.
+// The client is used only by the first call that creates the manager.
+func (a *Account) Nonces(client NonceReader) *NonceManager {
+	a.noncesOnce.Do(func() {
+		a.nonces = NewNonceManager(client, a.Address)
+	})
+	return a.nonces
 }
 
 func (a *Account) GetAddress() common.Address {
@@ -143,8 +181,8 @@ func (a *Account) GetPrivateKey() *ecdsa.PrivateKey {
 	return a.PrivateKey
 }
 
-func GetAccountByPubAddess(pubAddr string) (*Account, error) {
-	accounts, err := GetAccounts()
+func GetAccountByPubAddess(cfg AccountsConfig, pubAddr string) (*Account, error) {
+	accounts, err := GetAccounts(cfg)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting accounts")
 	}
@@ -157,9 +195,49 @@ func GetAccountByPubAddess(pubAddr string) (*Account, error) {
 	return nil, errors.Errorf("account not found:%v", pubAddr)
 }
 
-// GetAccounts returns a slice of Account from private keys in
-// PrivateKeysEnvName environment variable.
-func GetAccounts() ([]*Account, error) {
+// GetAccounts returns a slice of Account from the configured source and
+// the accounts of the remote signer at RemoteSignerURLEnvName.
+// The env source uses the private keys in PrivateKeysEnvName environment variable.
+func GetAccounts(cfg AccountsConfig) ([]*Account, error) {
+	var accounts []*Account
+	switch cfg.Source {
+	case AccountsSourceEnv, "":
+		if os.Getenv(PrivateKeysEnvName) != "" {
+			keyAccounts, err := getKeyAccounts()
+			if err != nil {
+				return nil, err
+			}
+			accounts = append(accounts, keyAccounts...)
+		}
+	case AccountsSourceKeystore:
+		keystoreAccounts, err := getKeystoreAccounts(cfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting keystore accounts")
+		}
+		accounts = append(accounts, keystoreAccounts...)
+	case AccountsSourceMnemonic:
+		mnemonicAccounts, err := getMnemonicAccounts(cfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting mnemonic accounts")
+		}
+		accounts = append(accounts, mnemonicAccounts...)
+	default:
+		return nil, errors.Errorf("unknown accounts source:%v", cfg.Source)
+	}
+	if os.Getenv(RemoteSignerURLEnvName) != "" {
+		remoteAccounts, err := getRemoteAccounts()
+		if err != nil {
+			return nil, errors.Wrap(err, "getting remote signer accounts")
+		}
+		accounts = append(accounts, remoteAccounts...)
+	}
+	if len(accounts) == 0 {
+		return nil, errors.Errorf("no accounts for source:%v, and no remote signer is set with the %v env variable", cfg.Source, RemoteSignerURLEnvName)
+	}
+	return accounts, nil
+}
+
+func getKeyAccounts() ([]*Account, error) {
 	_privateKeys := os.Getenv(PrivateKeysEnvName)
 	privateKeys := strings.Split(_privateKeys, ",")
 
@@ -178,36 +256,39 @@ func GetAccounts() ([]*Account, error) {
 		}
 
 		publicAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-		accounts[i] = &Account{Address: publicAddress, PrivateKey: privateKey}
+		accounts[i] = &Account{Address: publicAddress, PrivateKey: privateKey, Signer: NewKeySigner(privateKey)}
 	}
 	return accounts, nil
 }
 
-func NewClient(ctx context.Context, logger log.Logger) (*ethclient.Client, error) {
-	nodeURL := os.Getenv(NodeURLEnvName)
-
-	client, err := ethclient.DialContext(ctx, nodeURL)
+// getRemoteAccounts returns the accounts listed in RemoteSignerAccountsEnvName or
+// all accounts of the remote signer when the list is empty.
+func getRemoteAccounts() ([]*Account, error) {
+	ctx, cncl := context.WithTimeout(context.Background(), remoteSignerTimeout)
+	defer cncl()
+	client, err := rpc.DialContext(ctx, os.Getenv(RemoteSignerURLEnvName))
 	if err != nil {
-		return nil, errors.Wrap(err, "create rpc client instance")
+		return nil, errors.Wrap(err, "dial remote signer")
 	}
 
-	if !strings.Contains(strings.ToLower(nodeURL), "arbitrum") { // Arbitrum nodes doesn't support sync checking.
-		// Issue #55, halt if client is still syncing with Ethereum network
-		s, err := client.SyncProgress(ctx)
-		if err != nil {
-			return nil, errors.Wrap(err, "determining if Ethereum client is syncing")
+	var addresses []common.Address
+	if list := os.Getenv(RemoteSignerAccountsEnvName); list != "" {
+		for _, addr := range strings.Split(list, ",") {
+			addr = strings.TrimSpace(addr)
+			if err := ValidateAddress(addr); err != nil {
+				return nil, errors.Wrapf(err, "remote signer account:%v", addr)
+			}
+			addresses = append(addresses, common.HexToAddress(addr))
 		}
-		if s != nil {
-			return nil, errors.New("ethereum node is still syncing with the network")
+	} else {
+		if err := client.CallContext(ctx, &addresses, "eth_accounts"); err != nil {
+			return nil, errors.Wrap(err, "remote signer eth_accounts")
 		}
 	}
 
-	id, err := client.NetworkID(ctx)
-	if err != nil {
-		return nil, level.Error(logger).Log("msg", "get nerwork ID", "err", err)
+	accounts := make([]*Account, len(addresses))
+	for i, addr := range addresses {
+		accounts[i] = &Account{Address: addr, Signer: NewRemoteSigner(client, addr)}
 	}
-
-	level.Info(logger).Log("msg", "client created", "netID", id.String())
-
-	return client, nil
+	return accounts, nil
 }
@@ -0,0 +1,185 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+type NonceReader interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+}
+
+// NonceManager allocates the nonces of a single account in order
+// so that all transactors of the account can send transactions at the same time.
+// It keeps track of the allocated and pending nonces and
+// re-syncs from the chain after Reset.
+type NonceManager struct {
+	mtx     sync.Mutex
+	client  NonceReader
+	address common.Address
+	synced  bool
+	// next is the first nonce to try for the next allocation.
+	next uint64
+	// allocated holds the nonces in use and the hash of the last transaction sent with them.
+	// The hash is empty until the transaction is sent.
+	allocated map[uint64]common.Hash
+	// abandoned holds the last transaction of the nonces given back while it is still pending.
+	// The next user of the nonce must replace the transaction.
+	abandoned map[uint64]*types.Transaction
+}
+
+func NewNonceManager(client NonceReader, address common.Address) *NonceManager {
+	return &NonceManager{
+		client:    client,
+		address:   address,
+		allocated: make(map[uint64]common.Hash),
+		abandoned: make(map[uint64]*types.Transaction),
+	}
+}
+
+// Next allocates the lowest nonce that is not used by the chain
+// or by another transaction of the process.
+// The nonce must be given back with Release when the transaction isn't sent or
+// isn't waited for anymore and with Done when it is mined.
+func (self *NonceManager) Next(ctx context.Context) (uint64, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	if !self.synced {
+		nonce, err := self.client.PendingNonceAt(ctx, self.address)
+		if err != nil {
+			return 0, errors.Wrap(err, "getting pending nonce")
+		}
+		// Nonces below the chain nonce are already mined.
+		for n := range self.allocated {
+			if n < nonce {
+				delete(self.allocated, n)
+			}
+		}
+		for n := range self.abandoned {
+			if n < nonce {
+				delete(self.abandoned, n)
+			}
+		}
+		// Nonces of dropped transactions are reused so that they don't leave a gap.
+		for n, hash := range self.allocated {
+			if hash == (common.Hash{}) {
+				continue
+			}
+			_, _, err := self.client.TransactionByHash(ctx, hash)
+			if errors.Is(err, ethereum.NotFound) {
+				delete(self.allocated, n)
+				continue
+			}
+			if err != nil {
+				return 0, errors.Wrapf(err, "getting the transaction of nonce:%v", n)
+			}
+		}
+		for n, tx := range self.abandoned {
+			_, _, err := self.client.TransactionByHash(ctx, tx.Hash())
+			if errors.Is(err, ethereum.NotFound) {
+				delete(self.abandoned, n)
+				continue
+			}
+			if err != nil {
+				return 0, errors.Wrapf(err, "getting the transaction of nonce:%v", n)
+			}
+		}
+		self.next = nonce
+		self.synced = true
+	}
+
+	nonce := self.next
+	for {
+		if _, ok := self.allocated[nonce]; !ok {
+			break
+		}
+		nonce++
+	}
+	self.allocated[nonce] = common.Hash{}
+	self.next = nonce + 1
+	return nonce, nil
+}
+
+// Sent records the hash of the last transaction sent with the nonce.
+// The transaction replaces the abandoned one of the nonce.
+func (self *NonceManager) Sent(nonce uint64, hash common.Hash) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.allocated[nonce] = hash
+	delete(self.abandoned, nonce)
+}
+
+// Abandon gives back a nonce which transaction is still pending but isn't waited for anymore.
+// The nonce stays pending with the transaction and is reused by the next allocation
+// which must replace the transaction with higher fees, see Abandoned.
+func (self *NonceManager) Abandon(nonce uint64, tx *types.Transaction) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	delete(self.allocated, nonce)
+	self.abandoned[nonce] = tx
+	if nonce < self.next {
+		self.next = nonce
+	}
+}
+
+// Abandoned returns the pending transaction that the user of the nonce must replace
+// or nil when the nonce has no abandoned transaction.
+func (self *NonceManager) Abandoned(nonce uint64) *types.Transaction {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.abandoned[nonce]
+}
+
+// Release gives back a nonce that wasn't used so that the next allocation doesn't leave a gap.
+// An abandoned transaction of the nonce is kept for the next allocation to replace it.
+func (self *NonceManager) Release(nonce uint64) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	delete(self.allocated, nonce)
+	if nonce < self.next {
+		self.next = nonce
+	}
+}
+
+// Done removes a nonce which transaction is mined.
+func (self *NonceManager) Done(nonce uint64) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	delete(self.allocated, nonce)
+	delete(self.abandoned, nonce)
+}
+
+// Reset makes the next allocation re-sync from the chain and
+// drop the sent nonces which transaction the node doesn't know anymore.
+// Used after errors like "nonce too low" when the chain state is different than expected.
+func (self *NonceManager) Reset() {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.synced = false
+}
+
+// Pending returns the hashes of the sent transactions that are not mined yet by nonce.
+func (self *NonceManager) Pending() map[uint64]common.Hash {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	pending := make(map[uint64]common.Hash)
+	for n, tx := range self.abandoned {
+		pending[n] = tx.Hash()
+	}
+	for n, h := range self.allocated {
+		if h != (common.Hash{}) {
+			pending[n] = h
+		}
+	}
+	return pending
+}
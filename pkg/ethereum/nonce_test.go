@@ -0,0 +1,138 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+type nonceReader struct {
+	nonce uint64
+	// dropped holds the transactions that the node doesn't know.
+	dropped map[common.Hash]bool
+}
+
+func (self *nonceReader) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return self.nonce, nil
+}
+
+func (self *nonceReader) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if self.dropped[hash] {
+		return nil, false, ethereum.NotFound
+	}
+	return &types.Transaction{}, true, nil
+}
+
+func TestNonceManager(t *testing.T) {
+	ctx := context.Background()
+	chain := &nonceReader{nonce: 5}
+	nonces := NewNonceManager(chain, common.Address{})
+
+	n1, err := nonces.Next(ctx)
+	testutil.Ok(t, err)
+	n2, err := nonces.Next(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(5), n1)
+	testutil.Equals(t, uint64(6), n2)
+
+	// A released nonce is reused so that there are no gaps.
+	nonces.Release(n1)
+	n3, err := nonces.Next(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(5), n3)
+
+	hash := common.HexToHash("0x01")
+	nonces.Sent(n2, hash)
+	testutil.Equals(t, map[uint64]common.Hash{6: hash}, nonces.Pending())
+
+	// After a re-sync the nonces in use by the process are skipped.
+	chain.nonce = 6
+	nonces.Reset()
+	n4, err := nonces.Next(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(7), n4)
+
+	nonces.Done(n2)
+	testutil.Equals(t, map[uint64]common.Hash{}, nonces.Pending())
+}
+
+func TestNonceManagerDropped(t *testing.T) {
+	ctx := context.Background()
+	chain := &nonceReader{nonce: 5, dropped: make(map[common.Hash]bool)}
+	nonces := NewNonceManager(chain, common.Address{})
+
+	n1, err := nonces.Next(ctx)
+	testutil.Ok(t, err)
+	n2, err := nonces.Next(ctx)
+	testutil.Ok(t, err)
+	n3, err := nonces.Next(ctx)
+	testutil.Ok(t, err)
+	nonces.Sent(n1, common.HexToHash("0x01"))
+	nonces.Sent(n2, common.HexToHash("0x02"))
+	nonces.Sent(n3, common.HexToHash("0x03"))
+
+	// An abandoned nonce is reused to replace its transaction.
+	nonces.Release(n2)
+	n, err := nonces.Next(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, n2, n)
+	nonces.Sent(n, common.HexToHash("0x04"))
+
+	// A dropped transaction doesn't leave a gap after a re-sync.
+	chain.dropped[common.HexToHash("0x01")] = true
+	nonces.Reset()
+	n, err = nonces.Next(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, n1, n)
+	n, err = nonces.Next(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(8), n)
+	testutil.Equals(t, map[uint64]common.Hash{6: common.HexToHash("0x04"), 7: common.HexToHash("0x03")}, nonces.Pending())
+}
+
+func TestNonceManagerAbandon(t *testing.T) {
+	ctx := context.Background()
+	chain := &nonceReader{nonce: 5, dropped: make(map[common.Hash]bool)}
+	nonces := NewNonceManager(chain, common.Address{})
+
+	n1, err := nonces.Next(ctx)
+	testutil.Ok(t, err)
+	tx := types.NewTransaction(n1, common.Address{}, nil, 21000, big.NewInt(100), nil)
+	nonces.Sent(n1, tx.Hash())
+
+	// An abandoned nonce stays pending and the next user of the nonce gets its transaction to replace.
+	nonces.Abandon(n1, tx)
+	testutil.Equals(t, map[uint64]common.Hash{5: tx.Hash()}, nonces.Pending())
+	n, err := nonces.Next(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, n1, n)
+	testutil.Equals(t, tx, nonces.Abandoned(n))
+
+	// A user that doesn't send keeps the transaction for the next one.
+	nonces.Release(n)
+	n, err = nonces.Next(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, tx, nonces.Abandoned(n))
+
+	replacement := types.NewTransaction(n1, common.Address{}, nil, 21000, big.NewInt(112), nil)
+	nonces.Sent(n, replacement.Hash())
+	testutil.Assert(t, nonces.Abandoned(n) == nil, "the sent transaction should replace the abandoned one")
+	testutil.Equals(t, map[uint64]common.Hash{5: replacement.Hash()}, nonces.Pending())
+
+	// A dropped abandoned transaction is forgotten after a re-sync.
+	nonces.Abandon(n, replacement)
+	chain.dropped[replacement.Hash()] = true
+	nonces.Reset()
+	n, err = nonces.Next(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, n1, n)
+	testutil.Assert(t, nonces.Abandoned(n) == nil, "the dropped transaction shouldn't be replaced")
+}
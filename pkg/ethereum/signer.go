@@ -0,0 +1,166 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+const RemoteSignerURLEnvName = "REMOTE_SIGNER_URL"
+const RemoteSignerAccountsEnvName = "REMOTE_SIGNER_ACCOUNTS"
+
+// remoteSignerTimeout is how long to wait for the remote signer.
+// Some signers ask for a manual approval so it needs to be long enough for that.
+const remoteSignerTimeout = time.Minute
+
+// Signer signs transactions for a single account.
+type Signer interface {
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// KeySigner signs with a private key held in memory.
+type KeySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func NewKeySigner(key *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{key: key}
+}
+
+func (self *KeySigner) SignTx(_ context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), self.key)
+	if err != nil {
+		return nil, errors.Wrap(err, "sign transaction")
+	}
+	return signed, nil
+}
+
+// SignTxArgs are the eth_signTransaction arguments.
+// GasPrice is set for legacy transactions and
+// MaxFeePerGas and MaxPriorityFeePerGas for dynamic fee transactions.
+type SignTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId,omitempty"`
+}
+
+// SignTxResult is the eth_signTransaction result returned by Clef and Geth.
+type SignTxResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// RemoteSigner signs through an external JSON-RPC signer with eth_signTransaction
+// so that the private keys can be kept in a separate process.
+type RemoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+func NewRemoteSigner(client *rpc.Client, address common.Address) *RemoteSigner {
+	return &RemoteSigner{client: client, address: address}
+}
+
+func (self *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := SignTxArgs{
+		From:    self.address,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   (*hexutil.Big)(tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    tx.Data(),
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, remoteSignerTimeout)
+	defer cncl()
+	var result json.RawMessage
+	if err := self.client.CallContext(ctx, &result, "eth_signTransaction", args); err != nil {
+		return nil, errors.Wrap(err, "remote signer eth_signTransaction")
+	}
+
+	// Clef and Geth return an object with the raw transaction and
+	// other signers like Web3Signer return only the raw transaction.
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(result, &raw); err != nil {
+		var res SignTxResult
+		if err := json.Unmarshal(result, &res); err != nil {
+			return nil, errors.Wrap(err, "parse remote signer result")
+		}
+		raw = res.Raw
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, errors.Wrap(err, "decode signed transaction")
+	}
+
+	// Don't trust the remote signer to sign exactly what was requested.
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		return nil, errors.Wrap(err, "recover the signed transaction sender")
+	}
+	if sender != self.address {
+		return nil, errors.Errorf("signed transaction sender mismatch expected:%v, actual:%v", self.address.Hex(), sender.Hex())
+	}
+	if signed.Type() != tx.Type() ||
+		signed.Nonce() != tx.Nonce() ||
+		signed.Gas() != tx.Gas() ||
+		signed.GasFeeCap().Cmp(tx.GasFeeCap()) != 0 ||
+		signed.GasTipCap().Cmp(tx.GasTipCap()) != 0 ||
+		signed.Value().Cmp(tx.Value()) != 0 ||
+		!equalAddress(signed.To(), tx.To()) ||
+		!bytes.Equal(signed.Data(), tx.Data()) {
+		return nil, errors.New("the remote signer signed a different transaction than requested")
+	}
+	return signed, nil
+}
+
+// TransactOpts returns the options for the contract bindings that sign with the account signer.
+func (a *Account) TransactOpts(ctx context.Context, chainID *big.Int) (*bind.TransactOpts, error) {
+	if a.Signer == nil {
+		return nil, errors.Errorf("account without a signer:%v", a.Address.Hex())
+	}
+	return &bind.TransactOpts{
+		From:    a.Address,
+		Context: ctx,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != a.Address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return a.Signer.SignTx(ctx, tx, chainID)
+		},
+	}, nil
+}
+
+func equalAddress(a, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,120 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// testSigner is a stand-in for an external signer like Clef.
+type testSigner struct {
+	key *ecdsa.PrivateKey
+	// tamper makes the signer sign a different transaction than requested.
+	tamper bool
+}
+
+func (self *testSigner) Accounts() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(self.key.PublicKey)}
+}
+
+func (self *testSigner) SignTransaction(args SignTxArgs) (*SignTxResult, error) {
+	nonce := uint64(args.Nonce)
+	if self.tamper {
+		nonce++
+	}
+	var tx *types.Transaction
+	if args.MaxFeePerGas != nil {
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   args.ChainID.ToInt(),
+			Nonce:     nonce,
+			To:        args.To,
+			Gas:       uint64(args.Gas),
+			GasFeeCap: args.MaxFeePerGas.ToInt(),
+			GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+			Value:     args.Value.ToInt(),
+			Data:      args.Data,
+		})
+	} else {
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			To:       args.To,
+			Gas:      uint64(args.Gas),
+			GasPrice: args.GasPrice.ToInt(),
+			Value:    args.Value.ToInt(),
+			Data:     args.Data,
+		})
+	}
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(args.ChainID.ToInt()), self.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &SignTxResult{Raw: raw, Tx: signed}, nil
+}
+
+func TestRemoteSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	signer := &testSigner{key: key}
+
+	server := rpc.NewServer()
+	testutil.Ok(t, server.RegisterName("eth", signer))
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	testutil.Ok(t, os.Setenv(RemoteSignerURLEnvName, srv.URL))
+	defer os.Unsetenv(RemoteSignerURLEnvName)
+	defer os.Setenv(PrivateKeysEnvName, os.Getenv(PrivateKeysEnvName))
+	testutil.Ok(t, os.Unsetenv(PrivateKeysEnvName))
+
+	accounts, err := GetAccounts(AccountsConfig{Source: AccountsSourceEnv})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(accounts))
+	testutil.Equals(t, crypto.PubkeyToAddress(key.PublicKey), accounts[0].Address)
+
+	chainID := big.NewInt(4)
+	to := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	tx := types.NewTransaction(7, to, big.NewInt(0), 3000000, big.NewInt(1e9), []byte{1, 2, 3})
+
+	// Signing through the remote signer gives the same result as signing with the key.
+	signed, err := accounts[0].Signer.SignTx(context.Background(), tx, chainID)
+	testutil.Ok(t, err)
+	expected, err := NewKeySigner(key).SignTx(context.Background(), tx, chainID)
+	testutil.Ok(t, err)
+	testutil.Equals(t, expected.Hash(), signed.Hash())
+
+	dynamicTx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     7,
+		To:        &to,
+		Gas:       3000000,
+		GasFeeCap: big.NewInt(2e9),
+		GasTipCap: big.NewInt(1e9),
+		Value:     big.NewInt(0),
+		Data:      []byte{1, 2, 3},
+	})
+	signed, err = accounts[0].Signer.SignTx(context.Background(), dynamicTx, chainID)
+	testutil.Ok(t, err)
+	expected, err = NewKeySigner(key).SignTx(context.Background(), dynamicTx, chainID)
+	testutil.Ok(t, err)
+	testutil.Equals(t, expected.Hash(), signed.Hash())
+
+	signer.tamper = true
+	_, err = accounts[0].Signer.SignTx(context.Background(), tx, chainID)
+	testutil.NotOk(t, err)
+}
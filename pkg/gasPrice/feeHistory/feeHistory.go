@@ -0,0 +1,86 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package feeHistory
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/logging"
+)
+
+const ComponentName = "gasPriceFeeHistory"
+
+type Config struct {
+	LogLevel   string
+	Blocks     uint64  `help:"How many of the last blocks to use."`
+	Percentile float64 `help:"Percentile of the priority fees paid in each block. The median of all blocks is added to the next block base fee."`
+}
+
+// Client is the part of the node client used by the estimator.
+type Client interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// FeeHistory estimates the gas price from the eth_feeHistory of the last blocks
+// as the next block base fee plus the median of the priority fee percentile of each block.
+type FeeHistory struct {
+	cfg    Config
+	client Client
+	logger log.Logger
+}
+
+func New(logger log.Logger, cfg Config, client Client) (*FeeHistory, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	if cfg.Blocks == 0 {
+		return nil, errors.New("the fee history blocks can't be 0")
+	}
+	if cfg.Percentile < 0 || cfg.Percentile > 100 {
+		return nil, errors.Errorf("invalid fee history percentile:%v", cfg.Percentile)
+	}
+	return &FeeHistory{
+		cfg:    cfg,
+		client: client,
+		logger: log.With(logger, "component", ComponentName),
+	}, nil
+}
+
+// Query returns the estimated gas price.
+// Chains without a base fee use the node suggested gas price.
+func (self *FeeHistory) Query(ctx context.Context) (*big.Int, error) {
+	history, err := self.client.FeeHistory(ctx, self.cfg.Blocks, nil, []float64{self.cfg.Percentile})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting fee history")
+	}
+	if len(history.BaseFee) == 0 || history.BaseFee[len(history.BaseFee)-1] == nil || history.BaseFee[len(history.BaseFee)-1].Sign() == 0 {
+		gasPrice, err := self.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting suggested gas price")
+		}
+		return gasPrice, nil
+	}
+	// The last base fee is for the next block.
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+
+	var tips []*big.Int
+	for _, r := range history.Reward {
+		if len(r) > 0 && r[0] != nil {
+			tips = append(tips, r[0])
+		}
+	}
+	tip := big.NewInt(0)
+	if len(tips) > 0 {
+		sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+		tip = tips[len(tips)/2]
+	}
+	return new(big.Int).Add(baseFee, tip), nil
+}
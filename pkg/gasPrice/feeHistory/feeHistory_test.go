@@ -0,0 +1,46 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package feeHistory
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/go-kit/kit/log"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+type fakeClient struct {
+	history *ethereum.FeeHistory
+}
+
+func (self *fakeClient) FeeHistory(context.Context, uint64, *big.Int, []float64) (*ethereum.FeeHistory, error) {
+	return self.history, nil
+}
+
+func (self *fakeClient) SuggestGasPrice(context.Context) (*big.Int, error) {
+	return big.NewInt(7), nil
+}
+
+func TestQuery(t *testing.T) {
+	client := &fakeClient{history: &ethereum.FeeHistory{
+		BaseFee: []*big.Int{big.NewInt(90), big.NewInt(95), big.NewInt(100), big.NewInt(110)},
+		Reward:  [][]*big.Int{{big.NewInt(5)}, {big.NewInt(1)}, {big.NewInt(3)}},
+	}}
+	estimator, err := New(log.NewNopLogger(), Config{LogLevel: "info", Blocks: 3, Percentile: 50}, client)
+	testutil.Ok(t, err)
+
+	// The next block base fee plus the median priority fee.
+	price, err := estimator.Query(context.Background())
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(113), price)
+
+	// Chains without a base fee.
+	client.history = &ethereum.FeeHistory{BaseFee: []*big.Int{big.NewInt(0)}}
+	price, err = estimator.Query(context.Background())
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(7), price)
+}
@@ -9,11 +9,11 @@ import (
 	"math/big"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/format"
 	"github.com/tellor-io/telliot/pkg/web"
 )
@@ -21,13 +21,13 @@ import (
 const ComponentName = "gasPriceGasStation"
 
 type Config struct {
-	TimeWait format.Duration
+	TimeWait format.Duration `help:"Expected time until the transaction is mined. Below 30s uses the fastest price, below 2m the fast price and the average price otherwise."`
 }
 
 type GasStation struct {
 	netID  int64
 	cfg    Config
-	client *ethclient.Client
+	client *ethereum.Client
 	logger log.Logger
 }
 
@@ -38,7 +38,20 @@ type GasStationModel struct {
 	Average float32 `json:"average"`
 }
 
-func New(logger log.Logger, cfg Config, client *ethclient.Client) (*GasStation, error) {
+// price returns the price for the expected time until the transaction is mined.
+func (self GasStationModel) price(timeWait time.Duration) float32 {
+	// The shortest wait is checked first as the cases overlap.
+	switch {
+	case timeWait < 30*time.Second:
+		return self.Fastest
+	case timeWait < 2*time.Minute:
+		return self.Fast
+	default:
+		return self.Average
+	}
+}
+
+func New(logger log.Logger, cfg Config, client *ethereum.Client) (*GasStation, error) {
 	ctx, cncl := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cncl()
 	netID, err := client.NetworkID(ctx)
@@ -89,16 +102,6 @@ func (self *GasStation) Query(ctx context.Context) (gasPriceFinal *big.Int, errF
 		return nil, errors.Wrap(err, "provider response json unmarshal")
 	}
 
-	var gasPrice float32
-	switch t := self.cfg.TimeWait.Duration; {
-	case t < 5*time.Minute:
-		gasPrice = gpModel.Average
-	case t < 2*time.Minute:
-		gasPrice = gpModel.Fast
-	case t < 30*time.Second:
-		gasPrice = gpModel.Fastest
-	}
-
-	gasPriceB := big.NewInt(int64(gasPrice / 10))
+	gasPriceB := big.NewInt(int64(gpModel.price(self.cfg.TimeWait.Duration) / 10))
 	return big.NewInt(0).Mul(gasPriceB, big.NewInt(params.GWei)), nil
 }
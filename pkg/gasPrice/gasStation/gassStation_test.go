@@ -3,4 +3,18 @@
 
 package gasStation
 
+import (
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
 // TODO Add test - 1. Get price from Gas Station and fall back to client estimated gas.
+
+func TestTimeWait(t *testing.T) {
+	model := GasStationModel{Fastest: 3, Fast: 2, Average: 1}
+	testutil.Equals(t, float32(3), model.price(15*time.Second))
+	testutil.Equals(t, float32(2), model.price(time.Minute))
+	testutil.Equals(t, float32(1), model.price(5*time.Minute))
+}
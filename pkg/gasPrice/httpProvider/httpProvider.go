@@ -0,0 +1,124 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package httpProvider
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/go-kit/kit/log"
+	"github.com/itchyny/gojq"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/web"
+)
+
+const ComponentName = "gasPriceHTTPProvider"
+
+const timeout = 15 * time.Second
+
+type Config struct {
+	Name string `help:"Name used to select the provider as an oracle and in the metrics."`
+	URL  string `help:"Provider URL. Env variables like ${API_KEY} are expanded."`
+	// Query is a jq query that returns a single number or a numeric string.
+	Query string `help:"jq query that returns the gas price from the response, for example .result.FastGasPrice"`
+	// Multiplier converts the parsed value to gwei.
+	Multiplier float64           `help:"Multiplier that converts the parsed value to gwei, 0 means the value is already in gwei."`
+	Headers    map[string]string `help:"HTTP headers sent with the request."`
+}
+
+// Provider gets the gas price from an HTTP API.
+type Provider struct {
+	cfg    Config
+	url    string
+	query  *gojq.Query
+	logger log.Logger
+}
+
+func New(logger log.Logger, cfg Config) (*Provider, error) {
+	if cfg.Name == "" {
+		return nil, errors.New("missing gas price provider name")
+	}
+	query, err := gojq.Parse(cfg.Query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the query of gas price provider:%v", cfg.Name)
+	}
+	url := os.Expand(cfg.URL, func(key string) string {
+		if os.Getenv(key) == "" {
+			err = errors.Errorf("missing required env variable in gas price provider url:%v", key)
+		}
+		return os.Getenv(key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{
+		cfg:    cfg,
+		url:    url,
+		query:  query,
+		logger: log.With(logger, "component", ComponentName, "provider", cfg.Name),
+	}, nil
+}
+
+func (self *Provider) Query(ctx context.Context) (*big.Int, error) {
+	ctx, cncl := context.WithTimeout(ctx, timeout)
+	defer cncl()
+	resp, err := web.Get(ctx, self.url, self.cfg.Headers)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch price from provider")
+	}
+	gwei, err := self.parse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if self.cfg.Multiplier != 0 {
+		gwei *= self.cfg.Multiplier
+	}
+	if gwei <= 0 || math.IsInf(gwei, 0) || math.IsNaN(gwei) {
+		return nil, errors.Errorf("invalid gas price:%v", gwei)
+	}
+	wei, _ := new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(params.GWei)).Int(nil)
+	return wei, nil
+}
+
+// parse runs the query on the response and returns the result as a number.
+func (self *Provider) parse(resp []byte) (float64, error) {
+	var input interface{}
+	if err := json.Unmarshal(resp, &input); err != nil {
+		return 0, errors.Wrap(err, "provider response json unmarshal")
+	}
+	iter := self.query.Run(input)
+	output, ok := iter.Next()
+	if !ok {
+		return 0, errors.New("the query returned no value")
+	}
+	if _, ok := iter.Next(); ok {
+		return 0, errors.New("the query returned multiple values")
+	}
+
+	switch v := output.(type) {
+	case error:
+		return 0, errors.Wrap(v, "running the query")
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(v).Float64()
+		return f, nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "parsing the query result:%v", v)
+		}
+		return f, nil
+	default:
+		return 0, errors.Errorf("unsupported query result type:%T", output)
+	}
+}
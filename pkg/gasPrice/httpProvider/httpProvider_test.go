@@ -0,0 +1,45 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package httpProvider
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testutil.Equals(t, "key", r.URL.Query().Get("apikey"))
+		_, _ = w.Write([]byte(`{"status":"1","result":{"SafeGasPrice":"30","ProposeGasPrice":"42.5"},"fast":450}`))
+	}))
+	defer srv.Close()
+
+	defer os.Setenv("GAS_API_KEY", os.Getenv("GAS_API_KEY"))
+	testutil.Ok(t, os.Setenv("GAS_API_KEY", "key"))
+
+	provider, err := New(log.NewNopLogger(), Config{Name: "etherscan", URL: srv.URL + "?apikey=${GAS_API_KEY}", Query: ".result.ProposeGasPrice"})
+	testutil.Ok(t, err)
+	price, err := provider.Query(context.Background())
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(42.5e9), price)
+
+	// Values in gwei*10 like ethgasstation.
+	provider, err = New(log.NewNopLogger(), Config{Name: "gasStation", URL: srv.URL + "?apikey=${GAS_API_KEY}", Query: ".fast", Multiplier: 0.1})
+	testutil.Ok(t, err)
+	price, err = provider.Query(context.Background())
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(45e9), price)
+
+	provider, err = New(log.NewNopLogger(), Config{Name: "missing", URL: srv.URL + "?apikey=${GAS_API_KEY}", Query: ".result.FastGasPrice"})
+	testutil.Ok(t, err)
+	_, err = provider.Query(context.Background())
+	testutil.NotOk(t, err)
+}
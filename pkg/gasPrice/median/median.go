@@ -0,0 +1,84 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package median
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/gasPrice"
+)
+
+const ComponentName = "gasPriceMedian"
+
+// Median returns the median price of multiple oracles.
+// Oracles that fail are skipped and the result is cached for the ttl.
+type Median struct {
+	logger   log.Logger
+	oracles  map[string]gasPrice.GasPriceQuerier
+	ttl      time.Duration
+	mtx      sync.Mutex
+	cached   *big.Int
+	cachedAt time.Time
+}
+
+func New(logger log.Logger, oracles map[string]gasPrice.GasPriceQuerier, ttl time.Duration) (*Median, error) {
+	if len(oracles) == 0 {
+		return nil, errors.New("the median needs at least one oracle")
+	}
+	return &Median{
+		logger:  log.With(logger, "component", ComponentName),
+		oracles: oracles,
+		ttl:     ttl,
+	}, nil
+}
+
+func (self *Median) Query(ctx context.Context) (*big.Int, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if self.cached != nil && time.Since(self.cachedAt) < self.ttl {
+		return new(big.Int).Set(self.cached), nil
+	}
+
+	type quote struct {
+		name  string
+		price *big.Int
+		err   error
+	}
+	quotes := make(chan quote, len(self.oracles))
+	for name, oracle := range self.oracles {
+		go func(name string, oracle gasPrice.GasPriceQuerier) {
+			price, err := oracle.Query(ctx)
+			quotes <- quote{name: name, price: price, err: err}
+		}(name, oracle)
+	}
+
+	var prices []*big.Int
+	for range self.oracles {
+		q := <-quotes
+		if q.err != nil {
+			level.Warn(self.logger).Log("msg", "skipping gas price oracle", "oracle", q.name, "err", q.err)
+			continue
+		}
+		prices = append(prices, q.price)
+	}
+	if len(prices) == 0 {
+		return nil, errors.New("all gas price oracles failed")
+	}
+
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) < 0 })
+	median := new(big.Int).Set(prices[len(prices)/2])
+	if len(prices)%2 == 0 {
+		median.Add(median, prices[len(prices)/2-1])
+		median.Div(median, big.NewInt(2))
+	}
+	self.cached, self.cachedAt = median, time.Now()
+	return new(big.Int).Set(median), nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package median
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/gasPrice"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+type fixed struct {
+	price   int64
+	err     error
+	queries int
+}
+
+func (self *fixed) Query(context.Context) (*big.Int, error) {
+	self.queries++
+	if self.err != nil {
+		return nil, self.err
+	}
+	return big.NewInt(self.price), nil
+}
+
+func TestMedian(t *testing.T) {
+	a, b, c := &fixed{price: 10}, &fixed{price: 30}, &fixed{err: errors.New("provider down")}
+	m, err := New(log.NewNopLogger(), map[string]gasPrice.GasPriceQuerier{"a": a, "b": b, "c": c}, time.Hour)
+	testutil.Ok(t, err)
+
+	// The failed oracle is skipped and the two middle values of an even count are averaged.
+	price, err := m.Query(context.Background())
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(20), price)
+
+	// Cached.
+	b.price = 50
+	price, err = m.Query(context.Background())
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(20), price)
+	testutil.Equals(t, 1, a.queries)
+
+	m, err = New(log.NewNopLogger(), map[string]gasPrice.GasPriceQuerier{"a": a, "b": b, "c": &fixed{price: 40}}, 0)
+	testutil.Ok(t, err)
+	price, err = m.Query(context.Background())
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(40), price)
+
+	m, err = New(log.NewNopLogger(), map[string]gasPrice.GasPriceQuerier{"c": c}, 0)
+	testutil.Ok(t, err)
+	_, err = m.Query(context.Background())
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,156 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package oracle
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/gasPrice"
+	"github.com/tellor-io/telliot/pkg/gasPrice/feeHistory"
+	"github.com/tellor-io/telliot/pkg/gasPrice/gasStation"
+	"github.com/tellor-io/telliot/pkg/gasPrice/httpProvider"
+	"github.com/tellor-io/telliot/pkg/gasPrice/median"
+	"github.com/tellor-io/telliot/pkg/logging"
+)
+
+const ComponentName = "gasPrice"
+
+// Built in oracles.
+// The http providers are selected by their name.
+const (
+	OracleGasStation = "gasStation"
+	OracleNode       = "node"
+	OracleFeeHistory = "feeHistory"
+	OracleMedian     = "median"
+)
+
+var (
+	quotes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "quote_gwei",
+		Help:      "The last gas price quote of each oracle in gwei",
+	},
+		[]string{"oracle"},
+	)
+	quoteErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "errors_total",
+		Help:      "The total number of failed gas price queries of each oracle",
+	},
+		[]string{"oracle"},
+	)
+)
+
+type Config struct {
+	LogLevel   string
+	Oracle     string                `help:"Gas price oracle - gasStation(ethgasstation.info on mainnet and the node suggested price elsewhere), node(the node suggested price), feeHistory(the eth_feeHistory estimator), median(the median of the Median oracles) or the name of one of the Providers."`
+	Median     []string              `help:"Oracles used by the median oracle."`
+	CacheTTL   format.Duration       `help:"How long the median is cached."`
+	FeeHistory feeHistory.Config     `help:"The eth_feeHistory estimator."`
+	Providers  []httpProvider.Config `help:"HTTP gas price providers."`
+}
+
+// New returns the gas price oracle selected in the config.
+func New(logger log.Logger, cfg Config, gasStationCfg gasStation.Config, client *ethereum.Client) (gasPrice.GasPriceQuerier, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	logger = log.With(logger, "component", ComponentName)
+
+	providers := make(map[string]httpProvider.Config)
+	for _, p := range cfg.Providers {
+		switch p.Name {
+		case OracleGasStation, OracleNode, OracleFeeHistory, OracleMedian:
+			return nil, errors.Errorf("gas price provider name is reserved:%v", p.Name)
+		}
+		if _, ok := providers[p.Name]; ok {
+			return nil, errors.Errorf("duplicate gas price provider:%v", p.Name)
+		}
+		providers[p.Name] = p
+	}
+
+	build := func(name string) (gasPrice.GasPriceQuerier, error) {
+		var oracle gasPrice.GasPriceQuerier
+		var err error
+		switch name {
+		case OracleGasStation:
+			oracle, err = gasStation.New(logger, gasStationCfg, client)
+		case OracleNode:
+			oracle = &node{client: client}
+		case OracleFeeHistory:
+			oracle, err = feeHistory.New(logger, cfg.FeeHistory, client)
+		default:
+			p, ok := providers[name]
+			if !ok {
+				return nil, errors.Errorf("unknown gas price oracle:%v", name)
+			}
+			oracle, err = httpProvider.New(logger, p)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating gas price oracle:%v", name)
+		}
+		return &metered{name: name, oracle: oracle}, nil
+	}
+
+	if cfg.Oracle != OracleMedian {
+		return build(cfg.Oracle)
+	}
+	oracles := make(map[string]gasPrice.GasPriceQuerier)
+	for _, name := range cfg.Median {
+		if name == OracleMedian {
+			return nil, errors.New("the median can't include itself")
+		}
+		oracle, err := build(name)
+		if err != nil {
+			return nil, err
+		}
+		oracles[name] = oracle
+	}
+	m, err := median.New(logger, oracles, cfg.CacheTTL.Duration)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating median gas price oracle")
+	}
+	return &metered{name: OracleMedian, oracle: m}, nil
+}
+
+// node returns the gas price suggested by the node.
+type node struct {
+	client *ethereum.Client
+}
+
+func (self *node) Query(ctx context.Context) (*big.Int, error) {
+	gasPrice, err := self.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting suggested gas price")
+	}
+	return gasPrice, nil
+}
+
+// metered exports the quotes and errors of an oracle.
+type metered struct {
+	name   string
+	oracle gasPrice.GasPriceQuerier
+}
+
+func (self *metered) Query(ctx context.Context) (*big.Int, error) {
+	price, err := self.oracle.Query(ctx)
+	if err != nil {
+		quoteErrors.With(prometheus.Labels{"oracle": self.name}).Inc()
+		return nil, err
+	}
+	gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(price), big.NewFloat(params.GWei)).Float64()
+	quotes.With(prometheus.Labels{"oracle": self.name}).Set(gwei)
+	return price, nil
+}
@@ -0,0 +1,135 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package manual
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/route"
+)
+
+// APITokenEnvName is the env variable with the bearer token required by the api endpoints.
+// The endpoints are disabled when it is not set.
+const APITokenEnvName = "MANUAL_API_TOKEN"
+
+type setRequest struct {
+	Oracle     string    `json:"oracle"`
+	ReqID      int64     `json:"id"`
+	Value      float64   `json:"value"`
+	ValidFrom  time.Time `json:"validFrom"`
+	ValidUntil time.Time `json:"validUntil"`
+	Author     string    `json:"author"`
+	Reason     string    `json:"reason"`
+}
+
+type expireRequest struct {
+	Oracle string `json:"oracle"`
+	ReqID  int64  `json:"id"`
+	Author string `json:"author"`
+	Reason string `json:"reason"`
+}
+
+type response struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// Register adds the api endpoints for managing the manual override values.
+func (self *Store) Register(r *route.Router) {
+	r.Get("/manual", self.auth(self.apiList))
+	r.Post("/manual", self.auth(self.apiSet))
+	r.Post("/manual/expire", self.auth(self.apiExpire))
+}
+
+func (self *Store) auth(f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := os.Getenv(APITokenEnvName)
+		if token == "" {
+			self.respondError(w, http.StatusForbidden, errors.Errorf("manual api is disabled, set the %v env variable to enable it", APITokenEnvName))
+			return
+		}
+		reqToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(reqToken), []byte(token)) != 1 {
+			self.respondError(w, http.StatusUnauthorized, errors.New("invalid api token"))
+			return
+		}
+		f(w, r)
+	}
+}
+
+func (self *Store) apiList(w http.ResponseWriter, r *http.Request) {
+	overrides, err := self.List()
+	if err != nil {
+		self.respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	self.respond(w, overrides)
+}
+
+func (self *Store) apiSet(w http.ResponseWriter, r *http.Request) {
+	var req setRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		self.respondError(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return
+	}
+	if req.ValidFrom.IsZero() {
+		req.ValidFrom = time.Now()
+	}
+	entry := Entry{
+		Value:      req.Value,
+		ValidFrom:  req.ValidFrom,
+		ValidUntil: req.ValidUntil,
+		Author:     req.Author,
+		Reason:     req.Reason,
+	}
+	if err := self.Set(req.Oracle, req.ReqID, entry); err != nil {
+		self.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	self.respond(w, entry)
+}
+
+func (self *Store) apiExpire(w http.ResponseWriter, r *http.Request) {
+	var req expireRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		self.respondError(w, http.StatusBadRequest, errors.Wrap(err, "decode request"))
+		return
+	}
+	count, err := self.Expire(req.Oracle, req.ReqID, time.Now(), req.Author, req.Reason)
+	if err != nil {
+		self.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	self.respond(w, map[string]int{"expired": count})
+}
+
+func (self *Store) respond(w http.ResponseWriter, data interface{}) {
+	self.writeResponse(w, http.StatusOK, response{Status: "success", Data: data})
+}
+
+func (self *Store) respondError(w http.ResponseWriter, code int, err error) {
+	self.writeResponse(w, code, response{Status: "error", Error: err.Error()})
+}
+
+func (self *Store) writeResponse(w http.ResponseWriter, code int, resp response) {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		level.Error(self.logger).Log("msg", "marshaling json response", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if _, err := w.Write(b); err != nil {
+		level.Error(self.logger).Log("msg", "writing response", "err", err)
+	}
+}
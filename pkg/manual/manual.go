@@ -0,0 +1,463 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package manual
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/logging"
+)
+
+const ComponentName = "manual"
+
+const (
+	ActionSet    = "set"
+	ActionExpire = "expire"
+)
+
+// Oracles that read the manual override values.
+const (
+	OracleTellor           = "tellor"
+	OracleTellorMesosphere = "tellorMesosphere"
+)
+
+type Config struct {
+	LogLevel  string
+	File      string `help:"File with all manual override values."`
+	AuditFile string `help:"Append only log of all changes to the manual override values."`
+}
+
+// Entry is a single manual override value for a given data ID.
+// It is used only between ValidFrom and ValidUntil.
+type Entry struct {
+	Value      float64   `json:"value"`
+	ValidFrom  time.Time `json:"validFrom"`
+	ValidUntil time.Time `json:"validUntil"`
+	Author     string    `json:"author"`
+	Reason     string    `json:"reason"`
+	Created    time.Time `json:"created"`
+}
+
+// ActiveAt returns true when the entry can be used at the given time.
+func (self Entry) ActiveAt(ts time.Time) bool {
+	return !ts.Before(self.ValidFrom) && ts.Before(self.ValidUntil)
+}
+
+func (self Entry) Validate() error {
+	if self.Value == 0 || math.IsNaN(self.Value) || math.IsInf(self.Value, 0) {
+		return errors.Errorf("invalid value:%v", self.Value)
+	}
+	if self.ValidUntil.IsZero() {
+		return errors.New("missing valid until time")
+	}
+	if self.ValidUntil.Before(self.ValidFrom) {
+		return errors.Errorf("valid until:%v should be after valid from:%v", self.ValidUntil, self.ValidFrom)
+	}
+	if self.Author == "" {
+		return errors.New("missing author")
+	}
+	return nil
+}
+
+// Override is an entry together with the oracle and data ID it overrides.
+type Override struct {
+	Oracle string `json:"oracle"`
+	ReqID  int64  `json:"id"`
+	Entry
+}
+
+// AuditRecord is a single line in the audit log.
+type AuditRecord struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Oracle string    `json:"oracle"`
+	ReqID  int64     `json:"id"`
+	Author string    `json:"author"`
+	Reason string    `json:"reason"`
+	Entry  *Entry    `json:"entry,omitempty"`
+}
+
+// entries holds all entries per oracle and data ID.
+type entries map[string]map[int64][]Entry
+
+// legacyEntry is the format used before entries had validity windows.
+// Such entries are valid until DATE.
+type legacyEntry struct {
+	Value float64 `json:"VALUE"`
+	Date  int64   `json:"DATE"`
+}
+
+// Store keeps the manual override values in memory and
+// reloads them only when the file on disk changes.
+type Store struct {
+	logger  log.Logger
+	cfg     Config
+	mtx     sync.Mutex
+	entries entries
+	file    os.FileInfo
+	loadErr error
+}
+
+func New(logger log.Logger, cfg Config) (*Store, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	store := &Store{
+		logger:  log.With(logger, "component", ComponentName),
+		cfg:     cfg,
+		entries: make(entries),
+	}
+	if err := store.reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Value returns the manual override value for the given data ID at the given time.
+// When more than one entry is active the most recently created one is used.
+// Returns false when there is no active entry.
+func (self *Store) Value(oracle string, reqID int64, ts time.Time) (*Entry, bool, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	if err := self.reload(); err != nil {
+		return nil, false, err
+	}
+
+	var active *Entry
+	for i, entry := range self.entries[oracle][reqID] {
+		if !entry.ActiveAt(ts) {
+			continue
+		}
+		if active == nil || entry.Created.After(active.Created) {
+			active = &self.entries[oracle][reqID][i]
+		}
+	}
+	if active == nil {
+		return nil, false, nil
+	}
+	e := *active
+	return &e, true, nil
+}
+
+// List returns all overrides which haven't expired yet sorted by oracle, ID and start time.
+func (self *Store) List() ([]Override, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	if err := self.reload(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var overrides []Override
+	for oracle, ids := range self.entries {
+		for id, entries := range ids {
+			for _, entry := range entries {
+				if !entry.ValidUntil.After(now) {
+					continue
+				}
+				overrides = append(overrides, Override{Oracle: oracle, ReqID: id, Entry: entry})
+			}
+		}
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].Oracle != overrides[j].Oracle {
+			return overrides[i].Oracle < overrides[j].Oracle
+		}
+		if overrides[i].ReqID != overrides[j].ReqID {
+			return overrides[i].ReqID < overrides[j].ReqID
+		}
+		return overrides[i].ValidFrom.Before(overrides[j].ValidFrom)
+	})
+	return overrides, nil
+}
+
+// Set adds a new override entry and records the change in the audit log.
+func (self *Store) Set(oracle string, reqID int64, entry Entry) error {
+	if err := validateID(oracle, reqID); err != nil {
+		return err
+	}
+	if entry.Created.IsZero() {
+		entry.Created = time.Now()
+	}
+	if err := entry.Validate(); err != nil {
+		return errors.Wrap(err, "validate entry")
+	}
+	if !entry.ValidUntil.After(entry.ValidFrom) {
+		return errors.New("entry is never active")
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	if err := self.reload(); err != nil {
+		return err
+	}
+	if _, ok := self.entries[oracle]; !ok {
+		self.entries[oracle] = make(map[int64][]Entry)
+	}
+	self.entries[oracle][reqID] = append(self.entries[oracle][reqID], entry)
+
+	if err := self.write(); err != nil {
+		return err
+	}
+
+	level.Info(self.logger).Log("msg", "manual value set", "oracle", oracle, "reqID", reqID, "val", entry.Value, "validUntil", entry.ValidUntil, "author", entry.Author)
+
+	return self.audit(AuditRecord{
+		Time:   entry.Created,
+		Action: ActionSet,
+		Oracle: oracle,
+		ReqID:  reqID,
+		Author: entry.Author,
+		Reason: entry.Reason,
+		Entry:  &entry,
+	})
+}
+
+// Expire ends all entries for the data ID that are still valid at the given time
+// and returns how many entries were changed.
+func (self *Store) Expire(oracle string, reqID int64, at time.Time, author, reason string) (int, error) {
+	if err := validateID(oracle, reqID); err != nil {
+		return 0, err
+	}
+	if author == "" {
+		return 0, errors.New("missing author")
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	if err := self.reload(); err != nil {
+		return 0, err
+	}
+
+	var expired int
+	for i, entry := range self.entries[oracle][reqID] {
+		if !entry.ValidUntil.After(at) {
+			continue
+		}
+		if entry.ValidFrom.After(at) {
+			self.entries[oracle][reqID][i].ValidFrom = at
+		}
+		self.entries[oracle][reqID][i].ValidUntil = at
+		expired++
+	}
+	if expired == 0 {
+		return 0, nil
+	}
+
+	if err := self.write(); err != nil {
+		return 0, err
+	}
+
+	level.Info(self.logger).Log("msg", "manual value expired", "oracle", oracle, "reqID", reqID, "count", expired, "author", author)
+
+	return expired, self.audit(AuditRecord{
+		Time:   at,
+		Action: ActionExpire,
+		Oracle: oracle,
+		ReqID:  reqID,
+		Author: author,
+		Reason: reason,
+	})
+}
+
+// validateID rejects oracles and IDs which are never read
+// so that a typo doesn't silently create an unused override.
+func validateID(oracle string, reqID int64) error {
+	switch oracle {
+	case OracleTellor, OracleTellorMesosphere:
+	default:
+		return errors.Errorf("unknown oracle:%q", oracle)
+	}
+	if reqID <= 0 {
+		return errors.Errorf("invalid ID:%v", reqID)
+	}
+	return nil
+}
+
+// reload parses the file only when it has changed since the last load.
+// A broken file is reported on every call until it is fixed.
+func (self *Store) reload() error {
+	info, err := os.Stat(self.cfg.File)
+	if os.IsNotExist(err) {
+		self.entries, self.file, self.loadErr = make(entries), nil, nil
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "stat manual data file")
+	}
+	// The file is always replaced on write so comparing the file identity
+	// catches changes even when the modification time has a coarse resolution.
+	if self.file != nil && os.SameFile(self.file, info) && info.ModTime().Equal(self.file.ModTime()) && info.Size() == self.file.Size() {
+		return self.loadErr
+	}
+	self.file = info
+
+	data, err := ioutil.ReadFile(self.cfg.File)
+	if err != nil {
+		self.loadErr = errors.Wrap(err, "read manual data file")
+		return self.loadErr
+	}
+	entries, err := parse(data)
+	if err != nil {
+		self.loadErr = errors.Wrapf(err, "parse manual data file:%v", self.cfg.File)
+		level.Error(self.logger).Log("msg", "loading manual data file", "err", self.loadErr)
+		return self.loadErr
+	}
+	self.entries, self.loadErr = entries, nil
+	level.Debug(self.logger).Log("msg", "loaded manual data file", "file", self.cfg.File)
+	return nil
+}
+
+// write replaces the file on disk and drops all entries that have already expired.
+// The audit log keeps the full history.
+func (self *Store) write() error {
+	now := time.Now()
+	out := make(map[string]map[string][]Entry)
+	for oracle, ids := range self.entries {
+		out[oracle] = make(map[string][]Entry)
+		for id, entries := range ids {
+			var valid []Entry
+			for _, entry := range entries {
+				if entry.ValidUntil.After(now) {
+					valid = append(valid, entry)
+				}
+			}
+			if len(valid) > 0 {
+				out[oracle][strconv.FormatInt(id, 10)] = valid
+			}
+		}
+	}
+	data, err := json.MarshalIndent(out, "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "marshal manual data")
+	}
+
+	// Write to a temp file and rename it so that
+	// readers never see a partially written file.
+	tmp, err := ioutil.TempFile(filepath.Dir(self.cfg.File), filepath.Base(self.cfg.File))
+	if err != nil {
+		return errors.Wrap(err, "create temp manual data file")
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "write temp manual data file")
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "close temp manual data file")
+	}
+	if err := os.Rename(tmp.Name(), self.cfg.File); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "replace manual data file")
+	}
+
+	return self.reload()
+}
+
+func (self *Store) audit(record AuditRecord) (err error) {
+	f, err := os.OpenFile(self.cfg.AuditFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "open audit log")
+	}
+	defer func() {
+		if errC := f.Close(); errC != nil && err == nil {
+			err = errors.Wrap(errC, "close audit log")
+		}
+	}()
+	data, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "marshal audit record")
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return errors.Wrap(err, "write audit record")
+	}
+	return nil
+}
+
+// Audit returns all records from the audit log.
+func (self *Store) Audit() ([]AuditRecord, error) {
+	data, err := ioutil.ReadFile(self.cfg.AuditFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "read audit log")
+	}
+	var records []AuditRecord
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var record AuditRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, errors.Wrapf(err, "parse audit log line:%v", i+1)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// parse returns all entries from the file content.
+// It also accepts the legacy format where each ID has a single VALUE and DATE.
+func parse(data []byte) (entries, error) {
+	var raw map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrap(err, "unmarshal")
+	}
+
+	result := make(entries)
+	for oracle, ids := range raw {
+		result[oracle] = make(map[int64][]Entry)
+		for _id, rawEntries := range ids {
+			id, err := strconv.ParseInt(_id, 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "oracle:%v invalid ID:%v", oracle, _id)
+			}
+
+			var _entries []Entry
+			if trimmed := bytes.TrimSpace(rawEntries); len(trimmed) > 0 && trimmed[0] == '{' {
+				var legacy legacyEntry
+				if err := json.Unmarshal(rawEntries, &legacy); err != nil {
+					return nil, errors.Wrapf(err, "oracle:%v ID:%v", oracle, id)
+				}
+				if legacy.Value == 0 {
+					continue
+				}
+				_entries = append(_entries, Entry{
+					Value:      legacy.Value,
+					ValidUntil: time.Unix(legacy.Date, 0),
+					Author:     "legacy",
+				})
+			} else if err := json.Unmarshal(rawEntries, &_entries); err != nil {
+				return nil, errors.Wrapf(err, "oracle:%v ID:%v", oracle, id)
+			}
+
+			for i, entry := range _entries {
+				if err := entry.Validate(); err != nil {
+					return nil, errors.Wrapf(err, "oracle:%v ID:%v entry:%v", oracle, id, i)
+				}
+			}
+			result[oracle][id] = _entries
+		}
+	}
+	return result, nil
+}
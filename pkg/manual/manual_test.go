@@ -0,0 +1,138 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package manual
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/common/route"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func newTestStore(t *testing.T, content string) *Store {
+	dir := t.TempDir()
+	cfg := Config{
+		LogLevel:  "info",
+		File:      filepath.Join(dir, "manualData.json"),
+		AuditFile: filepath.Join(dir, "manualDataAudit.log"),
+	}
+	if content != "" {
+		testutil.Ok(t, ioutil.WriteFile(cfg.File, []byte(content), 0644))
+	}
+	store, err := New(log.NewNopLogger(), cfg)
+	testutil.Ok(t, err)
+	return store
+}
+
+func TestLegacyFormat(t *testing.T) {
+	store := newTestStore(t, `{"tellor":{"41":{"VALUE":114.048,"DATE":1627664400},"42":{"VALUE":0,"DATE":1627664400}}}`)
+
+	entry, ok, err := store.Value("tellor", 41, time.Unix(1627664399, 0))
+	testutil.Ok(t, err)
+	testutil.Assert(t, ok, "legacy entry should be active before its date")
+	testutil.Equals(t, 114.048, entry.Value)
+
+	_, ok, err = store.Value("tellor", 41, time.Unix(1627664400, 0))
+	testutil.Ok(t, err)
+	testutil.Assert(t, !ok, "legacy entry should expire at its date")
+
+	_, ok, err = store.Value("tellor", 42, time.Unix(0, 0))
+	testutil.Ok(t, err)
+	testutil.Assert(t, !ok, "legacy entry with zero value should be ignored")
+}
+
+func TestMalformedFile(t *testing.T) {
+	store := newTestStore(t, "")
+	testutil.Ok(t, ioutil.WriteFile(store.cfg.File, []byte(`{"tellor":{"1":[{"value":1}]}}`), 0644))
+
+	_, _, err := store.Value("tellor", 1, time.Now())
+	testutil.NotOk(t, err)
+}
+
+func TestSetExpire(t *testing.T) {
+	store := newTestStore(t, "")
+	now := time.Now()
+
+	testutil.NotOk(t, store.Set("tellor", 1, Entry{Value: 1, ValidFrom: now, Author: "test"}), "entries without expiry should be rejected")
+
+	testutil.Ok(t, store.Set("tellor", 1, Entry{Value: 1, ValidFrom: now, ValidUntil: now.Add(time.Hour), Author: "test", Created: now}))
+	testutil.Ok(t, store.Set("tellor", 1, Entry{Value: 2, ValidFrom: now, ValidUntil: now.Add(time.Hour), Author: "test", Created: now.Add(time.Second)}))
+	testutil.Ok(t, store.Set("tellor", 1, Entry{Value: 3, ValidFrom: now.Add(2 * time.Hour), ValidUntil: now.Add(3 * time.Hour), Author: "test"}))
+
+	// The most recently created entry wins.
+	entry, ok, err := store.Value("tellor", 1, now.Add(time.Minute))
+	testutil.Ok(t, err)
+	testutil.Assert(t, ok, "expected an active entry")
+	testutil.Equals(t, float64(2), entry.Value)
+
+	entry, ok, err = store.Value("tellor", 1, now.Add(2*time.Hour+time.Minute))
+	testutil.Ok(t, err)
+	testutil.Assert(t, ok, "expected an active entry")
+	testutil.Equals(t, float64(3), entry.Value)
+
+	// A new store instance reads the same values from disk.
+	other, err := New(log.NewNopLogger(), store.cfg)
+	testutil.Ok(t, err)
+	overrides, err := other.List()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(overrides))
+
+	count, err := store.Expire("tellor", 1, now.Add(time.Minute), "test", "")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, count)
+
+	_, ok, err = other.Value("tellor", 1, now.Add(2*time.Hour+time.Minute))
+	testutil.Ok(t, err)
+	testutil.Assert(t, !ok, "expired entries shouldn't be active")
+
+	records, err := store.Audit()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 4, len(records))
+	testutil.Equals(t, ActionExpire, records[3].Action)
+}
+
+func TestListSkipsExpired(t *testing.T) {
+	store := newTestStore(t, `{"tellor":{"1":[
+		{"value":1,"validUntil":"2000-01-01T00:00:00Z","author":"test"},
+		{"value":2,"validUntil":"2100-01-01T00:00:00Z","author":"test"}
+	]}}`)
+	overrides, err := store.List()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(overrides))
+	testutil.Equals(t, float64(2), overrides[0].Value)
+}
+
+func TestAPISetValidation(t *testing.T) {
+	store := newTestStore(t, "")
+	defer os.Setenv(APITokenEnvName, os.Getenv(APITokenEnvName))
+	testutil.Ok(t, os.Setenv(APITokenEnvName, "secret"))
+	router := route.New()
+	store.Register(router)
+
+	validUntil := time.Now().Add(time.Hour).Format(time.RFC3339)
+	for body, code := range map[string]int{
+		`{"oracle":"tellor","id":1,"value":1,"author":"test","validUntil":"` + validUntil + `"}`:  http.StatusOK,
+		`{"oracle":"teller","id":1,"value":1,"author":"test","validUntil":"` + validUntil + `"}`:  http.StatusBadRequest,
+		`{"oracle":"tellor","id":0,"value":1,"author":"test","validUntil":"` + validUntil + `"}`:  http.StatusBadRequest,
+		`{"oracle":"tellor","id":-1,"value":1,"author":"test","validUntil":"` + validUntil + `"}`: http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/manual", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		testutil.Equals(t, code, rec.Code, body)
+	}
+
+	overrides, err := store.List()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(overrides))
+}
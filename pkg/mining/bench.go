@@ -0,0 +1,134 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Hasher implementations that can be benchmarked.
+const (
+	HasherCpu     = "cpu"
+	HasherFastCpu = "fastCpu"
+)
+
+// NewHasher returns the hasher implementation with the given name.
+func NewHasher(name string, id int64) (Hasher, error) {
+	switch name {
+	case HasherCpu:
+		return NewCpuMiner(id), nil
+	case HasherFastCpu:
+		return NewFastCpuMiner(id), nil
+	default:
+		return nil, errors.Errorf("unknown hasher:%v", name)
+	}
+}
+
+// BenchResult is the result of running a hasher on all threads for the benchmark duration.
+type BenchResult struct {
+	Hasher    string
+	Threads   int
+	Duration  time.Duration
+	Hashes    uint64
+	HashRate  float64
+	Solutions int
+	// ChunkSize is the average number of hashes per chunk dispatched to each thread
+	// at the final hash rate estimate.
+	ChunkSize uint64
+}
+
+// Bench runs the hasher on the given number of threads for the duration
+// with a random synthetic challenge at the difficulty.
+// The threads check chunks sized the same way as the mining group
+// so the chunk size converges like in a live run.
+func Bench(ctx context.Context, hasher string, threads int, difficulty *big.Int, duration time.Duration) (*BenchResult, error) {
+	if threads <= 0 {
+		return nil, errors.Errorf("invalid threads:%v", threads)
+	}
+	if difficulty.Sign() <= 0 {
+		return nil, errors.Errorf("invalid difficulty:%v", difficulty)
+	}
+	challenge := make([]byte, 32)
+	addr := make([]byte, 20)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, errors.Wrap(err, "generating challenge")
+	}
+	if _, err := rand.Read(addr); err != nil {
+		return nil, errors.Wrap(err, "generating address")
+	}
+	hash := NewHashSettings(&MiningChallenge{Challenge: challenge, Difficulty: difficulty}, fmt.Sprintf("0x%x", addr))
+
+	var backends []*Backend
+	for i := 0; i < threads; i++ {
+		h, err := NewHasher(hasher, int64(i))
+		if err != nil {
+			return nil, err
+		}
+		backends = append(backends, &Backend{Hasher: h, HashRateEstimate: rateInitialGuess})
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, duration)
+	defer cncl()
+	var (
+		wg        sync.WaitGroup
+		mtx       sync.Mutex
+		solutions int
+		errB      error
+	)
+	started := time.Now()
+	// Each thread checks its own part of the nonce space.
+	for i, b := range backends {
+		wg.Add(1)
+		go func(b *Backend, start uint64) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				n := b.chunkSize()
+				chunkStarted := time.Now()
+				// The benchmark context isn't passed to the hasher
+				// as a cancellation is reported as a solution.
+				nonce, checked, err := b.CheckRange(context.Background(), hash, start, n)
+				if err != nil {
+					mtx.Lock()
+					errB = err
+					mtx.Unlock()
+					return
+				}
+				start += checked
+				b.TotalHashes += checked
+				if nonce != "" {
+					mtx.Lock()
+					solutions++
+					mtx.Unlock()
+					continue
+				}
+				b.updateEstimate(checked, time.Since(chunkStarted))
+			}
+		}(b, uint64(i)<<48)
+	}
+	wg.Wait()
+	if errB != nil {
+		return nil, errors.Wrap(errB, "running the hasher")
+	}
+
+	result := &BenchResult{
+		Hasher:    hasher,
+		Threads:   threads,
+		Duration:  time.Since(started),
+		Solutions: solutions,
+	}
+	for _, b := range backends {
+		result.Hashes += b.TotalHashes
+		result.ChunkSize += b.chunkSize()
+	}
+	result.ChunkSize /= uint64(len(backends))
+	result.HashRate = float64(result.Hashes) / result.Duration.Seconds()
+	return result, nil
+}
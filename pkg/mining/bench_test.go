@@ -0,0 +1,27 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestBench(t *testing.T) {
+	for _, hasher := range []string{HasherCpu, HasherFastCpu} {
+		result, err := Bench(context.Background(), hasher, 2, big.NewInt(1000), 200*time.Millisecond)
+		testutil.Ok(t, err)
+		testutil.Equals(t, 2, result.Threads)
+		testutil.Assert(t, result.Hashes > 0, "no hashes checked")
+		testutil.Assert(t, result.Solutions > 0, "no solutions at a low difficulty")
+		testutil.Assert(t, result.ChunkSize > 0, "no chunk size")
+	}
+
+	_, err := Bench(context.Background(), "unknown", 1, big.NewInt(1000), time.Millisecond)
+	testutil.NotOk(t, err)
+}
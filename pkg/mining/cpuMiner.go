@@ -28,7 +28,7 @@ func (c *CpuMiner) StepSize() uint64 {
 }
 
 func (c *CpuMiner) Name() string {
-	return fmt.Sprintf("CPU %d", c)
+	return fmt.Sprintf("CPU %d", *c)
 }
 
 func (c *CpuMiner) CheckRange(anySolution context.Context, hash *HashSettings, start uint64, n uint64) (string, uint64, error) {
@@ -0,0 +1,150 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math/big"
+	"math/bits"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	// nolint:staticcheck
+	"golang.org/x/crypto/ripemd160"
+)
+
+// maxNonceDigits is the length of the largest uint64 in decimal.
+const maxNonceDigits = 20
+
+// FastCpuMiner checks the same nonces as the CpuMiner without allocating for every nonce.
+// It reuses the hash states, increments the decimal nonce in place
+// and checks difficulties that fit in 64 bits without big ints.
+type FastCpuMiner int64
+
+func NewFastCpuMiner(id int64) *FastCpuMiner {
+	x := FastCpuMiner(id)
+	return &x
+}
+
+func (c *FastCpuMiner) StepSize() uint64 {
+	return 1
+}
+
+func (c *FastCpuMiner) Name() string {
+	return fmt.Sprintf("CPU %d", *c)
+}
+
+func (c *FastCpuMiner) CheckRange(anySolution context.Context, hash *HashSettings, start uint64, n uint64) (string, uint64, error) {
+	if hash.difficulty.Sign() <= 0 {
+		return "", 0, errors.Errorf("invalid difficulty:%v", hash.difficulty)
+	}
+	baseLen := len(hash.prefix)
+	hashInput := make([]byte, baseLen, baseLen+maxNonceDigits)
+	copy(hashInput, hash.prefix)
+	hashInput = strconv.AppendUint(hashInput, start, 10)
+
+	state := newHashState()
+	done := anySolution.Done()
+	divisor := newDivisor(hash.difficulty)
+	for i := uint64(0); i < n; i++ {
+		select {
+		case <-done:
+			return "any", n, nil
+		default:
+		}
+		if i > 0 {
+			hashInput = incrementDecimal(hashInput, baseLen)
+		}
+		numHash, err := state.sum(hashInput)
+		if err != nil {
+			return "", 0, err
+		}
+		if divisor.divides(&numHash) {
+			return string(hashInput[baseLen:]), i + 1, nil
+		}
+	}
+	return "", n, nil
+}
+
+// hashState holds the reusable states of the hash functions used by hashFn.
+type hashState struct {
+	keccak    crypto.KeccakState
+	ripemd    hash.Hash
+	keccakOut [32]byte
+	ripemdOut [ripemd160.Size]byte
+}
+
+func newHashState() *hashState {
+	return &hashState{
+		keccak: crypto.NewKeccakState(),
+		ripemd: ripemd160.New(),
+	}
+}
+
+// sum returns the same hash as hashFn as big endian bytes.
+func (self *hashState) sum(input []byte) ([32]byte, error) {
+	self.keccak.Reset()
+	if _, err := self.keccak.Write(input); err != nil {
+		return [32]byte{}, err
+	}
+	if _, err := self.keccak.Read(self.keccakOut[:]); err != nil {
+		return [32]byte{}, err
+	}
+	self.ripemd.Reset()
+	if _, err := self.ripemd.Write(self.keccakOut[:]); err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(self.ripemd.Sum(self.ripemdOut[:0])), nil
+}
+
+// incrementDecimal adds one to the decimal number after the prefix.
+// The slice only grows when all digits are 9.
+func incrementDecimal(input []byte, prefixLen int) []byte {
+	for i := len(input) - 1; i >= prefixLen; i-- {
+		if input[i] != '9' {
+			input[i]++
+			return input
+		}
+		input[i] = '0'
+	}
+	input[prefixLen] = '1'
+	return append(input, '0')
+}
+
+// divisor checks if hashes are divisible by the difficulty.
+// Difficulties that fit in 64 bits are checked with the remainder of each 64 bit word
+// and bigger ones with a reused big int.
+type divisor struct {
+	difficulty *big.Int
+	d64        uint64
+	x          *big.Int
+}
+
+func newDivisor(difficulty *big.Int) *divisor {
+	d := &divisor{difficulty: difficulty}
+	if difficulty.IsUint64() {
+		d.d64 = difficulty.Uint64()
+	} else {
+		d.x = new(big.Int)
+	}
+	return d
+}
+
+func (self *divisor) divides(h *[32]byte) bool {
+	if self.x != nil {
+		self.x.SetBytes(h[:])
+		return self.x.Mod(self.x, self.difficulty).Sign() == 0
+	}
+	var rem uint64
+	for i := 0; i < len(h); i += 8 {
+		rem = bits.Rem64(rem, binary.BigEndian.Uint64(h[i:]), self.d64)
+	}
+	return rem == 0
+}
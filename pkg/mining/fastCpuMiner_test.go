@@ -0,0 +1,126 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func randomHashSettings(r *rand.Rand, difficulty *big.Int) *HashSettings {
+	challenge := make([]byte, 32)
+	r.Read(challenge)
+	addr := make([]byte, 20)
+	r.Read(addr)
+	return NewHashSettings(&MiningChallenge{Challenge: challenge, Difficulty: difficulty}, fmt.Sprintf("0x%x", addr))
+}
+
+func TestFastCpuMinerEquivalence(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	state := newHashState()
+	for i := 0; i < 100; i++ {
+		hash := randomHashSettings(r, big.NewInt(1))
+		// Include starts that add a digit while incrementing.
+		starts := []uint64{0, 9, 99999, r.Uint64() >> 4, r.Uint64()}
+		for _, start := range starts {
+			input := append(append([]byte{}, hash.prefix...), strconv.FormatUint(start, 10)...)
+			for j := uint64(0); j < 20; j++ {
+				if j > 0 {
+					input = incrementDecimal(input, len(hash.prefix))
+				}
+				testutil.Equals(t, strconv.FormatUint(start+j, 10), string(input[len(hash.prefix):]))
+
+				exp, err := hashFn(input)
+				testutil.Ok(t, err)
+				act, err := state.sum(input)
+				testutil.Ok(t, err)
+				testutil.Equals(t, exp, new(big.Int).SetBytes(act[:]))
+			}
+		}
+	}
+}
+
+func TestFastCpuMinerCheckRange(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	big2e70 := new(big.Int).Lsh(big.NewInt(1), 70)
+	difficulties := []*big.Int{
+		big.NewInt(1),
+		big.NewInt(7),
+		big.NewInt(1000),
+		new(big.Int).SetUint64(^uint64(0)),
+		// Bigger than 64 bits.
+		new(big.Int).Add(big2e70, big.NewInt(3)),
+	}
+	for _, difficulty := range difficulties {
+		for i := 0; i < 10; i++ {
+			hash := randomHashSettings(r, difficulty)
+			start := r.Uint64() >> 1
+
+			expNonce, expN, err := NewCpuMiner(0).CheckRange(context.Background(), hash, start, 5000)
+			testutil.Ok(t, err)
+			actNonce, actN, err := NewFastCpuMiner(0).CheckRange(context.Background(), hash, start, 5000)
+			testutil.Ok(t, err)
+			testutil.Equals(t, expNonce, actNonce, "difficulty:%v start:%v", difficulty, start)
+			testutil.Equals(t, expN, actN, "difficulty:%v start:%v", difficulty, start)
+		}
+	}
+
+	_, _, err := NewFastCpuMiner(0).CheckRange(context.Background(), randomHashSettings(r, big.NewInt(0)), 0, 1)
+	testutil.NotOk(t, err)
+}
+
+func benchmarkHasher(b *testing.B, hasher Hasher, difficulty *big.Int) {
+	hash := randomHashSettings(rand.New(rand.NewSource(3)), difficulty)
+	b.ReportAllocs()
+	b.ResetTimer()
+	// Each iteration checks a single nonce so ns/op is the time per hash.
+	if _, _, err := hasher.CheckRange(context.Background(), hash, 0, uint64(b.N)); err != nil {
+		b.Fatal(err)
+	}
+}
+
+// Difficulties that are never found so the whole range is checked.
+var (
+	benchDifficulty64  = new(big.Int).SetUint64(^uint64(0))
+	benchDifficultyBig = new(big.Int).Lsh(big.NewInt(1), 255)
+)
+
+func BenchmarkCpuMiner(b *testing.B) {
+	benchmarkHasher(b, NewCpuMiner(0), benchDifficulty64)
+}
+
+func BenchmarkFastCpuMiner(b *testing.B) {
+	benchmarkHasher(b, NewFastCpuMiner(0), benchDifficulty64)
+}
+
+func BenchmarkFastCpuMinerBigDifficulty(b *testing.B) {
+	benchmarkHasher(b, NewFastCpuMiner(0), benchDifficultyBig)
+}
+
+func BenchmarkHashFn(b *testing.B) {
+	input := []byte("0123456789012345678901234567890123456789012345678901123456")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := hashFn(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHashState(b *testing.B) {
+	input := []byte("0123456789012345678901234567890123456789012345678901123456")
+	state := newHashState()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := state.sum(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/logging"
@@ -20,6 +22,25 @@ import (
 
 const ComponentName = "miner"
 
+var (
+	hashesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "hashes_total",
+		Help:      "The total number of hashes checked by each mining backend",
+	},
+		[]string{"backend"},
+	)
+	hashRate = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "hash_rate",
+		Help:      "The estimated hash rate of each mining backend in hashes per second",
+	},
+		[]string{"backend"},
+	)
+)
+
 type HashSettings struct {
 	prefix     []byte
 	difficulty *big.Int
@@ -191,15 +212,32 @@ type Result struct {
 	Nonce string
 }
 
-// dispatches a chunk and returns the number of hashes chosen.
-func (b *Backend) dispatchWork(parentCtx context.Context, timeOfLastNewValue *big.Int, hash *HashSettings, start uint64, resultCh chan *backendResult) uint64 {
+// chunkSize returns the number of hashes that take about the target chunk time at the estimated hash rate.
+func (b *Backend) chunkSize() uint64 {
 	target := b.HashRateEstimate * targetChunkTime.Seconds()
 	step := b.StepSize()
 	nsteps := uint64(math.Round(target / float64(step)))
 	if nsteps == 0 {
 		nsteps = 1
 	}
-	n := nsteps * step
+	return nsteps * step
+}
+
+// updateEstimate updates the hash rate estimate with the rate of a checked chunk.
+func (b *Backend) updateEstimate(n uint64, elapsed time.Duration) {
+	newEst := float64(n) / elapsed.Seconds()
+	if b.HashRateEstimate == rateInitialGuess {
+		b.HashRateEstimate = newEst
+	} else {
+		memory := 0.2
+		b.HashRateEstimate *= 1 - memory
+		b.HashRateEstimate += memory * newEst
+	}
+}
+
+// dispatches a chunk and returns the number of hashes chosen.
+func (b *Backend) dispatchWork(parentCtx context.Context, timeOfLastNewValue *big.Int, hash *HashSettings, start uint64, resultCh chan *backendResult) uint64 {
+	n := b.chunkSize()
 	tm := time.Unix(timeOfLastNewValue.Int64(), 0)
 	anySolution, close := context.WithDeadline(parentCtx, tm.Add(15*time.Minute))
 	go b.doWork(anySolution, close, hash, start, n, resultCh)
@@ -277,18 +315,13 @@ func (g *MiningGroup) Mine(ctx context.Context, input chan *Work, output chan *R
 			// Update the backend statistics no matter what.
 			result.backend.TotalHashes += result.n
 			result.backend.HashSincePrint += result.n
+			hashesTotal.With(prometheus.Labels{"backend": result.backend.Name()}).Add(float64(result.n))
 
 			// Only update the hashRateEstimate if we didn't find a solution - otherwise the rate could be wrong
 			// due to returning early.
 			if result.nonce == "" {
-				newEst := float64(result.n) / (result.finished.Sub(result.started).Seconds())
-				if result.backend.HashRateEstimate == rateInitialGuess {
-					result.backend.HashRateEstimate = newEst
-				} else {
-					memory := 0.2
-					result.backend.HashRateEstimate *= 1 - memory
-					result.backend.HashRateEstimate += memory * newEst
-				}
+				result.backend.updateEstimate(result.n, result.finished.Sub(result.started))
+				hashRate.With(prometheus.Labels{"backend": result.backend.Name()}).Set(result.backend.HashRateEstimate)
 			}
 
 			// Ignore out of date results.
@@ -6,34 +6,49 @@ package mining
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/logging"
 )
 
 type Config struct {
 	LogLevel  string
 	Heartbeat time.Duration
+	Threads   int `help:"Number of CPU mining threads, 0 uses all CPUs."`
 }
 
 type SolutionSink interface {
 	Submit(context.Context, *Result) (*types.Transaction, error)
 }
 
-const NumProcessors = 1
+// NewHashers returns a CPU hasher for each thread, 0 threads uses all CPUs.
+func NewHashers(threads int) ([]Hasher, error) {
+	if threads < 0 {
+		return nil, errors.Errorf("invalid mining threads:%v", threads)
+	}
+	if threads == 0 {
+		threads = runtime.NumCPU()
+	}
+	var hashers []Hasher
+	for i := 0; i < threads; i++ {
+		hashers = append(hashers, NewFastCpuMiner(int64(i)))
+	}
+	return hashers, nil
+}
 
 func SetupMiningGroup(logger log.Logger, ctx context.Context, cfg Config, contractInstance *contracts.ITellor) (*MiningGroup, error) {
-	var hashers []Hasher
-	level.Info(logger).Log("msg", "starting CPU mining", "threads", NumProcessors)
-	for i := 0; i < NumProcessors; i++ {
-		hashers = append(hashers, NewCpuMiner(int64(i)))
+	hashers, err := NewHashers(cfg.Threads)
+	if err != nil {
+		return nil, err
 	}
+	level.Info(logger).Log("msg", "starting CPU mining", "threads", len(hashers))
 	miningGrp, err := NewMiningGroup(logger, ctx, cfg, hashers, contractInstance)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating new mining group")
@@ -51,7 +66,7 @@ type MiningMgr struct {
 	ctx              context.Context
 	close            context.CancelFunc
 	logger           log.Logger
-	ethClient        *ethclient.Client
+	ethClient        *ethereum.Client
 	group            *MiningGroup
 	taskerCh         chan *Work
 	submitterCh      chan *Result
@@ -68,7 +83,7 @@ func NewMiningManager(
 	contractInstance *contracts.ITellor,
 	taskerCh chan *Work,
 	submitterCh chan *Result,
-	client *ethclient.Client,
+	client *ethereum.Client,
 ) (*MiningMgr, error) {
 
 	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
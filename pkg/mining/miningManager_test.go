@@ -0,0 +1,27 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestSetupMiningGroup(t *testing.T) {
+	group, err := SetupMiningGroup(log.NewNopLogger(), context.Background(), Config{LogLevel: "info"}, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, runtime.NumCPU(), len(group.Backends))
+
+	group, err = SetupMiningGroup(log.NewNopLogger(), context.Background(), Config{LogLevel: "info", Threads: 3}, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(group.Backends))
+	testutil.Equals(t, "CPU 2", group.Backends[2].Name())
+
+	_, err = SetupMiningGroup(log.NewNopLogger(), context.Background(), Config{LogLevel: "info", Threads: -1}, nil)
+	testutil.NotOk(t, err)
+}
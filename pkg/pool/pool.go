@@ -0,0 +1,387 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package pool
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/mining"
+)
+
+const ComponentName = "pool"
+
+// TokenEnvName is the env variable with the shared secret of the pool and its workers.
+const TokenEnvName = "POOL_TOKEN"
+
+// After 15 minutes since the last new value the contract accepts any nonce.
+const anySolutionAfter = 15 * time.Minute
+
+var (
+	workersGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "workers",
+		Help:      "The number of workers seen within the worker timeout",
+	})
+	hashesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "hashes_total",
+		Help:      "The total number of hashes reported by each worker",
+	},
+		[]string{"worker"},
+	)
+	hashRate = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "hash_rate",
+		Help:      "The hash rate reported by each worker in hashes per second",
+	},
+		[]string{"worker"},
+	)
+	resultsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "results_total",
+		Help:      "The total number of nonce ranges reported by the workers",
+	},
+		[]string{"result"},
+	)
+)
+
+type Config struct {
+	LogLevel      string
+	ListenHost    string
+	ListenPort    uint
+	JobDuration   format.Duration `help:"How long a worker should take to check a nonce range at its reported hash rate."`
+	WorkerTimeout format.Duration `help:"Workers that haven't asked for work for this long aren't counted as active."`
+}
+
+// Contract is the part of the tellor contract used by the pool.
+type Contract interface {
+	GetUintVar(opts *bind.CallOpts, _data [32]byte) (*big.Int, error)
+}
+
+// Job is a nonce range of the current work of an account.
+type Job struct {
+	// ID changes with every new work so results of old work are detected as stale.
+	ID         uint64         `json:"id"`
+	Challenge  hexutil.Bytes  `json:"challenge"`
+	Address    common.Address `json:"address"`
+	Difficulty *hexutil.Big   `json:"difficulty"`
+	Start      hexutil.Uint64 `json:"start"`
+	N          hexutil.Uint64 `json:"n"`
+	// Deadline is the unix time after which any nonce is a valid solution, 0 means no deadline.
+	Deadline int64 `json:"deadline"`
+}
+
+// WorkRequest is sent by the workers to get a new job.
+type WorkRequest struct {
+	Worker   string  `json:"worker"`
+	HashRate float64 `json:"hashRate"`
+}
+
+// Report is sent by the workers after checking the nonce range of a job.
+type Report struct {
+	Worker string         `json:"worker"`
+	ID     uint64         `json:"id"`
+	N      hexutil.Uint64 `json:"n"`
+	Hashes hexutil.Uint64 `json:"hashes"`
+	// Nonce is empty when no solution was found in the range.
+	Nonce string `json:"nonce"`
+}
+
+type account struct {
+	addr        common.Address
+	submitterCh chan *mining.Result
+	work        *mining.Work
+	jobID       uint64
+	deadline    int64
+	sent        uint64
+	recv        uint64
+}
+
+// Pool hands out nonce ranges of the work of each account to remote workers
+// and forwards their solutions to the submitter of the account.
+type Pool struct {
+	logger   log.Logger
+	cfg      Config
+	ctx      context.Context
+	stop     context.CancelFunc
+	srv      *http.Server
+	contract Contract
+
+	mtx      sync.Mutex
+	accounts []*account
+	next     int
+	lastID   uint64
+	// workers holds the last time each worker was seen.
+	workers map[string]time.Time
+}
+
+func New(logger log.Logger, ctx context.Context, cfg Config, contract Contract) (*Pool, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	token := os.Getenv(TokenEnvName)
+	if token == "" {
+		return nil, errors.Errorf("missing required env variable:%v", TokenEnvName)
+	}
+	if cfg.JobDuration.Duration <= 0 {
+		return nil, errors.Errorf("invalid pool job duration:%v", cfg.JobDuration)
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	self := &Pool{
+		logger:   log.With(logger, "component", ComponentName),
+		cfg:      cfg,
+		ctx:      ctx,
+		stop:     stop,
+		contract: contract,
+		workers:  make(map[string]time.Time),
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName(ComponentName, &service{pool: self}); err != nil {
+		return nil, errors.Wrap(err, "registering the pool rpc service")
+	}
+	self.srv = &http.Server{
+		Handler: authenticate(token, server),
+		Addr:    fmt.Sprintf("%s:%d", cfg.ListenHost, cfg.ListenPort),
+	}
+	return self, nil
+}
+
+// AddAccount adds an account that gets its work from the tasker channel
+// and sends the solutions to the submitter channel.
+func (self *Pool) AddAccount(addr common.Address, taskerCh chan *mining.Work, submitterCh chan *mining.Result) {
+	acc := &account{addr: addr, submitterCh: submitterCh}
+	self.mtx.Lock()
+	self.accounts = append(self.accounts, acc)
+	self.mtx.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-self.ctx.Done():
+				return
+			case work := <-taskerCh:
+				self.setWork(acc, work)
+			}
+		}
+	}()
+}
+
+func (self *Pool) Start() error {
+	level.Info(self.logger).Log("msg", "starting", "addr", self.srv.Addr)
+	if err := self.srv.ListenAndServe(); err != http.ErrServerClosed {
+		return errors.Wrapf(err, "ListenAndServe")
+	}
+	return nil
+}
+
+func (self *Pool) Stop() {
+	self.stop()
+	if err := self.srv.Close(); err != nil {
+		level.Error(self.logger).Log("msg", "closing srv", "err", err)
+	}
+}
+
+func (self *Pool) setWork(acc *account, work *mining.Work) {
+	var deadline int64
+	timeOfLastNewValue, err := self.contract.GetUintVar(&bind.CallOpts{Context: self.ctx}, ethereum.Keccak256([]byte("_TIME_OF_LAST_NEW_VALUE")))
+	if err != nil {
+		level.Error(self.logger).Log("msg", "getting time of last new value, the job has no deadline", "err", err)
+	} else {
+		deadline = time.Unix(timeOfLastNewValue.Int64(), 0).Add(anySolutionAfter).Unix()
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.lastID++
+	acc.work = work
+	acc.jobID = self.lastID
+	acc.deadline = deadline
+	acc.sent = 0
+	acc.recv = 0
+	level.Info(self.logger).Log("msg", "new work",
+		"addr", acc.addr.String()[:6],
+		"challenge", fmt.Sprintf("%x", work.Challenge.Challenge),
+		"difficulty", work.Challenge.Difficulty,
+		"id", acc.jobID,
+	)
+}
+
+// getWork returns the next nonce range sized to take about the job duration at the worker hash rate.
+// The accounts with work are served in turns.
+func (self *Pool) getWork(req WorkRequest) *Job {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.seen(req.Worker, req.HashRate)
+
+	for i := 0; i < len(self.accounts); i++ {
+		acc := self.accounts[(self.next+i)%len(self.accounts)]
+		if acc.work == nil || acc.sent >= acc.work.N {
+			continue
+		}
+		self.next = (self.next + i + 1) % len(self.accounts)
+
+		n := uint64(math.Max(req.HashRate*self.cfg.JobDuration.Seconds(), 1))
+		if remaining := acc.work.N - acc.sent; n > remaining {
+			n = remaining
+		}
+		job := &Job{
+			ID:         acc.jobID,
+			Challenge:  acc.work.Challenge.Challenge,
+			Address:    acc.addr,
+			Difficulty: (*hexutil.Big)(acc.work.Challenge.Difficulty),
+			Start:      hexutil.Uint64(acc.work.Start + acc.sent),
+			N:          hexutil.Uint64(n),
+			Deadline:   acc.deadline,
+		}
+		acc.sent += n
+		return job
+	}
+	return nil
+}
+
+// report records the result of a job and returns false when the job is stale.
+func (self *Pool) report(r Report) bool {
+	self.mtx.Lock()
+	self.seen(r.Worker, -1)
+	hashesTotal.With(prometheus.Labels{"worker": r.Worker}).Add(float64(r.Hashes))
+
+	var acc *account
+	for _, a := range self.accounts {
+		if a.work != nil && a.jobID == r.ID {
+			acc = a
+			break
+		}
+	}
+	if acc == nil {
+		self.mtx.Unlock()
+		resultsTotal.With(prometheus.Labels{"result": "stale"}).Inc()
+		level.Debug(self.logger).Log("msg", "stale result", "worker", r.Worker, "id", r.ID)
+		return false
+	}
+
+	if r.Nonce != "" && !acc.valid(r.Nonce) {
+		self.mtx.Unlock()
+		resultsTotal.With(prometheus.Labels{"result": "invalid"}).Inc()
+		level.Warn(self.logger).Log("msg", "invalid solution", "worker", r.Worker, "id", r.ID, "solution", r.Nonce)
+		return true
+	}
+
+	acc.recv += uint64(r.N)
+	if r.Nonce == "" && acc.recv < acc.work.N {
+		self.mtx.Unlock()
+		resultsTotal.With(prometheus.Labels{"result": "checked"}).Inc()
+		return true
+	}
+	result := &mining.Result{Work: acc.work, Nonce: r.Nonce}
+	acc.work = nil
+	self.mtx.Unlock()
+
+	resultsTotal.With(prometheus.Labels{"result": "solution"}).Inc()
+	level.Info(self.logger).Log("msg", "found solution and sending the result",
+		"addr", acc.addr.String()[:6],
+		"worker", r.Worker,
+		"challenge", fmt.Sprintf("%x", result.Work.Challenge.Challenge),
+		"solution", result.Nonce,
+	)
+	select {
+	case acc.submitterCh <- result:
+	case <-self.ctx.Done():
+	}
+	return true
+}
+
+// valid checks a reported solution of the current work with a single hash.
+// Any nonce is valid only after the deadline.
+func (self *account) valid(nonce string) bool {
+	if nonce == "any" {
+		return self.deadline != 0 && time.Now().Unix() >= self.deadline
+	}
+	n, err := strconv.ParseUint(nonce, 10, 64)
+	if err != nil || strconv.FormatUint(n, 10) != nonce {
+		return false
+	}
+	hash := mining.NewHashSettings(self.work.Challenge, self.work.PublicAddr)
+	sol, _, err := mining.NewFastCpuMiner(0).CheckRange(context.Background(), hash, n, 1)
+	return err == nil && sol == nonce
+}
+
+// seen updates the worker stats, a negative hash rate keeps the last reported one.
+func (self *Pool) seen(name string, rate float64) {
+	if _, ok := self.workers[name]; !ok {
+		level.Info(self.logger).Log("msg", "new worker", "worker", name)
+	}
+	self.workers[name] = time.Now()
+	if rate >= 0 {
+		hashRate.With(prometheus.Labels{"worker": name}).Set(rate)
+	}
+
+	active := 0
+	for _, lastSeen := range self.workers {
+		if time.Since(lastSeen) < self.cfg.WorkerTimeout.Duration {
+			active++
+		}
+	}
+	workersGauge.Set(float64(active))
+}
+
+// service is the json rpc api used by the workers.
+type service struct {
+	pool *Pool
+}
+
+// GetWork returns the next job or nil when there is no work.
+func (self *service) GetWork(req WorkRequest) (*Job, error) {
+	if req.Worker == "" {
+		return nil, errors.New("missing worker name")
+	}
+	return self.pool.getWork(req), nil
+}
+
+// Submit reports a checked nonce range and returns false when the job is stale.
+func (self *service) Submit(r Report) (bool, error) {
+	if r.Worker == "" {
+		return false, errors.New("missing worker name")
+	}
+	return self.pool.report(r), nil
+}
+
+// authenticate rejects requests without the shared token.
+func authenticate(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,212 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package pool
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-kit/kit/log"
+	promTestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/mining"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+type fakeContract struct{}
+
+func (fakeContract) GetUintVar(opts *bind.CallOpts, _data [32]byte) (*big.Int, error) {
+	return big.NewInt(time.Now().Unix()), nil
+}
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+	defer os.Setenv(TokenEnvName, os.Getenv(TokenEnvName))
+	testutil.Ok(t, os.Setenv(TokenEnvName, "secret"))
+
+	pool, err := New(log.NewNopLogger(), ctx, Config{
+		LogLevel:      "info",
+		JobDuration:   format.Duration{Duration: 100 * time.Millisecond},
+		WorkerTimeout: format.Duration{Duration: time.Minute},
+	}, fakeContract{})
+	testutil.Ok(t, err)
+	defer pool.Stop()
+	srv := httptest.NewServer(pool.srv.Handler)
+	defer srv.Close()
+
+	// Requests without the token are rejected.
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"pool_getWork","params":[{"worker":"x"}]}`))
+	testutil.Ok(t, err)
+	testutil.Ok(t, resp.Body.Close())
+	testutil.Equals(t, http.StatusUnauthorized, resp.StatusCode)
+
+	addr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	taskerCh, submitterCh := make(chan *mining.Work), make(chan *mining.Result)
+	pool.AddAccount(addr, taskerCh, submitterCh)
+	work := &mining.Work{
+		Challenge: &mining.MiningChallenge{
+			Challenge:  common.HexToHash("0x01").Bytes(),
+			Difficulty: big.NewInt(1000),
+		},
+		PublicAddr: addr.Hex(),
+		Start:      5000,
+		N:          math.MaxInt64,
+	}
+	taskerCh <- work
+
+	worker, err := NewWorker(log.NewNopLogger(), ctx, WorkerConfig{
+		LogLevel:     "info",
+		URL:          srv.URL,
+		Name:         "test",
+		Threads:      2,
+		PollInterval: format.Duration{Duration: 10 * time.Millisecond},
+	})
+	testutil.Ok(t, err)
+	errCh := make(chan error)
+	go func() {
+		errCh <- worker.Start()
+	}()
+
+	var result *mining.Result
+	select {
+	case result = <-submitterCh:
+	case <-time.After(10 * time.Second):
+		t.Fatal("no solution from the worker")
+	}
+	testutil.Equals(t, work, result.Work)
+	worker.Stop()
+	testutil.Ok(t, <-errCh)
+
+	// Verify the solution with the in process hasher.
+	nonce, err := strconv.ParseUint(result.Nonce, 10, 64)
+	testutil.Ok(t, err)
+	testutil.Assert(t, nonce >= work.Start, "the solution is before the work start:%v", nonce)
+	hash := mining.NewHashSettings(work.Challenge, work.PublicAddr)
+	exp, _, err := mining.NewCpuMiner(0).CheckRange(ctx, hash, nonce, 1)
+	testutil.Ok(t, err)
+	testutil.Equals(t, result.Nonce, exp)
+
+	// Reports for the solved work are stale.
+	pool.mtx.Lock()
+	id := pool.lastID
+	pool.mtx.Unlock()
+	testutil.Equals(t, false, pool.report(Report{Worker: "test", ID: id, N: 1, Nonce: "1"}))
+}
+
+func TestPoolInvalidSolution(t *testing.T) {
+	ctx := context.Background()
+	defer os.Setenv(TokenEnvName, os.Getenv(TokenEnvName))
+	testutil.Ok(t, os.Setenv(TokenEnvName, "secret"))
+
+	pool, err := New(log.NewNopLogger(), ctx, Config{
+		LogLevel:      "info",
+		JobDuration:   format.Duration{Duration: time.Second},
+		WorkerTimeout: format.Duration{Duration: time.Minute},
+	}, fakeContract{})
+	testutil.Ok(t, err)
+	defer pool.Stop()
+
+	addr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	submitterCh := make(chan *mining.Result)
+	acc := &account{addr: addr, submitterCh: submitterCh}
+	pool.accounts = append(pool.accounts, acc)
+	work := &mining.Work{
+		Challenge: &mining.MiningChallenge{
+			Challenge:  common.HexToHash("0x02").Bytes(),
+			Difficulty: big.NewInt(1000),
+		},
+		PublicAddr: addr.Hex(),
+		N:          math.MaxInt64,
+	}
+	pool.setWork(acc, work)
+
+	// Find the first solution and a nonce before it which isn't one.
+	hash := mining.NewHashSettings(work.Challenge, work.PublicAddr)
+	solution, checked, err := mining.NewFastCpuMiner(0).CheckRange(ctx, hash, 0, 1000000)
+	testutil.Ok(t, err)
+	testutil.Assert(t, checked > 1, "the first nonce is a solution")
+	notSolution := strconv.FormatUint(checked-2, 10)
+
+	invalid := promTestutil.ToFloat64(resultsTotal.WithLabelValues("invalid"))
+	for _, nonce := range []string{notSolution, "0" + solution, "x", "any"} {
+		testutil.Equals(t, true, pool.report(Report{Worker: "test", ID: acc.jobID, N: 1, Nonce: nonce}))
+	}
+	testutil.Equals(t, invalid+4, promTestutil.ToFloat64(resultsTotal.WithLabelValues("invalid")))
+	testutil.Assert(t, acc.work != nil, "an invalid solution shouldn't end the work")
+
+	go pool.report(Report{Worker: "test", ID: acc.jobID, N: 1, Nonce: solution})
+	select {
+	case result := <-submitterCh:
+		testutil.Equals(t, solution, result.Nonce)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the valid solution wasn't forwarded")
+	}
+}
+
+// blockingHasher finds a solution immediately when solve is set
+// and otherwise runs until the context is done.
+type blockingHasher struct {
+	solve bool
+}
+
+func (self blockingHasher) CheckRange(anySolution context.Context, hash *mining.HashSettings, start uint64, n uint64) (string, uint64, error) {
+	if self.solve {
+		return strconv.FormatUint(start, 10), 1, nil
+	}
+	<-anySolution.Done()
+	return "any", n, nil
+}
+
+func (self blockingHasher) StepSize() uint64 { return 1 }
+
+func (self blockingHasher) Name() string { return "blocking" }
+
+func TestWorkerStopsAfterSolution(t *testing.T) {
+	defer os.Setenv(TokenEnvName, os.Getenv(TokenEnvName))
+	testutil.Ok(t, os.Setenv(TokenEnvName, "secret"))
+	worker, err := NewWorker(log.NewNopLogger(), context.Background(), WorkerConfig{
+		LogLevel: "info",
+		URL:      "http://localhost:1",
+		Name:     "test",
+	})
+	testutil.Ok(t, err)
+	defer worker.Stop()
+	worker.hashers = []mining.Hasher{blockingHasher{}, blockingHasher{solve: true}}
+
+	job := &Job{
+		ID:         1,
+		Challenge:  common.HexToHash("0x01").Bytes(),
+		Difficulty: (*hexutil.Big)(big.NewInt(1000)),
+		N:          1000,
+	}
+	done := make(chan struct{})
+	var (
+		nonce  string
+		hashes uint64
+	)
+	go func() {
+		defer close(done)
+		nonce, hashes, err = worker.check(job)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the worker didn't stop the other threads after a solution")
+	}
+	testutil.Ok(t, err)
+	// The stopped thread isn't reported as an any nonce solution.
+	testutil.Equals(t, "500", nonce)
+	testutil.Equals(t, uint64(1), hashes)
+}
@@ -0,0 +1,213 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package pool
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/mining"
+)
+
+const WorkerComponentName = "poolWorker"
+
+// Start with a small hash rate per thread as it increases much faster than it decreases.
+const rateInitialGuess = 100e3
+
+type WorkerConfig struct {
+	LogLevel     string
+	URL          string          `help:"URL of the pool."`
+	Name         string          `help:"Name reported to the pool, defaults to the hostname."`
+	Threads      int             `help:"Number of CPU hashing threads, 0 uses all CPUs."`
+	PollInterval format.Duration `help:"How long to wait before asking again when the pool has no work or is unreachable."`
+}
+
+// Worker checks the nonce ranges handed out by a pool.
+type Worker struct {
+	logger   log.Logger
+	cfg      WorkerConfig
+	ctx      context.Context
+	stop     context.CancelFunc
+	client   *rpc.Client
+	hashers  []mining.Hasher
+	hashRate float64
+}
+
+func NewWorker(logger log.Logger, ctx context.Context, cfg WorkerConfig) (*Worker, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	token := os.Getenv(TokenEnvName)
+	if token == "" {
+		return nil, errors.Errorf("missing required env variable:%v", TokenEnvName)
+	}
+	if cfg.Name == "" {
+		cfg.Name, err = os.Hostname()
+		if err != nil {
+			return nil, errors.Wrap(err, "getting the hostname for the worker name")
+		}
+	}
+	hashers, err := mining.NewHashers(cfg.Threads)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating hashers")
+	}
+	client, err := rpc.DialHTTP(cfg.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "dialing the pool")
+	}
+	client.SetHeader("Authorization", "Bearer "+token)
+
+	ctx, stop := context.WithCancel(ctx)
+	return &Worker{
+		logger:   log.With(logger, "component", WorkerComponentName),
+		cfg:      cfg,
+		ctx:      ctx,
+		stop:     stop,
+		client:   client,
+		hashers:  hashers,
+		hashRate: rateInitialGuess * float64(len(hashers)),
+	}, nil
+}
+
+func (self *Worker) Start() error {
+	level.Info(self.logger).Log("msg", "starting", "pool", self.cfg.URL, "name", self.cfg.Name, "threads", len(self.hashers))
+	for {
+		job, err := self.getWork()
+		if err != nil {
+			level.Error(self.logger).Log("msg", "getting work from the pool", "err", err)
+		}
+		if job == nil {
+			select {
+			case <-self.ctx.Done():
+				return nil
+			case <-time.After(self.cfg.PollInterval.Duration):
+				continue
+			}
+		}
+
+		started := time.Now()
+		nonce, hashes, err := self.check(job)
+		if self.ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "checking nonce range")
+		}
+		// The rate is wrong when a solution is found early.
+		if nonce == "" {
+			memory := 0.2
+			self.hashRate *= 1 - memory
+			self.hashRate += memory * float64(hashes) / time.Since(started).Seconds()
+		} else {
+			level.Info(self.logger).Log("msg", "found solution", "id", job.ID, "solution", nonce)
+		}
+
+		current, err := self.submit(Report{
+			Worker: self.cfg.Name,
+			ID:     job.ID,
+			N:      job.N,
+			Hashes: hexutil.Uint64(hashes),
+			Nonce:  nonce,
+		})
+		if err != nil {
+			level.Error(self.logger).Log("msg", "reporting to the pool", "err", err)
+			continue
+		}
+		if !current {
+			level.Debug(self.logger).Log("msg", "the job was stale", "id", job.ID)
+		}
+	}
+}
+
+func (self *Worker) Stop() {
+	self.stop()
+	self.client.Close()
+}
+
+func (self *Worker) getWork() (*Job, error) {
+	var job *Job
+	err := self.client.CallContext(self.ctx, &job, ComponentName+"_getWork", WorkRequest{Worker: self.cfg.Name, HashRate: self.hashRate})
+	return job, err
+}
+
+func (self *Worker) submit(r Report) (bool, error) {
+	var current bool
+	err := self.client.CallContext(self.ctx, &current, ComponentName+"_submit", r)
+	return current, err
+}
+
+// check splits the nonce range of the job between all hashers
+// and returns the first solution and the number of checked hashes.
+func (self *Worker) check(job *Job) (string, uint64, error) {
+	hash := mining.NewHashSettings(&mining.MiningChallenge{
+		Challenge:  job.Challenge,
+		Difficulty: job.Difficulty.ToInt(),
+	}, job.Address.Hex())
+
+	anySolution := self.ctx
+	if job.Deadline != 0 {
+		var cncl context.CancelFunc
+		anySolution, cncl = context.WithDeadline(self.ctx, time.Unix(job.Deadline, 0))
+		defer cncl()
+	}
+	// The other threads are stopped when one finds a solution.
+	found, cncl := context.WithCancel(anySolution)
+	defer cncl()
+
+	var (
+		wg     sync.WaitGroup
+		mtx    sync.Mutex
+		nonce  string
+		hashes uint64
+		errH   error
+	)
+	n := uint64(job.N)
+	step := (n + uint64(len(self.hashers)) - 1) / uint64(len(self.hashers))
+	for i, hasher := range self.hashers {
+		start := uint64(i) * step
+		if start >= n {
+			break
+		}
+		count := step
+		if start+count > n {
+			count = n - start
+		}
+		wg.Add(1)
+		go func(hasher mining.Hasher, start, count uint64) {
+			defer wg.Done()
+			sol, checked, err := hasher.CheckRange(found, hash, start, count)
+			mtx.Lock()
+			defer mtx.Unlock()
+			if err != nil {
+				errH = err
+				return
+			}
+			// A thread stopped after another solution returns any nonce
+			// which is a solution only when the deadline has passed.
+			if sol == "any" && anySolution.Err() != context.DeadlineExceeded {
+				return
+			}
+			hashes += checked
+			if sol != "" && nonce == "" {
+				nonce = sol
+				cncl()
+			}
+		}(hasher, uint64(job.Start)+start, count)
+	}
+	wg.Wait()
+	if errH != nil {
+		return "", 0, errH
+	}
+	return nonce, hashes, nil
+}
@@ -0,0 +1,204 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package psr
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/format"
+)
+
+// Methods used to calculate a value.
+// Every value returned by the PSRs is tagged with one of these.
+const (
+	MethodDefault = "default"
+	MethodManual  = "manual"
+	MethodMedian  = "median"
+	MethodMean    = "mean"
+	MethodTWAP    = "twap"
+	MethodOnChain = "onchain"
+)
+
+var valuesCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "telliot",
+	Subsystem: "psr",
+	Name:      "values_total",
+	Help:      "The total number of values returned by the PSRs by the method used to calculate them",
+},
+	[]string{"psr", "id", "method"},
+)
+
+// ValuesCountInc records a value returned by a PSR.
+func ValuesCountInc(psr string, reqID int64, method string) {
+	valuesCount.With(prometheus.Labels{"psr": psr, "id": strconv.FormatInt(reqID, 10), "method": method}).Inc()
+}
+
+// Info describes how a PSR calculated a value.
+type Info struct {
+	// Method is one of the methods used to calculate a value.
+	Method string
+	// Symbol is the aggregated symbol and is empty for the manual and onchain methods.
+	Symbol string
+	// Sources are the index sources with values for the symbol used to calculate the value.
+	Sources []string
+}
+
+// Aggregator is the subset of the aggregator methods used by the fallback chain.
+type Aggregator interface {
+	MedianOver(symbol string, at time.Time, lookBack time.Duration) (float64, float64, error)
+	MeanAt(symbol string, at time.Time) (float64, float64, error)
+	TimeWeightedAvg(symbol string, start time.Time, lookBack time.Duration) (float64, float64, error)
+	Sources(symbol string, at time.Time, lookBack time.Duration) ([]string, error)
+}
+
+// OnChainValue returns the last accepted on-chain value for a data ID and its timestamp.
+type OnChainValue func(reqID int64) (float64, time.Time, error)
+
+// Fallback is an alternative method to calculate a value
+// when the default method doesn't reach the min confidence.
+type Fallback struct {
+	Method        string          `help:"one of median, mean, twap or onchain"`
+	Symbol        string          `help:"the symbol to aggregate, not used by the onchain method"`
+	LookBack      format.Duration `help:"look back period for the median and twap methods and max age of the value for the onchain method"`
+	MinConfidence float64         `help:"min confidence for this method, never lower than the absolute min confidence"`
+}
+
+func (self Fallback) Validate() error {
+	switch self.Method {
+	case MethodMedian, MethodTWAP:
+		if self.LookBack.Duration <= 0 {
+			return errors.Errorf("%v requires a look back", self.Method)
+		}
+	case MethodMean:
+	case MethodOnChain:
+		if self.LookBack.Duration <= 0 {
+			return errors.New("onchain requires a look back as max age")
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown method:%v", self.Method)
+	}
+	if self.Symbol == "" {
+		return errors.Errorf("%v requires a symbol", self.Method)
+	}
+	return nil
+}
+
+// FallbackChain tries the fallback methods in order
+// until one of them reaches its min confidence.
+type FallbackChain struct {
+	logger        log.Logger
+	aggregator    Aggregator
+	onChain       OnChainValue
+	fallbacks     map[int64][]Fallback
+	minConfidence float64
+}
+
+// NewFallbackChain creates a fallback chain.
+// The min confidence is the absolute min bound for all fallback methods.
+// The onChain func can be nil when the oracle doesn't support reading the last value.
+func NewFallbackChain(
+	logger log.Logger,
+	aggregator Aggregator,
+	onChain OnChainValue,
+	fallbacks map[int64][]Fallback,
+	minConfidence float64,
+) (*FallbackChain, error) {
+	if len(fallbacks) > 0 && minConfidence <= 0 {
+		return nil, errors.New("fallbacks require an absolute min confidence")
+	}
+	for reqID, ff := range fallbacks {
+		for i, f := range ff {
+			if err := f.Validate(); err != nil {
+				return nil, errors.Wrapf(err, "fallback id:%v, index:%v", reqID, i)
+			}
+			if f.Method == MethodOnChain && onChain == nil {
+				return nil, errors.Errorf("fallback id:%v, index:%v onchain method not supported", reqID, i)
+			}
+		}
+	}
+	return &FallbackChain{
+		logger:        logger,
+		aggregator:    aggregator,
+		onChain:       onChain,
+		fallbacks:     fallbacks,
+		minConfidence: minConfidence,
+	}, nil
+}
+
+// Value returns the value of the first fallback that reaches its min confidence and how it was calculated.
+func (self *FallbackChain) Value(reqID int64, ts time.Time) (float64, Info, error) {
+	fallbacks, ok := self.fallbacks[reqID]
+	if !ok {
+		return 0, Info{}, errors.Errorf("no fallbacks for request ID:%v", reqID)
+	}
+	for i, f := range fallbacks {
+		val, err := self.value(reqID, ts, f)
+		if err != nil {
+			level.Debug(self.logger).Log("msg", "fallback failed", "reqID", reqID, "index", i, "method", f.Method, "err", err)
+			continue
+		}
+		info := Info{Method: f.Method}
+		if f.Method == MethodOnChain {
+			return val, info, nil
+		}
+		info.Symbol = f.Symbol
+		lookBack := f.LookBack.Duration
+		if f.Method == MethodMean {
+			lookBack = 0
+		}
+		info.Sources, err = self.aggregator.Sources(f.Symbol, ts, lookBack)
+		if err != nil {
+			level.Warn(self.logger).Log("msg", "getting the value sources", "reqID", reqID, "symbol", f.Symbol, "err", err)
+		}
+		return val, info, nil
+	}
+	return 0, Info{}, errors.Errorf("all fallbacks failed for request ID:%v", reqID)
+}
+
+func (self *FallbackChain) value(reqID int64, ts time.Time, f Fallback) (float64, error) {
+	var (
+		val  float64
+		conf float64
+		err  error
+	)
+	switch f.Method {
+	case MethodMedian:
+		val, conf, err = self.aggregator.MedianOver(f.Symbol, ts, f.LookBack.Duration)
+	case MethodMean:
+		val, conf, err = self.aggregator.MeanAt(f.Symbol, ts)
+	case MethodTWAP:
+		val, conf, err = self.aggregator.TimeWeightedAvg(f.Symbol, ts, f.LookBack.Duration)
+	case MethodOnChain:
+		var updated time.Time
+		val, updated, err = self.onChain(reqID)
+		if err != nil {
+			return 0, err
+		}
+		if age := ts.Sub(updated); age > f.LookBack.Duration {
+			return 0, errors.Errorf("on-chain value too old - age:%v, max age:%v", age, f.LookBack.Duration)
+		}
+		return val, nil
+	default:
+		return 0, errors.Errorf("unknown method:%v", f.Method)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	minConfidence := f.MinConfidence
+	if minConfidence < self.minConfidence {
+		minConfidence = self.minConfidence
+	}
+	if conf < minConfidence {
+		return 0, errors.Errorf("not enough confidence - value:%v, conf:%v,confidence threshold:%v", val, conf, minConfidence)
+	}
+	return val, nil
+}
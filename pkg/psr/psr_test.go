@@ -0,0 +1,83 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package psr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+type mockAggr struct {
+	median, twap   float64
+	medianC, twapC float64
+}
+
+func (self *mockAggr) MedianOver(_ string, _ time.Time, _ time.Duration) (float64, float64, error) {
+	return self.median, self.medianC, nil
+}
+
+func (self *mockAggr) MeanAt(_ string, _ time.Time) (float64, float64, error) {
+	return 0, 0, errors.New("no vals")
+}
+
+func (self *mockAggr) TimeWeightedAvg(_ string, _ time.Time, _ time.Duration) (float64, float64, error) {
+	return self.twap, self.twapC, nil
+}
+
+func (self *mockAggr) Sources(_ string, _ time.Time, _ time.Duration) ([]string, error) {
+	return []string{"a", "b"}, nil
+}
+
+func TestFallbackChain(t *testing.T) {
+	now := time.Now()
+	onChain := func(reqID int64) (float64, time.Time, error) {
+		return 3, now.Add(-10 * time.Minute), nil
+	}
+	fallbacks := map[int64][]Fallback{
+		1: {
+			{Method: MethodMedian, Symbol: "ETH/USD", LookBack: format.Duration{Duration: 5 * time.Minute}, MinConfidence: 80},
+			{Method: MethodTWAP, Symbol: "ETH/USD", LookBack: format.Duration{Duration: time.Hour}},
+			{Method: MethodOnChain, LookBack: format.Duration{Duration: 15 * time.Minute}},
+		},
+	}
+
+	type testcase struct {
+		name string
+		aggr *mockAggr
+		ts   time.Time
+		val  float64
+		info Info
+		err  bool
+	}
+	for _, tc := range []testcase{
+		{name: "first that reaches its confidence wins", aggr: &mockAggr{median: 1, medianC: 90, twap: 2, twapC: 90}, ts: now, val: 1, info: Info{Method: MethodMedian, Symbol: "ETH/USD", Sources: []string{"a", "b"}}},
+		{name: "per method min confidence", aggr: &mockAggr{median: 1, medianC: 70, twap: 2, twapC: 70}, ts: now, val: 2, info: Info{Method: MethodTWAP, Symbol: "ETH/USD", Sources: []string{"a", "b"}}},
+		{name: "absolute min confidence", aggr: &mockAggr{median: 1, medianC: 40, twap: 2, twapC: 40}, ts: now, val: 3, info: Info{Method: MethodOnChain}},
+		{name: "on-chain value too old", aggr: &mockAggr{}, ts: now.Add(10 * time.Minute), err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			chain, err := NewFallbackChain(log.NewNopLogger(), tc.aggr, onChain, fallbacks, 50)
+			testutil.Ok(t, err)
+			val, info, err := chain.Value(1, tc.ts)
+			if tc.err {
+				testutil.NotOk(t, err)
+				return
+			}
+			testutil.Ok(t, err)
+			testutil.Equals(t, tc.val, val)
+			testutil.Equals(t, tc.info, info)
+		})
+	}
+
+	_, err := NewFallbackChain(log.NewNopLogger(), &mockAggr{}, onChain, fallbacks, 0)
+	testutil.NotOk(t, err, "fallbacks without an absolute min confidence should be rejected")
+
+	_, err = NewFallbackChain(log.NewNopLogger(), &mockAggr{}, nil, fallbacks, 50)
+	testutil.NotOk(t, err, "onchain without a value getter should be rejected")
+}
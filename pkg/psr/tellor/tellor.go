@@ -5,12 +5,17 @@ package tellor
 
 import (
 	"math"
+	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/aggregator"
+	"github.com/tellor-io/telliot/pkg/contracts/lens"
+	"github.com/tellor-io/telliot/pkg/manual"
+	"github.com/tellor-io/telliot/pkg/psr"
 )
 
 const (
@@ -18,170 +23,316 @@ const (
 	DefaultGranularity = 1000000
 )
 
-func New(logger log.Logger, cfg Config, aggregator *aggregator.Aggregator) *Psr {
-	return &Psr{
-		logger:     log.With(logger, "component", ComponentName),
+// ContractCaller reads the last accepted on-chain values.
+type ContractCaller interface {
+	GetLastValues(opts *bind.CallOpts, _dataID *big.Int, _count *big.Int) ([]lens.MainValue, error)
+}
+
+func New(logger log.Logger, cfg Config, aggregator *aggregator.Aggregator, manual *manual.Store, contract ContractCaller) (*Psr, error) {
+	logger = log.With(logger, "component", ComponentName)
+	for reqID, calendar := range cfg.EOD {
+		if _, err := calendar.LastClose(time.Now()); err != nil {
+			return nil, errors.Wrapf(err, "invalid EOD calendar for request ID:%v", reqID)
+		}
+	}
+	self := &Psr{
+		logger:     logger,
 		aggregator: aggregator,
+		manual:     manual,
+		contract:   contract,
 		cfg:        cfg,
 	}
+	fallbacks, err := psr.NewFallbackChain(logger, aggregator, self.onChainValue, cfg.Fallbacks, cfg.AbsoluteMinConfidence)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating fallback chain")
+	}
+	self.fallbacks = fallbacks
+	return self, nil
 }
 
 type Config struct {
-	MinConfidence float64
+	MinConfidence         float64
+	AbsoluteMinConfidence float64                       `help:"the min confidence for all fallback methods"`
+	Fallbacks             map[int64][]psr.Fallback      `help:"methods to try in order, per request ID, when the default method doesn't reach the min confidence"`
+	EOD                   map[int64]aggregator.Calendar `help:"market close calendars for the end of day request IDs, the default is midnight UTC every day and ID 56 uses the latest values when not set"`
 }
 
 type Psr struct {
 	logger     log.Logger
 	aggregator *aggregator.Aggregator
+	manual     *manual.Store
+	contract   ContractCaller
+	fallbacks  *psr.FallbackChain
 	cfg        Config
 }
 
-func (self *Psr) GetValue(reqID int64, ts time.Time) (int64, error) {
-	val, err := self.getValue(reqID, ts)
-	return int64(math.Round(val * DefaultGranularity)), err
+// GetValue returns the value for a request ID and how it was calculated.
+func (self *Psr) GetValue(reqID int64, ts time.Time) (int64, psr.Info, error) {
+	val, info, err := self.getValue(reqID, ts)
+	if err != nil {
+		return 0, psr.Info{}, err
+	}
+	psr.ValuesCountInc("tellor", reqID, info.Method)
+	return int64(math.Round(val * DefaultGranularity)), info, nil
 }
 
-func (self *Psr) getValue(reqID int64, ts time.Time) (float64, error) {
-	val, err := self.aggregator.ManualValue("tellor", reqID, ts)
+func (self *Psr) getValue(reqID int64, ts time.Time) (float64, psr.Info, error) {
+	entry, ok, err := self.manual.Value(manual.OracleTellor, reqID, ts)
 	if err != nil {
-		level.Error(self.logger).Log("msg", "get manual value", "reqID", reqID, "err", err)
+		return 0, psr.Info{}, errors.Wrap(err, "get manual value")
+	}
+	if ok {
+		level.Warn(self.logger).Log("msg", "USING MANUAL VALUE", "reqID", reqID, "val", entry.Value, "validUntil", entry.ValidUntil, "author", entry.Author, "reason", entry.Reason)
+		return entry.Value, psr.Info{Method: psr.MethodManual}, nil
+	}
+
+	val, info, err := self.defaultValue(reqID, ts)
+	if err == nil {
+		return val, info, nil
+	}
+	if _, ok := self.cfg.Fallbacks[reqID]; !ok {
+		return 0, psr.Info{}, err
 	}
-	if val != 0 {
-		level.Warn(self.logger).Log("msg", "USING MANUAL VALUE", "reqID", reqID, "val", val)
-		return val, nil
+
+	level.Warn(self.logger).Log("msg", "default method failed, trying the fallbacks", "reqID", reqID, "err", err)
+	val, info, errF := self.fallbacks.Value(reqID, ts)
+	if errF != nil {
+		return 0, psr.Info{}, errors.Wrapf(errF, "default method failed:%v", err)
 	}
+	level.Warn(self.logger).Log("msg", "USING FALLBACK VALUE", "reqID", reqID, "val", val, "method", info.Method)
+	return val, info, nil
+}
 
-	var conf float64
+func (self *Psr) defaultValue(reqID int64, ts time.Time) (float64, psr.Info, error) {
+	var (
+		val    float64
+		conf   float64
+		err    error
+		symbol string
+		// lookBack is the period used by the method,
+		// zero for the methods that use the latest values.
+		lookBack time.Duration
+		// eod methods use the values at the last market close.
+		eod bool
+	)
 	switch reqID {
 	case 1:
-		val, conf, err = self.aggregator.MedianAt("ETH/USD", ts)
+		symbol = "ETH/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 2:
-		val, conf, err = self.aggregator.MedianAt("BTC/USD", ts)
+		symbol = "BTC/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 3:
-		val, conf, err = self.aggregator.MedianAt("BNB/USD", ts)
+		symbol = "BNB/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 4:
-		val, conf, err = self.aggregator.TimeWeightedAvg("BTC/USD", ts, 24*time.Hour)
+		symbol, lookBack = "BTC/USD", 24*time.Hour
+		val, conf, err = self.aggregator.TimeWeightedAvg(symbol, ts, lookBack)
 	case 5:
-		val, conf, err = self.aggregator.MedianAt("ETH/BTC", ts)
+		symbol = "ETH/BTC"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 6:
-		val, conf, err = self.aggregator.MedianAt("BNB/BTC", ts)
+		symbol = "BNB/BTC"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 7:
-		val, conf, err = self.aggregator.MedianAt("BNB/ETH", ts)
+		symbol = "BNB/ETH"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 8:
-		val, conf, err = self.aggregator.TimeWeightedAvg("ETH/USD", ts, 24*time.Hour)
+		symbol, lookBack = "ETH/USD", 24*time.Hour
+		val, conf, err = self.aggregator.TimeWeightedAvg(symbol, ts, lookBack)
 	case 9:
-		val, conf, err = self.aggregator.MedianAtEOD("ETH/USD", ts)
+		symbol, eod = "ETH/USD", true
+		val, conf, err = self.aggregator.MedianAtEOD(symbol, ts, self.cfg.EOD[reqID])
 	case 10: // For more details see https://docs.google.com/document/d/1RFCApk1PznMhSRVhiyFl_vBDPA4mP2n1dTmfqjvuTNw/edit
-		val, conf, err = self.aggregator.VolumWeightedAvg("AMPL/USD", time.Now().Add(-(24 * time.Hour)), time.Now(), 10*time.Minute)
+		symbol, lookBack = "AMPL/USD", 24*time.Hour
+		val, conf, err = self.aggregator.VolumWeightedAvg(symbol, ts.Add(-lookBack), ts, 10*time.Minute)
 	case 11:
-		val, conf, err = self.aggregator.MedianAt("ZEC/ETH", ts)
+		symbol = "ZEC/ETH"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 12:
-		val, conf, err = self.aggregator.MedianAt("TRX/ETH", ts)
+		symbol = "TRX/ETH"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 13:
-		val, conf, err = self.aggregator.MedianAt("XRP/USD", ts)
+		symbol = "XRP/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 14:
-		val, conf, err = self.aggregator.MedianAt("XMR/ETH", ts)
+		symbol = "XMR/ETH"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 15:
-		val, conf, err = self.aggregator.MedianAt("ATOM/USD", ts)
+		symbol = "ATOM/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 16:
-		val, conf, err = self.aggregator.MedianAt("LTC/USD", ts)
+		symbol = "LTC/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 17:
-		val, conf, err = self.aggregator.MedianAt("WAVES/BTC", ts)
+		symbol = "WAVES/BTC"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 18:
-		val, conf, err = self.aggregator.MedianAt("REP/BTC", ts)
+		symbol = "REP/BTC"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 19:
-		val, conf, err = self.aggregator.MedianAt("TUSD/ETH", ts)
+		symbol = "TUSD/ETH"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 20:
-		val, conf, err = self.aggregator.MedianAt("EOS/USD", ts)
+		symbol = "EOS/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 21:
-		val, conf, err = self.aggregator.MedianAt("IOTA/USD", ts)
+		symbol = "IOTA/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 22:
-		val, conf, err = self.aggregator.MedianAt("ETC/USD", ts)
+		symbol = "ETC/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 23:
-		val, conf, err = self.aggregator.MedianAt("ETH/PAX", ts)
+		symbol = "ETH/PAX"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 24:
-		val, conf, err = self.aggregator.TimeWeightedAvg("ETH/BTC", ts, time.Hour)
+		symbol, lookBack = "ETH/BTC", time.Hour
+		val, conf, err = self.aggregator.TimeWeightedAvg(symbol, ts, lookBack)
 	case 25:
-		val, conf, err = self.aggregator.MedianAt("USDC/USDT", ts)
+		symbol = "USDC/USDT"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 26:
-		val, conf, err = self.aggregator.MedianAt("XTZ/USD", ts)
+		symbol = "XTZ/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 27:
-		val, conf, err = self.aggregator.MedianAt("LINK/USD", ts)
+		symbol = "LINK/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 28:
-		val, conf, err = self.aggregator.MedianAt("ZRX/BNB", ts)
+		symbol = "ZRX/BNB"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 29:
-		val, conf, err = self.aggregator.MedianAt("ZEC/USD", ts)
+		symbol = "ZEC/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 30:
-		val, conf, err = self.aggregator.MedianAt("XAU/USD", ts)
+		symbol = "XAU/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 31:
-		val, conf, err = self.aggregator.MedianAt("MATIC/USD", ts)
+		symbol = "MATIC/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 32:
-		val, conf, err = self.aggregator.MedianAt("BAT/USD", ts)
+		symbol = "BAT/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 33:
-		val, conf, err = self.aggregator.MedianAt("ALGO/USD", ts)
+		symbol = "ALGO/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 34:
-		val, conf, err = self.aggregator.MedianAt("ZRX/USD", ts)
+		symbol = "ZRX/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 35:
-		val, conf, err = self.aggregator.MedianAt("COS/USD", ts)
+		symbol = "COS/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 36:
-		val, conf, err = self.aggregator.MedianAt("BCH/USD", ts)
+		symbol = "BCH/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 37:
-		val, conf, err = self.aggregator.MedianAt("REP/USD", ts)
+		symbol = "REP/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 38:
-		val, conf, err = self.aggregator.MedianAt("GNO/USD", ts)
+		symbol = "GNO/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 39:
-		val, conf, err = self.aggregator.MedianAt("DAI/USD", ts)
+		symbol = "DAI/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 40:
-		val, conf, err = self.aggregator.MedianAt("STEEM/BTC", ts)
+		symbol = "STEEM/BTC"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 41:
 		// ID 41 is always manual so it sholud never get here.
 		// It is three month average for US PCE (monthly levels): https://www.bea.gov/data/personal-consumption-expenditures-price-index-excluding-food-and-energy
-		return 0, errors.New("no manual entry for request ID 41")
+		return 0, psr.Info{}, errors.New("no manual entry for request ID 41")
 	case 42:
-		val, conf, err = self.aggregator.MedianAtEOD("BTC/USD", ts)
+		symbol, eod = "BTC/USD", true
+		val, conf, err = self.aggregator.MedianAtEOD(symbol, ts, self.cfg.EOD[reqID])
 	case 43:
-		val, conf, err = self.aggregator.MedianAt("TRB/ETH", ts)
+		symbol = "TRB/ETH"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 44:
-		val, conf, err = self.aggregator.TimeWeightedAvg("BTC/USD", ts, time.Hour)
+		symbol, lookBack = "BTC/USD", time.Hour
+		val, conf, err = self.aggregator.TimeWeightedAvg(symbol, ts, lookBack)
 	case 45:
-		val, conf, err = self.aggregator.MedianAtEOD("TRB/USD", ts)
+		symbol, eod = "TRB/USD", true
+		val, conf, err = self.aggregator.MedianAtEOD(symbol, ts, self.cfg.EOD[reqID])
 	case 46:
-		val, conf, err = self.aggregator.TimeWeightedAvg("ETH/USD", ts, time.Hour)
+		symbol, lookBack = "ETH/USD", time.Hour
+		val, conf, err = self.aggregator.TimeWeightedAvg(symbol, ts, lookBack)
 	case 47:
-		val, conf, err = self.aggregator.MedianAt("BSV/USD", ts)
+		symbol = "BSV/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 48:
-		val, conf, err = self.aggregator.MedianAt("MAKER/USD", ts)
+		symbol = "MAKER/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 49:
-		val, conf, err = self.aggregator.TimeWeightedAvg("BCH/USD", ts, 24*time.Hour)
+		symbol, lookBack = "BCH/USD", 24*time.Hour
+		val, conf, err = self.aggregator.TimeWeightedAvg(symbol, ts, lookBack)
 	case 50:
-		val, conf, err = self.aggregator.MedianAt("TRB/USD", ts)
+		symbol = "TRB/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 51:
-		val, conf, err = self.aggregator.MedianAt("XMR/USD", ts)
+		symbol = "XMR/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 52:
-		val, conf, err = self.aggregator.MedianAt("XFT/USD", ts)
+		symbol = "XFT/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 53:
-		val, conf, err = self.aggregator.MedianAt("BTCDOMINANCE", ts)
+		symbol = "BTCDOMINANCE"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 54:
-		val, conf, err = self.aggregator.MedianAt("WAVES/USD", ts)
+		symbol = "WAVES/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 55:
-		val, conf, err = self.aggregator.MedianAt("OGN/USD", ts)
+		symbol = "OGN/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 56:
-		val, conf, err = self.aggregator.MedianAt("VIXEOD", ts)
+		// The VIX uses the latest values unless a market close calendar is configured for it.
+		symbol = "VIXEOD"
+		if calendar, ok := self.cfg.EOD[reqID]; ok {
+			eod = true
+			val, conf, err = self.aggregator.MedianAtEOD(symbol, ts, calendar)
+		} else {
+			val, conf, err = self.aggregator.MedianAt(symbol, ts)
+		}
 	case 57:
-		val, conf, err = self.aggregator.MedianAt("DEFITVL", ts)
+		symbol = "DEFITVL"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 58:
-		val, conf, err = self.aggregator.MeanAt("DEFIMCAP", ts)
+		symbol = "DEFIMCAP"
+		val, conf, err = self.aggregator.MeanAt(symbol, ts)
 	default:
-		return 0, errors.Errorf("undeclared request ID:%v", reqID)
+		return 0, psr.Info{}, errors.Errorf("undeclared request ID:%v", reqID)
 	}
 
 	if err != nil {
-		return 0, err
+		return 0, psr.Info{}, err
 	}
 
 	if conf < self.cfg.MinConfidence {
-		return 0, errors.Errorf("not enough confidence - value:%v, conf:%v,confidence threshold:%v", val, conf, self.cfg.MinConfidence)
+		return 0, psr.Info{}, errors.Errorf("not enough confidence - value:%v, conf:%v,confidence threshold:%v", val, conf, self.cfg.MinConfidence)
+	}
+
+	info := psr.Info{Method: psr.MethodDefault, Symbol: symbol}
+	sourcesAt := ts
+	if eod {
+		if sourcesAt, err = self.cfg.EOD[reqID].LastClose(ts); err != nil {
+			return 0, psr.Info{}, errors.Wrap(err, "getting the last market close")
+		}
+	}
+	// The sources are only for the records so the value is still used without them.
+	info.Sources, err = self.aggregator.Sources(symbol, sourcesAt, lookBack)
+	if err != nil {
+		level.Warn(self.logger).Log("msg", "getting the value sources", "reqID", reqID, "symbol", symbol, "err", err)
 	}
 
-	return val, err
+	return val, info, nil
+}
+
+func (self *Psr) onChainValue(reqID int64) (float64, time.Time, error) {
+	vals, err := self.contract.GetLastValues(&bind.CallOpts{}, big.NewInt(reqID), big.NewInt(1))
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "getting last on-chain value")
+	}
+	if len(vals) == 0 || vals[0].Timestamp == nil || vals[0].Timestamp.Int64() == 0 {
+		return 0, time.Time{}, errors.Errorf("no on-chain value for request ID:%v", reqID)
+	}
+	val, _ := new(big.Float).SetInt(vals[0].Value).Float64()
+	return val / DefaultGranularity, time.Unix(vals[0].Timestamp.Int64(), 0), nil
 }
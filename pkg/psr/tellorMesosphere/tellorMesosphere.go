@@ -5,12 +5,16 @@ package tellorMesosphere
 
 import (
 	"math"
+	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/aggregator"
+	"github.com/tellor-io/telliot/pkg/manual"
+	"github.com/tellor-io/telliot/pkg/psr"
 )
 
 const (
@@ -18,56 +22,124 @@ const (
 	DefaultGranularity = 1000000
 )
 
-func New(logger log.Logger, cfg Config, aggregator *aggregator.Aggregator) *Psr {
-	return &Psr{
-		logger:     log.With(logger, "component", ComponentName),
+// ContractCaller reads the last accepted on-chain values.
+type ContractCaller interface {
+	GetCurrentValue(opts *bind.CallOpts, _requestId *big.Int) (bool, *big.Int, *big.Int, error)
+}
+
+func New(logger log.Logger, cfg Config, aggregator *aggregator.Aggregator, manual *manual.Store, contract ContractCaller) (*Psr, error) {
+	logger = log.With(logger, "component", ComponentName)
+	self := &Psr{
+		logger:     logger,
 		aggregator: aggregator,
+		manual:     manual,
+		contract:   contract,
 		cfg:        cfg,
 	}
+	fallbacks, err := psr.NewFallbackChain(logger, aggregator, self.onChainValue, cfg.Fallbacks, cfg.AbsoluteMinConfidence)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating fallback chain")
+	}
+	self.fallbacks = fallbacks
+	return self, nil
 }
 
 type Config struct {
-	MinConfidence float64
+	MinConfidence         float64
+	AbsoluteMinConfidence float64                  `help:"the min confidence for all fallback methods"`
+	Fallbacks             map[int64][]psr.Fallback `help:"methods to try in order, per request ID, when the default method doesn't reach the min confidence"`
 }
 
 type Psr struct {
 	logger     log.Logger
 	aggregator *aggregator.Aggregator
+	manual     *manual.Store
+	contract   ContractCaller
+	fallbacks  *psr.FallbackChain
 	cfg        Config
 }
 
-func (self *Psr) GetValue(reqID int64, ts time.Time) (int64, error) {
-	val, err := self.getValue(reqID, ts)
-	return int64(math.Round(val * DefaultGranularity)), err
+// GetValue returns the value for a request ID and how it was calculated.
+func (self *Psr) GetValue(reqID int64, ts time.Time) (int64, psr.Info, error) {
+	val, info, err := self.getValue(reqID, ts)
+	if err != nil {
+		return 0, psr.Info{}, err
+	}
+	psr.ValuesCountInc("tellorMesosphere", reqID, info.Method)
+	return int64(math.Round(val * DefaultGranularity)), info, nil
 }
 
-func (self *Psr) getValue(reqID int64, ts time.Time) (float64, error) {
-	val, err := self.aggregator.ManualValue("tellorMesosphere", reqID, ts)
+func (self *Psr) getValue(reqID int64, ts time.Time) (float64, psr.Info, error) {
+	entry, ok, err := self.manual.Value(manual.OracleTellorMesosphere, reqID, ts)
 	if err != nil {
-		level.Error(self.logger).Log("msg", "get manual value", "reqID", reqID, "err", err)
+		return 0, psr.Info{}, errors.Wrap(err, "get manual value")
+	}
+	if ok {
+		level.Warn(self.logger).Log("msg", "USING MANUAL VALUE", "reqID", reqID, "val", entry.Value, "validUntil", entry.ValidUntil, "author", entry.Author, "reason", entry.Reason)
+		return entry.Value, psr.Info{Method: psr.MethodManual}, nil
+	}
+
+	val, info, err := self.defaultValue(reqID, ts)
+	if err == nil {
+		return val, info, nil
+	}
+	if _, ok := self.cfg.Fallbacks[reqID]; !ok {
+		return 0, psr.Info{}, err
 	}
-	if val != 0 {
-		level.Warn(self.logger).Log("msg", "USING MANUAL VALUE", "reqID", reqID, "val", val)
-		return val, nil
+
+	level.Warn(self.logger).Log("msg", "default method failed, trying the fallbacks", "reqID", reqID, "err", err)
+	val, info, errF := self.fallbacks.Value(reqID, ts)
+	if errF != nil {
+		return 0, psr.Info{}, errors.Wrapf(errF, "default method failed:%v", err)
 	}
+	level.Warn(self.logger).Log("msg", "USING FALLBACK VALUE", "reqID", reqID, "val", val, "method", info.Method)
+	return val, info, nil
+}
 
-	var conf float64
+func (self *Psr) defaultValue(reqID int64, ts time.Time) (float64, psr.Info, error) {
+	var (
+		val    float64
+		conf   float64
+		err    error
+		symbol string
+	)
 	switch reqID {
 	case 1:
-		val, conf, err = self.aggregator.MedianAt("ETH/USD", ts)
+		symbol = "ETH/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	case 2:
-		val, conf, err = self.aggregator.MedianAt("BTC/USD", ts)
+		symbol = "BTC/USD"
+		val, conf, err = self.aggregator.MedianAt(symbol, ts)
 	default:
-		return 0, errors.Errorf("undeclared request ID:%v", reqID)
+		return 0, psr.Info{}, errors.Errorf("undeclared request ID:%v", reqID)
 	}
 
 	if err != nil {
-		return 0, err
+		return 0, psr.Info{}, err
 	}
 
 	if conf < self.cfg.MinConfidence {
-		return 0, errors.Errorf("not enough confidence - value:%v, conf:%v,confidence threshold:%v", val, conf, self.cfg.MinConfidence)
+		return 0, psr.Info{}, errors.Errorf("not enough confidence - value:%v, conf:%v,confidence threshold:%v", val, conf, self.cfg.MinConfidence)
+	}
+
+	info := psr.Info{Method: psr.MethodDefault, Symbol: symbol}
+	// The sources are only for the records so the value is still used without them.
+	info.Sources, err = self.aggregator.Sources(symbol, ts, 0)
+	if err != nil {
+		level.Warn(self.logger).Log("msg", "getting the value sources", "reqID", reqID, "symbol", symbol, "err", err)
 	}
 
-	return val, err
+	return val, info, nil
+}
+
+func (self *Psr) onChainValue(reqID int64) (float64, time.Time, error) {
+	ok, value, timestamp, err := self.contract.GetCurrentValue(&bind.CallOpts{}, big.NewInt(reqID))
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "getting last on-chain value")
+	}
+	if !ok {
+		return 0, time.Time{}, errors.Errorf("no on-chain value for request ID:%v", reqID)
+	}
+	val, _ := new(big.Float).SetInt(value).Float64()
+	return val / DefaultGranularity, time.Unix(timestamp.Int64(), 0), nil
 }
@@ -4,39 +4,91 @@
 package reward
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"math"
 	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/aggregator"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
 	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/logging"
 )
 
 const ComponentName = "reward"
 
+// slotsCount is how many submissions complete a challenge.
+const slotsCount = 5
+
+type Config struct {
+	LogLevel        string
+	File            string  `help:"File to persist the gas used per slot across restarts."`
+	Window          int     `help:"How many of the most recent gas used samples to keep per slot."`
+	Percentile      float64 `help:"The percentile of the recent gas used samples used for the profit calculation."`
+	BootstrapBlocks uint64  `help:"How many blocks to search for NonceSubmitted transactions when a slot has no gas used samples."`
+}
+
 type ContractCaller interface {
 	GetUintVar(opts *bind.CallOpts, _data [32]byte) (*big.Int, error)
 	CurrentReward(opts *bind.CallOpts) (*big.Int, error)
 }
 
-func New(logger log.Logger, aggr aggregator.IAggregator, contractCaller ContractCaller) *Reward {
-	return &Reward{
+type EventFilterer interface {
+	FilterNonceSubmitted(opts *bind.FilterOpts, _miner []common.Address, _currentChallenge [][32]byte) (*tellor.ITellorNonceSubmittedIterator, error)
+}
+
+type ChainReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+func New(logger log.Logger, cfg Config, aggr aggregator.IAggregator, contractCaller ContractCaller) (*Reward, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	if cfg.Window <= 0 {
+		return nil, errors.Errorf("window should be positive:%v", cfg.Window)
+	}
+	if cfg.Percentile <= 0 || cfg.Percentile > 100 {
+		return nil, errors.Errorf("percentile should be between 0 and 100:%v", cfg.Percentile)
+	}
+	reward := &Reward{
+		cfg:            cfg,
 		aggr:           aggr,
 		logger:         log.With(logger, "component", ComponentName),
 		contractCaller: contractCaller,
-		gasUsed:        make(map[int64]*big.Int),
+		gasUsed:        make(map[int64][]uint64),
+	}
+	if err := reward.load(); err != nil {
+		return nil, err
 	}
+	return reward, nil
 }
 
+// Reward keeps a rolling window of the gas used for every slot
+// and uses it to calculate the profit of a submit.
 type Reward struct {
 	logger         log.Logger
+	cfg            Config
 	aggr           aggregator.IAggregator
 	contractCaller ContractCaller
-	gasUsed        map[int64]*big.Int
+	mtx            sync.Mutex
+	gasUsed        map[int64][]uint64
 }
 
 // Current returns the profit in percents based on the current TRB price.
@@ -70,13 +122,44 @@ func (self *Reward) Current(slot *big.Int, gasPriceEth1e18 *big.Int) (int64, err
 	return profitPercent, nil
 }
 
-func (self *Reward) GasUsed(slot *big.Int) (*big.Int, error) {
-	if gas, ok := self.gasUsed[slot.Int64()]; ok {
-		return gas, nil
+// MaxGasPrice returns the max gas price for the next slot transaction
+// at which the current reward still makes at least the min profit percent.
+func (self *Reward) MaxGasPrice(minProfitPercent int64) (*big.Int, error) {
+	slot, err := self.NextSlot()
+	if err != nil {
+		return nil, err
+	}
+	gasUsed, err := self.GasUsed(slot)
+	if err != nil {
+		return nil, err
+	}
+	rewardEth1e18, err := self.rewardInEth1e18()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the reward")
 	}
 
-	return nil, ErrNoDataForSlot{slot: slot.String()}
+	// profit% = (reward - cost) / cost * 100 so cost = reward * 100 / (100 + profit%).
+	maxCost := new(big.Int).Mul(rewardEth1e18, big.NewInt(100))
+	maxCost.Div(maxCost, big.NewInt(100+minProfitPercent))
+	return maxCost.Div(maxCost, gasUsed), nil
+}
 
+// GasUsed returns the configured percentile of the recent gas used samples for the slot.
+func (self *Reward) GasUsed(slot *big.Int) (*big.Int, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	samples := self.gasUsed[slot.Int64()]
+	if len(samples) == 0 {
+		return nil, ErrNoDataForSlot{slot: slot.String()}
+	}
+	sorted := append([]uint64(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(math.Ceil(self.cfg.Percentile/100*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return big.NewInt(int64(sorted[idx])), nil
 }
 
 type ErrNoDataForSlot struct {
@@ -87,12 +170,165 @@ func (e ErrNoDataForSlot) Error() string {
 	return "no data for gas used for slot:" + e.slot
 }
 
-// SaveGasUsed calculates the price for a given slot.
-func (self *Reward) SaveGasUsed(slot *big.Int, _gasUsed uint64) {
-	gasUsed := big.NewInt(int64(_gasUsed))
+// SaveGasUsed adds a gas used sample for a given slot and persists all samples.
+func (self *Reward) SaveGasUsed(slot *big.Int, gasUsed uint64) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	self.add(slot.Int64(), gasUsed)
+	if err := self.write(); err != nil {
+		level.Error(self.logger).Log("msg", "persisting gas used", "err", err)
+	}
+	level.Info(self.logger).Log("msg", "saved transaction gas used", "amount", gasUsed, "slot", slot.Int64())
+}
+
+// add appends a sample and drops the ones outside of the window.
+// It should be called with the lock held.
+func (self *Reward) add(slot int64, gasUsed uint64) {
+	samples := append(self.gasUsed[slot], gasUsed)
+	if len(samples) > self.cfg.Window {
+		samples = samples[len(samples)-self.cfg.Window:]
+	}
+	self.gasUsed[slot] = samples
+}
+
+// Bootstrap fills the slots without any gas used samples from the receipts of
+// the NonceSubmitted transactions of recent challenges.
+// Only challenges with all submissions in the searched blocks are used
+// as otherwise the slot of a submission is unknown.
+func (self *Reward) Bootstrap(ctx context.Context, filterer EventFilterer, client ChainReader) error {
+	self.mtx.Lock()
+	var missing bool
+	for slot := int64(0); slot < slotsCount; slot++ {
+		if len(self.gasUsed[slot]) == 0 {
+			missing = true
+		}
+	}
+	self.mtx.Unlock()
+	if !missing {
+		return nil
+	}
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return errors.Wrap(err, "getting last block number")
+	}
+	var start uint64
+	if head > self.cfg.BootstrapBlocks {
+		start = head - self.cfg.BootstrapBlocks
+	}
+	iter, err := filterer.FilterNonceSubmitted(&bind.FilterOpts{Start: start, Context: ctx}, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "filter nonce submitted events")
+	}
+	defer iter.Close()
+
+	challenges := make(map[[32]byte][]types.Log)
+	for iter.Next() {
+		challenges[iter.Event.CurrentChallenge] = append(challenges[iter.Event.CurrentChallenge], iter.Event.Raw)
+	}
+	if err := iter.Error(); err != nil {
+		return errors.Wrap(err, "iterate nonce submitted events")
+	}
+
+	samples := make(map[int64][]uint64)
+	for _, logs := range challenges {
+		if len(logs) != slotsCount {
+			continue
+		}
+		sort.Slice(logs, func(i, j int) bool {
+			if logs[i].BlockNumber != logs[j].BlockNumber {
+				return logs[i].BlockNumber < logs[j].BlockNumber
+			}
+			return logs[i].Index < logs[j].Index
+		})
+		for slot, l := range logs {
+			receipt, err := client.TransactionReceipt(ctx, l.TxHash)
+			if err != nil {
+				return errors.Wrapf(err, "getting receipt tx:%v", l.TxHash)
+			}
+			samples[int64(slot)] = append(samples[int64(slot)], receipt.GasUsed)
+		}
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	for slot, gasUsed := range samples {
+		// Our own submits could have been added in the meantime.
+		if len(self.gasUsed[slot]) > 0 {
+			continue
+		}
+		for _, g := range gasUsed {
+			self.add(slot, g)
+		}
+		level.Info(self.logger).Log("msg", "bootstrapped gas used from chain history", "slot", slot, "samples", len(self.gasUsed[slot]))
+	}
+	return self.write()
+}
+
+// load reads the persisted samples if the file exists.
+func (self *Reward) load() error {
+	if self.cfg.File == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(self.cfg.File)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "read gas used file")
+	}
+	var samples map[string][]uint64
+	if err := json.Unmarshal(data, &samples); err != nil {
+		return errors.Wrapf(err, "parse gas used file:%v", self.cfg.File)
+	}
+	for slot, gasUsed := range samples {
+		s, err := strconv.ParseInt(slot, 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "parse slot:%v", slot)
+		}
+		for _, g := range gasUsed {
+			self.add(s, g)
+		}
+	}
+	return nil
+}
 
-	self.gasUsed[slot.Int64()] = gasUsed
-	level.Info(self.logger).Log("msg", "saved transaction gas used", "amount", gasUsed.Int64(), "slot", slot.Int64())
+// write replaces the persisted samples.
+// It should be called with the lock held.
+func (self *Reward) write() error {
+	if self.cfg.File == "" {
+		return nil
+	}
+	out := make(map[string][]uint64)
+	for slot, gasUsed := range self.gasUsed {
+		out[strconv.FormatInt(slot, 10)] = gasUsed
+	}
+	data, err := json.MarshalIndent(out, "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "marshal gas used")
+	}
+
+	// Write to a temp file and rename it so that
+	// a crash never leaves a partially written file.
+	tmp, err := ioutil.TempFile(filepath.Dir(self.cfg.File), filepath.Base(self.cfg.File))
+	if err != nil {
+		return errors.Wrap(err, "create temp gas used file")
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "write temp gas used file")
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "close temp gas used file")
+	}
+	if err := os.Rename(tmp.Name(), self.cfg.File); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "replace gas used file")
+	}
+	return nil
 }
 
 func (self *Reward) rewardInEth1e18() (*big.Int, error) {
@@ -121,6 +357,24 @@ func (self *Reward) rewardInEth1e18() (*big.Int, error) {
 	return big.NewInt(rewardEth1e18Int), nil
 }
 
+// NextSlot returns the slot of the next submitted solution.
+func (self *Reward) NextSlot() (*big.Int, error) {
+	slot, err := self.Slot()
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting current slot")
+	}
+
+	// Need the price for next slot transaction so increment by one.
+	slot.Add(slot, big.NewInt(1))
+
+	// Slots numbers are from 0 to 4 so
+	// when next slot is 4+1=5 get the price for slot 0.
+	if slot.Int64() == 5 {
+		slot.SetInt64(0)
+	}
+	return slot, nil
+}
+
 func (s *Reward) Slot() (*big.Int, error) {
 	slot, err := s.contractCaller.GetUintVar(nil, ethereum.Keccak256([]byte("_SLOT_PROGRESS")))
 	if err != nil {
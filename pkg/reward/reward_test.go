@@ -4,13 +4,25 @@
 package reward
 
 import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
 	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
 	"github.com/tellor-io/telliot/pkg/testutil"
 )
 
@@ -38,7 +50,8 @@ func TestProfitCalculation(t *testing.T) {
 
 	for _, rewardAmount := range []float64{5e17, 1e18, 2e18, 3e18} {
 		contractCaller := &MockContractCaler{trbRewardAmount: big.NewInt(int64(rewardAmount))}
-		reward := New(logger, aggregator, contractCaller)
+		reward, err := New(logger, Config{LogLevel: "info", Window: 1, Percentile: 50}, aggregator, contractCaller)
+		testutil.Ok(t, err)
 		reward.SaveGasUsed(slotNum, uint64(gasUsed))
 
 		rewardAct, err := reward.Current(slotNum, big.NewInt(int64(gasCost)))
@@ -49,6 +62,31 @@ func TestProfitCalculation(t *testing.T) {
 	}
 }
 
+func TestGasUsedDistribution(t *testing.T) {
+	cfg := Config{LogLevel: "info", File: filepath.Join(t.TempDir(), "gasUsed.json"), Window: 4, Percentile: 75}
+	reward, err := New(log.NewNopLogger(), cfg, &MockAggr{}, &MockContractCaler{})
+	testutil.Ok(t, err)
+
+	slot := big.NewInt(4)
+	_, err = reward.GasUsed(slot)
+	testutil.Assert(t, errors.As(err, &ErrNoDataForSlot{}), "expected no data error")
+
+	for _, g := range []uint64{900, 100, 200, 300, 400} {
+		reward.SaveGasUsed(slot, g)
+	}
+	// The first sample is outside of the window.
+	gasUsed, err := reward.GasUsed(slot)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(300), gasUsed.Int64())
+
+	// The samples are persisted across restarts.
+	reward, err = New(log.NewNopLogger(), cfg, &MockAggr{}, &MockContractCaler{})
+	testutil.Ok(t, err)
+	gasUsed, err = reward.GasUsed(slot)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(300), gasUsed.Int64())
+}
+
 type MockAggr struct {
 	TRBPrice float64
 }
@@ -68,3 +106,111 @@ func (*MockContractCaler) GetUintVar(opts *bind.CallOpts, _data [32]byte) (*big.
 func (self *MockContractCaler) CurrentReward(opts *bind.CallOpts) (*big.Int, error) {
 	return self.trbRewardAmount, nil
 }
+
+// fakeChain returns the NonceSubmitted logs and the gas used of their transactions.
+type fakeChain struct {
+	head    uint64
+	logs    []types.Log
+	gasUsed map[common.Hash]uint64
+	from    uint64
+}
+
+func (self *fakeChain) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	self.from = q.FromBlock.Uint64()
+	return self.logs, nil
+}
+
+func (self *fakeChain) SubscribeFilterLogs(context.Context, ethereum.FilterQuery, chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func (self *fakeChain) BlockNumber(context.Context) (uint64, error) {
+	return self.head, nil
+}
+
+func (self *fakeChain) TransactionReceipt(_ context.Context, hash common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{GasUsed: self.gasUsed[hash]}, nil
+}
+
+// submit adds a NonceSubmitted log for the challenge with the gas used by its transaction.
+func (self *fakeChain) submit(t *testing.T, challenge byte, block uint64, index uint, gasUsed uint64) {
+	contractABI, err := abi.JSON(strings.NewReader(tellor.ITellorABI))
+	testutil.Ok(t, err)
+	event := contractABI.Events["NonceSubmitted"]
+	var ids, values [5]*big.Int
+	for i := range ids {
+		ids[i], values[i] = big.NewInt(int64(i+1)), big.NewInt(100)
+	}
+	data, err := event.Inputs.NonIndexed().Pack("1", ids, values)
+	testutil.Ok(t, err)
+
+	txHash := common.BigToHash(big.NewInt(int64(len(self.logs) + 1)))
+	self.logs = append(self.logs, types.Log{
+		Topics:      []common.Hash{event.ID, common.BigToHash(big.NewInt(int64(index))), common.BytesToHash([]byte{challenge})},
+		Data:        data,
+		BlockNumber: block,
+		Index:       index,
+		TxHash:      txHash,
+	})
+	self.gasUsed[txHash] = gasUsed
+}
+
+func TestBootstrap(t *testing.T) {
+	cfg := Config{LogLevel: "info", File: filepath.Join(t.TempDir(), "gasUsed.json"), Window: 10, Percentile: 50, BootstrapBlocks: 100}
+	reward, err := New(log.NewNopLogger(), cfg, &MockAggr{}, &MockContractCaler{})
+	testutil.Ok(t, err)
+	// A slot with own submits isn't bootstrapped.
+	reward.SaveGasUsed(big.NewInt(4), 1)
+
+	chain := &fakeChain{head: 1000, gasUsed: make(map[common.Hash]uint64)}
+	// The slots are ordered by the log position and not by the order of the logs.
+	chain.submit(t, 1, 950, 3, 1003)
+	chain.submit(t, 1, 950, 1, 1001)
+	chain.submit(t, 1, 949, 0, 1000)
+	chain.submit(t, 1, 951, 0, 1004)
+	chain.submit(t, 1, 950, 2, 1002)
+	for slot := uint(0); slot < slotsCount; slot++ {
+		chain.submit(t, 2, 960, slot, 2000+uint64(slot))
+	}
+	// Incomplete challenges are skipped as the slots are unknown.
+	chain.submit(t, 3, 990, 0, 3000)
+	chain.submit(t, 3, 990, 1, 3001)
+
+	filterer, err := tellor.NewITellorFilterer(common.Address{}, chain)
+	testutil.Ok(t, err)
+	testutil.Ok(t, reward.Bootstrap(context.Background(), filterer, chain))
+	testutil.Equals(t, uint64(900), chain.from)
+
+	exp := map[int64][]uint64{
+		0: {1000, 2000},
+		1: {1001, 2001},
+		2: {1002, 2002},
+		3: {1003, 2003},
+		4: {1},
+	}
+	for _, samples := range reward.gasUsed {
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	}
+	testutil.Equals(t, exp, reward.gasUsed)
+
+	// The samples are persisted.
+	data, err := ioutil.ReadFile(cfg.File)
+	testutil.Ok(t, err)
+	var persisted map[string][]uint64
+	testutil.Ok(t, json.Unmarshal(data, &persisted))
+	for _, samples := range persisted {
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	}
+	testutil.Equals(t, map[string][]uint64{
+		"0": {1000, 2000},
+		"1": {1001, 2001},
+		"2": {1002, 2002},
+		"3": {1003, 2003},
+		"4": {1},
+	}, persisted)
+
+	// Nothing is searched when all slots have samples.
+	chain.from = 0
+	testutil.Ok(t, reward.Bootstrap(context.Background(), filterer, chain))
+	testutil.Equals(t, uint64(0), chain.from)
+}
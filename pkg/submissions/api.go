@@ -0,0 +1,85 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package submissions
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/route"
+)
+
+type response struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// Register adds the api endpoints for querying the ledger.
+func (self *Ledger) Register(r *route.Router) {
+	r.Get("/submissions", self.apiList)
+}
+
+// apiList returns the records matching the from, to(RFC3339), contract, account, decision and limit query parameters.
+func (self *Ledger) apiList(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		self.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	records, err := self.List(filter)
+	if err != nil {
+		self.respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	self.writeResponse(w, http.StatusOK, response{Status: "success", Data: records})
+}
+
+func parseFilter(r *http.Request) (Filter, error) {
+	q := r.URL.Query()
+	filter := Filter{
+		Contract: q.Get("contract"),
+		Account:  q.Get("account"),
+		Decision: q.Get("decision"),
+	}
+	var err error
+	if v := q.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, errors.Wrap(err, "parse from")
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, errors.Wrap(err, "parse to")
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return filter, errors.Wrap(err, "parse limit")
+		}
+	}
+	return filter, nil
+}
+
+func (self *Ledger) respondError(w http.ResponseWriter, code int, err error) {
+	self.writeResponse(w, code, response{Status: "error", Error: err.Error()})
+}
+
+func (self *Ledger) writeResponse(w http.ResponseWriter, code int, resp response) {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		level.Error(self.logger).Log("msg", "marshaling json response", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if _, err := w.Write(b); err != nil {
+		level.Error(self.logger).Log("msg", "writing response", "err", err)
+	}
+}
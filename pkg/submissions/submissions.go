@@ -0,0 +1,218 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package submissions
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/logging"
+)
+
+const ComponentName = "submissions"
+
+// Decisions recorded in the ledger.
+const (
+	DecisionReceived  = "received"
+	DecisionSkipped   = "skipped"
+	DecisionReverted  = "reverted"
+	DecisionFailed    = "failed"
+	DecisionSubmitted = "submitted"
+)
+
+type Config struct {
+	LogLevel string
+	File     string `help:"Append only ledger of all received solutions and submit decisions."`
+}
+
+// Value is a single value used in a submission.
+type Value struct {
+	ReqID int64 `json:"id"`
+	Value int64 `json:"value"`
+	// Method is how the PSR calculated the value,
+	// for example the default aggregation, a manual override or a fallback.
+	Method string `json:"method"`
+	// Symbol and Sources are the aggregated symbol and the index sources used for the value.
+	// Both are empty for the manual and onchain methods.
+	Symbol  string   `json:"symbol,omitempty"`
+	Sources []string `json:"sources,omitempty"`
+}
+
+// Record is a single decision made by a submitter.
+type Record struct {
+	Time     time.Time `json:"time"`
+	Contract string    `json:"contract"`
+	Account  string    `json:"account"`
+	Decision string    `json:"decision"`
+	Reason   string    `json:"reason,omitempty"`
+	// Shadow is true when the submitter runs in shadow mode and nothing was sent.
+	Shadow    bool    `json:"shadow,omitempty"`
+	Challenge string  `json:"challenge,omitempty"`
+	Solution  string  `json:"solution,omitempty"`
+	Values    []Value `json:"values,omitempty"`
+	TxHash    string  `json:"txHash,omitempty"`
+	// GasPrice is the effective gas price of a mined transaction
+	// and the gas price of a legacy transaction that wasn't mined.
+	GasPrice string `json:"gasPrice,omitempty"`
+	// MaxFee and PriorityFee are the fee cap and the tip of a dynamic fee transaction.
+	MaxFee      string `json:"maxFee,omitempty"`
+	PriorityFee string `json:"priorityFee,omitempty"`
+	// ReceiptStatus is set only when the transaction was mined.
+	ReceiptStatus *uint64 `json:"receiptStatus,omitempty"`
+	GasUsed       uint64  `json:"gasUsed,omitempty"`
+	Slot          *int64  `json:"slot,omitempty"`
+	// Received is when the submitter started processing the solution or value.
+	Received time.Time `json:"received"`
+}
+
+// SetTx sets the hash and the fees of the transaction.
+func (self *Record) SetTx(tx *types.Transaction) {
+	self.TxHash = tx.Hash().String()
+	if tx.Type() == types.DynamicFeeTxType {
+		self.MaxFee = tx.GasFeeCap().String()
+		self.PriorityFee = tx.GasTipCap().String()
+		return
+	}
+	self.GasPrice = tx.GasPrice().String()
+}
+
+// SetReceipt sets the result of the mined transaction and the gas price it paid.
+// The gas price is nil when it is unknown.
+func (self *Record) SetReceipt(receipt *types.Receipt, gasPrice *big.Int) {
+	status := receipt.Status
+	self.ReceiptStatus = &status
+	self.GasUsed = receipt.GasUsed
+	if gasPrice != nil {
+		self.GasPrice = gasPrice.String()
+	}
+}
+
+// Filter selects records from the ledger.
+// Zero values match all records.
+type Filter struct {
+	From     time.Time
+	To       time.Time
+	Contract string
+	Account  string
+	Decision string
+	// Limit returns only the most recent records.
+	Limit int
+}
+
+func (self Filter) match(r Record) bool {
+	if !self.From.IsZero() && r.Time.Before(self.From) {
+		return false
+	}
+	if !self.To.IsZero() && r.Time.After(self.To) {
+		return false
+	}
+	if self.Contract != "" && r.Contract != self.Contract {
+		return false
+	}
+	if self.Account != "" && r.Account != self.Account {
+		return false
+	}
+	if self.Decision != "" && r.Decision != self.Decision {
+		return false
+	}
+	return true
+}
+
+// Ledger keeps a persistent history of all submitter decisions
+// in an append only file with one json record per line.
+type Ledger struct {
+	logger log.Logger
+	cfg    Config
+	mtx    sync.Mutex
+}
+
+func New(logger log.Logger, cfg Config) (*Ledger, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	if cfg.File == "" {
+		return nil, errors.New("missing ledger file")
+	}
+	return &Ledger{
+		logger: log.With(logger, "component", ComponentName),
+		cfg:    cfg,
+	}, nil
+}
+
+// Add appends a record to the ledger.
+func (self *Ledger) Add(record Record) (err error) {
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "marshal record")
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	f, err := os.OpenFile(self.cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "open ledger")
+	}
+	defer func() {
+		if errC := f.Close(); errC != nil && err == nil {
+			err = errors.Wrap(errC, "close ledger")
+		}
+	}()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return errors.Wrap(err, "write record")
+	}
+	level.Debug(self.logger).Log("msg", "added record", "contract", record.Contract, "decision", record.Decision, "reason", record.Reason)
+	return nil
+}
+
+// List returns the records matching the filter in the order they were added.
+func (self *Ledger) List(filter Filter) ([]Record, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	f, err := os.Open(self.cfg.File)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "open ledger")
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, errors.Wrapf(err, "parse ledger line:%v", line)
+		}
+		if !filter.match(record) {
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "read ledger")
+	}
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+	return records, nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package submissions
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/common/route"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestLedger(t *testing.T) {
+	ledger, err := New(log.NewNopLogger(), Config{LogLevel: "info", File: filepath.Join(t.TempDir(), "submissions.log")})
+	testutil.Ok(t, err)
+
+	records, err := ledger.List(Filter{})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(records))
+
+	start := time.Date(2021, 7, 16, 12, 0, 0, 0, time.UTC)
+	status := uint64(1)
+	slot := int64(3)
+	for _, r := range []Record{
+		{Time: start, Contract: "tellor", Account: "a", Decision: DecisionReceived, Challenge: "aa"},
+		{Time: start.Add(time.Minute), Contract: "tellor", Account: "a", Decision: DecisionSkipped, Reason: "profit too low"},
+		{Time: start.Add(2 * time.Minute), Contract: "tellor", Account: "a", Decision: DecisionSubmitted, Values: []Value{{ReqID: 1, Value: 2000e6, Method: "default"}}, TxHash: "0x1", ReceiptStatus: &status, Slot: &slot},
+		{Time: start.Add(3 * time.Minute), Contract: "tellorMesosphere", Account: "b", Decision: DecisionFailed, Reason: "timeout"},
+	} {
+		testutil.Ok(t, ledger.Add(r))
+	}
+
+	records, err = ledger.List(Filter{Contract: "tellor"})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(records))
+	testutil.Equals(t, int64(2000e6), records[2].Values[0].Value)
+	testutil.Equals(t, status, *records[2].ReceiptStatus)
+
+	records, err = ledger.List(Filter{From: start.Add(time.Minute), Limit: 2})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(records))
+	testutil.Equals(t, DecisionSubmitted, records[0].Decision)
+	testutil.Equals(t, DecisionFailed, records[1].Decision)
+
+	router := route.New()
+	ledger.Register(router)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/submissions?decision=skipped", nil))
+	testutil.Equals(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Data []Record `json:"data"`
+	}
+	testutil.Ok(t, json.NewDecoder(rec.Body).Decode(&resp))
+	testutil.Equals(t, 1, len(resp.Data))
+	testutil.Equals(t, "profit too low", resp.Data[0].Reason)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/submissions?from=yesterday", nil))
+	testutil.Equals(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRecordTx(t *testing.T) {
+	// A dynamic fee transaction records the fee cap and the tip
+	// and the price paid only when it is mined.
+	var r Record
+	r.SetTx(types.NewTx(&types.DynamicFeeTx{GasFeeCap: big.NewInt(300), GasTipCap: big.NewInt(2)}))
+	testutil.Equals(t, "300", r.MaxFee)
+	testutil.Equals(t, "2", r.PriorityFee)
+	testutil.Equals(t, "", r.GasPrice)
+	r.SetReceipt(&types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 100}, big.NewInt(102))
+	testutil.Equals(t, "102", r.GasPrice)
+	testutil.Equals(t, uint64(100), r.GasUsed)
+
+	r = Record{}
+	r.SetTx(types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(50)}))
+	testutil.Equals(t, "50", r.GasPrice)
+	testutil.Equals(t, "", r.MaxFee)
+}
@@ -0,0 +1,207 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package guard
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	mathU "github.com/tellor-io/telliot/pkg/math"
+)
+
+const ComponentName = "guard"
+
+// Sources of the reference values.
+const (
+	SourceOnChain = "onchain"
+	SourceMiners  = "miners"
+)
+
+var deviations = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "telliot",
+	Subsystem: ComponentName,
+	Name:      "deviations_total",
+	Help:      "The total number of values that deviated from the network more than the threshold",
+},
+	[]string{"id", "source"},
+)
+
+type Config struct {
+	Enabled        bool
+	AlertOnly      bool              `help:"Only log and count the deviations in the telliot_guard_deviations_total metric without refusing to submit."`
+	MaxDeviation   float64           `help:"Max percent deviation from the last accepted on-chain value and from the median of the other miners' values for the current challenge. 0 disables the check."`
+	MaxDeviations  map[int64]float64 `help:"Max percent deviation per request ID which overrides the default MaxDeviation."`
+	LookBackBlocks uint64            `help:"How many blocks to search for the other miners' submissions for the current challenge."`
+}
+
+type ContractCaller interface {
+	GetLastNewValueById(opts *bind.CallOpts, _requestId *big.Int) (*big.Int, bool, error)
+	FilterNonceSubmitted(opts *bind.FilterOpts, _miner []common.Address, _currentChallenge [][32]byte) (*tellor.ITellorNonceSubmittedIterator, error)
+}
+
+type BlockNumberer interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// ErrDeviation is returned when a value deviates from the network more than the threshold.
+type ErrDeviation struct {
+	ReqID     int64
+	Value     int64
+	Reference float64
+	Source    string
+	Deviation float64
+	Threshold float64
+}
+
+func (self ErrDeviation) Error() string {
+	return fmt.Sprintf("value deviates from the %v value id:%v, value:%v, reference:%v, deviation:%.2f%%, threshold:%v%%",
+		self.Source, self.ReqID, self.Value, self.Reference, self.Deviation, self.Threshold)
+}
+
+// Guard protects the stake by comparing the values about to be submitted
+// with the values the rest of the network reports.
+type Guard struct {
+	logger   log.Logger
+	cfg      Config
+	contract ContractCaller
+	client   BlockNumberer
+	account  common.Address
+}
+
+func New(logger log.Logger, cfg Config, contract ContractCaller, client BlockNumberer, account common.Address) *Guard {
+	return &Guard{
+		logger:   log.With(logger, "component", ComponentName),
+		cfg:      cfg,
+		contract: contract,
+		client:   client,
+		account:  account,
+	}
+}
+
+// Check compares the values with the last accepted on-chain values and
+// with the median of the values submitted by the other miners for the same challenge.
+// In alert only mode all deviations are only logged and it never returns an error.
+func (self *Guard) Check(ctx context.Context, challenge [32]byte, ids [5]*big.Int, vals [5]*big.Int) error {
+	err := self.check(ctx, challenge, ids, vals)
+	if err != nil && self.cfg.AlertOnly {
+		level.Warn(self.logger).Log("msg", "guard check failed, submitting anyway in alert only mode", "err", err)
+		return nil
+	}
+	return err
+}
+
+func (self *Guard) check(ctx context.Context, challenge [32]byte, ids [5]*big.Int, vals [5]*big.Int) error {
+	miners, err := self.minersValues(ctx, challenge)
+	if err != nil {
+		return errors.Wrap(err, "getting the other miners' values")
+	}
+
+	var errs []error
+	for i, id := range ids {
+		threshold := self.threshold(id.Int64())
+		if threshold <= 0 {
+			continue
+		}
+
+		last, ok, err := self.contract.GetLastNewValueById(&bind.CallOpts{Context: ctx}, id)
+		if err != nil {
+			return errors.Wrapf(err, "getting last on-chain value id:%v", id)
+		}
+		if ok && last.Sign() > 0 {
+			if err := self.compare(id.Int64(), vals[i], float64(last.Int64()), SourceOnChain, threshold); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if len(miners[i]) > 0 {
+			if err := self.compare(id.Int64(), vals[i], median(miners[i]), SourceMiners, threshold); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		for _, err := range errs[1:] {
+			level.Warn(self.logger).Log("msg", "value deviation", "err", err)
+		}
+		return errs[0]
+	}
+	return nil
+}
+
+func (self *Guard) compare(reqID int64, val *big.Int, reference float64, source string, threshold float64) error {
+	deviation := math.Abs(mathU.PercentageDiff(reference, float64(val.Int64())))
+	level.Debug(self.logger).Log("msg", "guard check", "id", reqID, "value", val, "reference", reference, "source", source, "deviation", deviation)
+	if deviation <= threshold {
+		return nil
+	}
+	deviations.With(prometheus.Labels{"id": strconv.FormatInt(reqID, 10), "source": source}).Inc()
+	return ErrDeviation{
+		ReqID:     reqID,
+		Value:     val.Int64(),
+		Reference: reference,
+		Source:    source,
+		Deviation: deviation,
+		Threshold: threshold,
+	}
+}
+
+func (self *Guard) threshold(reqID int64) float64 {
+	if t, ok := self.cfg.MaxDeviations[reqID]; ok {
+		return t
+	}
+	return self.cfg.MaxDeviation
+}
+
+// minersValues returns the values submitted by the other miners for the challenge
+// grouped by the position of the request ID in the challenge.
+func (self *Guard) minersValues(ctx context.Context, challenge [32]byte) ([5][]float64, error) {
+	var values [5][]float64
+	head, err := self.client.BlockNumber(ctx)
+	if err != nil {
+		return values, errors.Wrap(err, "getting last block number")
+	}
+	var start uint64
+	if head > self.cfg.LookBackBlocks {
+		start = head - self.cfg.LookBackBlocks
+	}
+	iter, err := self.contract.FilterNonceSubmitted(&bind.FilterOpts{Start: start, Context: ctx}, nil, [][32]byte{challenge})
+	if err != nil {
+		return values, errors.Wrap(err, "filter nonce submitted events")
+	}
+	defer iter.Close()
+	for iter.Next() {
+		if iter.Event.Miner == self.account {
+			continue
+		}
+		for i, val := range iter.Event.Value {
+			values[i] = append(values[i], float64(val.Int64()))
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return values, errors.Wrap(err, "iterate nonce submitted events")
+	}
+	return values, nil
+}
+
+func median(vals []float64) float64 {
+	sorted := append([]float64(nil), vals...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
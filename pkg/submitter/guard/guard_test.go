@@ -0,0 +1,185 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package guard
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	promTestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestCompare(t *testing.T) {
+	g := New(log.NewNopLogger(), Config{
+		Enabled:       true,
+		MaxDeviation:  10,
+		MaxDeviations: map[int64]float64{2: 1},
+	}, nil, nil, common.Address{})
+
+	testutil.Equals(t, float64(10), g.threshold(1))
+	testutil.Equals(t, float64(1), g.threshold(2))
+
+	testutil.Ok(t, g.compare(1, big.NewInt(2050e6), 2000e6, SourceOnChain, g.threshold(1)))
+
+	err := g.compare(2, big.NewInt(2050e6), 2000e6, SourceMiners, g.threshold(2))
+	var errD ErrDeviation
+	testutil.Assert(t, errors.As(err, &errD), "expected a deviation error")
+	testutil.Equals(t, SourceMiners, errD.Source)
+	testutil.Equals(t, int64(2), errD.ReqID)
+
+	testutil.Equals(t, float64(2), median([]float64{3, 1, 2}))
+	testutil.Equals(t, float64(2.5), median([]float64{4, 1, 2, 3}))
+}
+
+// fakeContract has the last on-chain values and the NonceSubmitted logs of the miners.
+type fakeContract struct {
+	*tellor.ITellorFilterer
+	head uint64
+	last map[int64]int64
+	logs []types.Log
+	from uint64
+}
+
+func newFakeContract(t *testing.T) *fakeContract {
+	contract := &fakeContract{last: make(map[int64]int64)}
+	filterer, err := tellor.NewITellorFilterer(common.Address{}, contract)
+	testutil.Ok(t, err)
+	contract.ITellorFilterer = filterer
+	return contract
+}
+
+func (self *fakeContract) GetLastNewValueById(_ *bind.CallOpts, id *big.Int) (*big.Int, bool, error) {
+	v, ok := self.last[id.Int64()]
+	return big.NewInt(v), ok, nil
+}
+
+func (self *fakeContract) BlockNumber(context.Context) (uint64, error) {
+	return self.head, nil
+}
+
+func (self *fakeContract) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	self.from = q.FromBlock.Uint64()
+	var logs []types.Log
+	for _, l := range self.logs {
+		if matchTopics(l, q.Topics) {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func matchTopics(l types.Log, topics [][]common.Hash) bool {
+	for i, rule := range topics {
+		if len(rule) == 0 {
+			continue
+		}
+		match := false
+		for _, topic := range rule {
+			match = match || l.Topics[i] == topic
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+func (self *fakeContract) SubscribeFilterLogs(context.Context, ethereum.FilterQuery, chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+// submit adds the NonceSubmitted log of a miner for the challenge.
+func (self *fakeContract) submit(t *testing.T, miner common.Address, challenge [32]byte, values [5]int64) {
+	contractABI, err := abi.JSON(strings.NewReader(tellor.ITellorABI))
+	testutil.Ok(t, err)
+	event := contractABI.Events["NonceSubmitted"]
+	var ids, vals [5]*big.Int
+	for i := range ids {
+		ids[i], vals[i] = big.NewInt(int64(i+1)), big.NewInt(values[i])
+	}
+	data, err := event.Inputs.NonIndexed().Pack("1", ids, vals)
+	testutil.Ok(t, err)
+	self.logs = append(self.logs, types.Log{
+		Topics: []common.Hash{event.ID, common.BytesToHash(miner.Bytes()), challenge},
+		Data:   data,
+	})
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	account := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	challenge, other := common.HexToHash("0x01"), common.HexToHash("0x02")
+
+	contract := newFakeContract(t)
+	contract.head = 1000
+	// ID 2 has no on-chain value yet.
+	contract.last = map[int64]int64{1: 1000, 3: 1000, 4: 1000, 5: 1000}
+	for i, miner := range []string{"0x01", "0x02", "0x03"} {
+		contract.submit(t, common.HexToAddress(miner), challenge, [5]int64{1200, []int64{1000, 1010, 3000}[i], 1000, 1000, 1000})
+	}
+	// The own submission and the submissions for other challenges are ignored.
+	contract.submit(t, account, challenge, [5]int64{1, 99999, 1, 1, 1})
+	contract.submit(t, common.HexToAddress("0x04"), other, [5]int64{1, 99999, 1, 1, 1})
+
+	cfg := Config{
+		Enabled:        true,
+		MaxDeviation:   10,
+		MaxDeviations:  map[int64]float64{3: 1, 5: 0},
+		LookBackBlocks: 100,
+	}
+	g := New(log.NewNopLogger(), cfg, contract, contract, account)
+	ids := [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)}
+	check := func(vals [5]int64) error {
+		var v [5]*big.Int
+		for i := range v {
+			v[i] = big.NewInt(vals[i])
+		}
+		return g.Check(ctx, challenge, ids, v)
+	}
+	// ID 5 isn't checked as the threshold is 0.
+	testutil.Ok(t, check([5]int64{1100, 1010, 1000, 1000, 5000}))
+	testutil.Equals(t, uint64(900), contract.from)
+
+	for _, tc := range []struct {
+		name      string
+		vals      [5]int64
+		id        int64
+		source    string
+		threshold float64
+	}{
+		{"on-chain", [5]int64{1300, 1010, 1000, 1000, 1000}, 1, SourceOnChain, 10},
+		{"other miners", [5]int64{950, 1010, 1000, 1000, 1000}, 1, SourceMiners, 10},
+		{"other miners without an on-chain value", [5]int64{1100, 2000, 1000, 1000, 1000}, 2, SourceMiners, 10},
+		{"per ID threshold", [5]int64{1100, 1010, 1050, 1000, 1000}, 3, SourceOnChain, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			count := promTestutil.ToFloat64(deviations.WithLabelValues(strconv.FormatInt(tc.id, 10), tc.source))
+			err := check(tc.vals)
+			var errD ErrDeviation
+			testutil.Assert(t, errors.As(err, &errD), "expected a deviation error:%v", err)
+			testutil.Equals(t, tc.id, errD.ReqID)
+			testutil.Equals(t, tc.source, errD.Source)
+			testutil.Equals(t, tc.threshold, errD.Threshold)
+			testutil.Equals(t, count+1, promTestutil.ToFloat64(deviations.WithLabelValues(strconv.FormatInt(tc.id, 10), tc.source)))
+
+			// In alert only mode the deviation is only counted.
+			g.cfg.AlertOnly = true
+			defer func() { g.cfg.AlertOnly = false }()
+			testutil.Ok(t, check(tc.vals))
+			testutil.Equals(t, count+2, promTestutil.ToFloat64(deviations.WithLabelValues(strconv.FormatInt(tc.id, 10), tc.source)))
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package shadow
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/prometheus/prometheus/storage"
+)
+
+const ComponentName = "shadow"
+
+const (
+	DecisionMetricName = "shadow_decision"
+	ValueMetricName    = "shadow_value"
+	GasPriceMetricName = "shadow_gas_price"
+)
+
+// Decisions recorded for every submit attempt.
+const (
+	DecisionSubmit = "submit"
+	DecisionSkip   = "skip"
+	DecisionFailed = "failed"
+)
+
+// Recorder records what a submitter would have done when running in shadow mode.
+// Every decision is recorded as a sample of 1 in the decision series and
+// every transaction that would have been sent is recorded with its values and gas price
+// so that these can be compared with the values of a live instance.
+// When the DB is nil the decisions are only logged.
+type Recorder struct {
+	logger   log.Logger
+	ctx      context.Context
+	tsDB     storage.Appendable
+	contract string
+	account  string
+}
+
+func New(logger log.Logger, ctx context.Context, tsDB storage.Appendable, contract string, account common.Address) *Recorder {
+	return &Recorder{
+		logger:   log.With(logger, "component", ComponentName),
+		ctx:      ctx,
+		tsDB:     tsDB,
+		contract: contract,
+		account:  account.String(),
+	}
+}
+
+// Decision records a decision that didn't result in a transaction.
+func (self *Recorder) Decision(decision string, reason error) error {
+	level.Info(self.logger).Log("msg", "shadow decision", "decision", decision, "reason", reason)
+	return self.append(time.Now(), decision, nil, nil, nil)
+}
+
+// Submit logs the transaction that would have been sent and records the submitted values.
+func (self *Recorder) Submit(tx *types.Transaction, ids []*big.Int, vals []*big.Int) error {
+	level.Info(self.logger).Log("msg", "shadow submit, transaction not sent",
+		"txHash", tx.Hash().String(),
+		"nonce", tx.Nonce(),
+		"gasPrice", tx.GasPrice(),
+		"gasLimit", tx.Gas(),
+		"to", tx.To().String(),
+		"IDs", fmt.Sprintf("%+v", ids),
+		"vals", fmt.Sprintf("%+v", vals),
+		"data", fmt.Sprintf("%x", tx.Data()),
+	)
+	return self.append(time.Now(), DecisionSubmit, ids, vals, tx.GasPrice())
+}
+
+func (self *Recorder) append(now time.Time, decision string, ids []*big.Int, vals []*big.Int, gasPrice *big.Int) (err error) {
+	if self.tsDB == nil {
+		return nil
+	}
+	if len(ids) != len(vals) {
+		return errors.Errorf("ids and values count mismatch ids:%v, vals:%v", len(ids), len(vals))
+	}
+
+	appender := self.tsDB.Appender(self.ctx)
+	ts := timestamp.FromTime(now)
+
+	defer func() { // An appender always needs to be committed or rolled back.
+		if err != nil {
+			if err := appender.Rollback(); err != nil {
+				level.Error(self.logger).Log("msg", "db rollback failed", "err", err)
+			}
+			return
+		}
+		if errC := appender.Commit(); errC != nil {
+			err = errors.Wrap(errC, "db append commit failed")
+		}
+	}()
+
+	add := func(val float64, lbls ...labels.Label) error {
+		lbls = append(lbls,
+			labels.Label{Name: "contract", Value: self.contract},
+			labels.Label{Name: "account", Value: self.account},
+		)
+		sort.Sort(labels.Labels(lbls)) // This is important! The labels need to be sorted to avoid creating the same series with duplicate reference.
+		if _, err := appender.Append(0, lbls, ts, val); err != nil {
+			return errors.Wrap(err, "append values to the DB")
+		}
+		return nil
+	}
+
+	if err := add(1,
+		labels.Label{Name: "__name__", Value: DecisionMetricName},
+		labels.Label{Name: "decision", Value: decision},
+	); err != nil {
+		return err
+	}
+	for i, id := range ids {
+		if err := add(float64(vals[i].Int64()),
+			labels.Label{Name: "__name__", Value: ValueMetricName},
+			labels.Label{Name: "id", Value: id.String()},
+		); err != nil {
+			return err
+		}
+	}
+	if gasPrice != nil {
+		if err := add(float64(gasPrice.Int64()),
+			labels.Label{Name: "__name__", Value: GasPriceMetricName},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package shadow
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	db, err := tsdb.Open(t.TempDir(), nil, nil, tsdb.DefaultOptions())
+	testutil.Ok(t, err)
+	defer func() { testutil.Ok(t, db.Close()) }()
+
+	account := common.HexToAddress("0x1")
+	recorder := New(log.NewNopLogger(), ctx, db, "tellor", account)
+
+	testutil.Ok(t, recorder.Decision(DecisionSkip, errors.New("profit too low")))
+	tx := types.NewTransaction(1, common.HexToAddress("0x2"), big.NewInt(0), 3000000, big.NewInt(20), nil)
+	testutil.Ok(t, recorder.Submit(tx, []*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(2000e6), big.NewInt(40000e6)}))
+
+	querier, err := db.Querier(ctx, math.MinInt64, math.MaxInt64)
+	testutil.Ok(t, err)
+	defer querier.Close()
+
+	count := func(name string, matchers ...*labels.Matcher) int {
+		matchers = append(matchers,
+			labels.MustNewMatcher(labels.MatchEqual, "__name__", name),
+			labels.MustNewMatcher(labels.MatchEqual, "account", account.String()),
+			labels.MustNewMatcher(labels.MatchEqual, "contract", "tellor"),
+		)
+		set := querier.Select(false, nil, matchers...)
+		var n int
+		for set.Next() {
+			n++
+		}
+		testutil.Ok(t, set.Err())
+		return n
+	}
+	testutil.Equals(t, 1, count(DecisionMetricName, labels.MustNewMatcher(labels.MatchEqual, "decision", DecisionSkip)))
+	testutil.Equals(t, 1, count(DecisionMetricName, labels.MustNewMatcher(labels.MatchEqual, "decision", DecisionSubmit)))
+	testutil.Equals(t, 2, count(ValueMetricName))
+	testutil.Equals(t, 1, count(GasPriceMetricName))
+
+	// Without a DB the decisions are only logged.
+	testutil.Ok(t, New(log.NewNopLogger(), ctx, nil, "tellor", account).Decision(DecisionFailed, errors.New("no value")))
+}
@@ -13,12 +13,12 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/format"
 	"github.com/tellor-io/telliot/pkg/gasPrice"
@@ -26,6 +26,9 @@ import (
 	"github.com/tellor-io/telliot/pkg/mining"
 	psr "github.com/tellor-io/telliot/pkg/psr/tellor"
 	"github.com/tellor-io/telliot/pkg/reward"
+	"github.com/tellor-io/telliot/pkg/submissions"
+	"github.com/tellor-io/telliot/pkg/submitter/guard"
+	"github.com/tellor-io/telliot/pkg/submitter/shadow"
 	"github.com/tellor-io/telliot/pkg/transactor"
 )
 
@@ -35,13 +38,17 @@ type ContractCaller interface {
 	GetUintVar(opts *bind.CallOpts, _data [32]byte) (*big.Int, error)
 	SubmitMiningSolution(opts *bind.TransactOpts, _nonce string, _requestId [5]*big.Int, _value [5]*big.Int) (*types.Transaction, error)
 	GetStakerInfo(opts *bind.CallOpts, _staker common.Address) (*big.Int, *big.Int, error)
+	guard.ContractCaller
 }
 
 type Config struct {
 	Enabled         bool
 	LogLevel        string
-	ProfitThreshold uint64          `help:"Minimum percent of profit when submitting a solution. For example if the tx cost is 0.01 ETH and current reward is 0.02 ETH a ProfitThreshold of 200% or more will wait until the reward is increased or the gas cost is lowered a ProfitThreshold of 199% or less will submit."`
-	MinSubmitPeriod format.Duration `help:"The time limit between each submit for a staked miner."`
+	ProfitThreshold uint64                `help:"Minimum percent of profit when submitting a solution. For example if the tx cost is 0.01 ETH and current reward is 0.02 ETH a ProfitThreshold of 200% or more will wait until the reward is increased or the gas cost is lowered a ProfitThreshold of 199% or less will submit."`
+	MinSubmitPeriod format.Duration       `help:"The time limit between each submit for a staked miner."`
+	Shadow          bool                  `help:"Run the full submit pipeline without sending any transactions. The transactions that would have been sent are logged and the decisions and values are recorded in the DB."`
+	Guard           guard.Config          `help:"Refuse to submit values that deviate too much from the values reported by the rest of the network."`
+	Bump            transactor.BumpConfig `help:"How to increase the fees of the retried send attempts."`
 }
 
 /**
@@ -56,7 +63,7 @@ type Submitter struct {
 	logger          log.Logger
 	cfg             Config
 	account         *ethereum.Account
-	client          *ethclient.Client
+	client          *ethereum.Client
 	contract        ContractCaller
 	resultCh        chan *mining.Result
 	submitCount     prometheus.Counter
@@ -67,19 +74,24 @@ type Submitter struct {
 	reward          *reward.Reward
 	gasPriceQuerier gasPrice.GasPriceQuerier
 	psr             *psr.Psr
+	shadow          *shadow.Recorder
+	ledger          *submissions.Ledger
+	guard           *guard.Guard
 }
 
 func New(
 	ctx context.Context,
 	logger log.Logger,
 	cfg Config,
-	client *ethclient.Client,
+	client *ethereum.Client,
 	contract ContractCaller,
 	account *ethereum.Account,
 	reward *reward.Reward,
 	transactor transactor.Transactor,
 	gasPriceQuerier gasPrice.GasPriceQuerier,
 	psr *psr.Psr,
+	tsDB storage.Appendable,
+	ledger *submissions.Ledger,
 ) (*Submitter, chan *mining.Result, error) {
 	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
 	if err != nil {
@@ -100,6 +112,7 @@ func New(
 		transactor:      transactor,
 		gasPriceQuerier: gasPriceQuerier,
 		psr:             psr,
+		ledger:          ledger,
 		submitCount: promauto.NewCounter(prometheus.CounterOpts{
 			Namespace:   "telliot",
 			Subsystem:   ComponentName,
@@ -125,6 +138,14 @@ func New(
 		),
 	}
 
+	if cfg.Guard.Enabled {
+		submitter.guard = guard.New(logger, cfg.Guard, contract, client, account.Address)
+	}
+	if cfg.Shadow {
+		level.Warn(logger).Log("msg", "running in shadow mode, no transactions will be sent")
+		submitter.shadow = shadow.New(logger, ctx, tsDB, "tellor", account.Address)
+	}
+
 	return submitter, submitter.resultCh, nil
 }
 
@@ -160,7 +181,7 @@ func (self *Submitter) Stop() {
 	self.close()
 }
 
-func (self *Submitter) blockUntilTimeToSubmit(newChallengeReplace context.Context) {
+func (self *Submitter) blockUntilTimeToSubmit(newChallengeReplace context.Context, record submissions.Record) {
 	var (
 		lastSubmit time.Duration
 		timestamp  *time.Time
@@ -187,6 +208,7 @@ func (self *Submitter) blockUntilTimeToSubmit(newChallengeReplace context.Contex
 			"lastSubmitTimestamp", timestamp.Format("2006-01-02 15:04:05.000000"),
 			"minSubmitPeriod", self.cfg.MinSubmitPeriod,
 		)
+		self.record(record, submissions.DecisionSkipped, errors.Errorf("min submit period hasn't passed, waiting:%v", time.Duration(self.cfg.MinSubmitPeriod.Nanoseconds())-lastSubmit))
 		timeToSubmit, cncl := context.WithDeadline(newChallengeReplace, timestamp.Add(self.cfg.MinSubmitPeriod.Duration))
 		defer cncl()
 		select {
@@ -221,24 +243,15 @@ func (self *Submitter) canSubmit() error {
 }
 
 func (self *Submitter) profitPercent() (int64, error) {
-	slot, err := self.reward.Slot()
+	slot, err := self.reward.NextSlot()
 	if err != nil {
-		return 0, errors.Wrapf(err, "getting current slot")
+		return 0, err
 	}
 	gasPrice, err := self.gasPriceQuerier.Query(self.ctx)
 	if err != nil {
 		return 0, errors.Wrapf(err, "getting current Gas price")
 	}
 
-	// Need the price for next slot transaction so increment by one.
-	slot.Add(slot, big.NewInt(1))
-
-	// Slots numbers are from 0 to 4 so
-	// when next slot is 4+1=5 get the price for slot 0.
-	if slot.Int64() == 5 {
-		slot.SetInt64(0)
-	}
-
 	return self.reward.Current(slot, gasPrice)
 }
 
@@ -246,6 +259,14 @@ func (self *Submitter) Submit(newChallengeReplace context.Context, result *minin
 	go func(newChallengeReplace context.Context, result *mining.Result) {
 		ticker := time.NewTicker(30 * time.Second)
 		defer ticker.Stop()
+
+		base := submissions.Record{
+			Challenge: fmt.Sprintf("%x", result.Work.Challenge.Challenge),
+			Solution:  result.Nonce,
+			Received:  time.Now(),
+		}
+		self.record(base, submissions.DecisionReceived, nil)
+
 		for {
 			select {
 			case <-newChallengeReplace.Done():
@@ -254,9 +275,11 @@ func (self *Submitter) Submit(newChallengeReplace context.Context, result *minin
 			default:
 			}
 
-			self.blockUntilTimeToSubmit(newChallengeReplace)
+			self.blockUntilTimeToSubmit(newChallengeReplace, base)
 			if err := self.canSubmit(); err != nil {
 				level.Info(self.logger).Log("msg", "can't submit and will retry later", "reason", err)
+				self.recordShadowDecision(shadow.DecisionSkip, err)
+				self.record(base, submissions.DecisionSkipped, err)
 				<-ticker.C
 				continue
 			}
@@ -268,9 +291,11 @@ func (self *Submitter) Submit(newChallengeReplace context.Context, result *minin
 				default:
 				}
 
-				reqVals, err := self.requestVals(result.Work.Challenge.RequestIDs)
+				reqVals, values, err := self.requestVals(result.Work.Challenge.RequestIDs)
 				if err != nil {
 					level.Error(self.logger).Log("msg", "adding the request ids, retrying", "err", err)
+					self.recordShadowDecision(shadow.DecisionFailed, err)
+					self.record(base, submissions.DecisionSkipped, err)
 					<-ticker.C
 					continue
 				}
@@ -283,6 +308,23 @@ func (self *Submitter) Submit(newChallengeReplace context.Context, result *minin
 				f := func(auth *bind.TransactOpts) (*types.Transaction, error) {
 					return self.contract.SubmitMiningSolution(auth, result.Nonce, result.Work.Challenge.RequestIDs, reqVals)
 				}
+				base.Values = values
+
+				if self.cfg.Guard.Enabled {
+					var challenge [32]byte
+					copy(challenge[:], result.Work.Challenge.Challenge)
+					if err := self.guard.Check(newChallengeReplace, challenge, result.Work.Challenge.RequestIDs, reqVals); err != nil {
+						level.Error(self.logger).Log("msg", "guard refused to submit the values, retrying", "err", err)
+						self.recordShadowDecision(shadow.DecisionSkip, err)
+						self.record(base, submissions.DecisionSkipped, err)
+						<-ticker.C
+						continue
+					}
+				}
+				if self.cfg.Shadow {
+					self.shadowSubmit(newChallengeReplace, f, base, result.Work.Challenge.RequestIDs, reqVals)
+					return
+				}
 				tx, recieipt, err := self.transactor.Transact(newChallengeReplace, f)
 				select {
 				case <-newChallengeReplace.Done():
@@ -291,14 +333,39 @@ func (self *Submitter) Submit(newChallengeReplace context.Context, result *minin
 				default:
 				}
 				if err != nil {
+					var errR transactor.ErrReverted
+					if errors.As(err, &errR) {
+						self.record(base, submissions.DecisionReverted, err)
+						if errR.Retry {
+							level.Info(self.logger).Log("msg", "solution simulation reverted, will retry later", "reason", errR.Reason)
+							<-ticker.C
+							continue
+						}
+						level.Info(self.logger).Log("msg", "solution simulation reverted, skipping the challenge", "reason", errR.Reason)
+						return
+					}
+					// The stuck submit was replaced with a mined cancel as configured.
+					if errors.Is(err, transactor.ErrCancelled) {
+						level.Warn(self.logger).Log("msg", "solution submit was cancelled", "err", err)
+						self.record(base, submissions.DecisionFailed, err)
+						return
+					}
 					self.submitFailCount.Inc()
 					level.Error(self.logger).Log("msg", "submiting a solution", "err", err)
+					self.record(base, submissions.DecisionFailed, err)
 					return
 				}
 
+				base.SetTx(tx)
+				price, err := transactor.EffectiveGasPrice(self.ctx, self.client, tx, recieipt)
+				if err != nil {
+					level.Error(self.logger).Log("msg", "getting the effective gas price", "tx", tx.Hash(), "err", err)
+				}
+				base.SetReceipt(recieipt, price)
 				if recieipt.Status != types.ReceiptStatusSuccessful {
 					self.submitFailCount.Inc()
 					level.Error(self.logger).Log("msg", "submiting solution status not success", "status", recieipt.Status, "hash", tx.Hash())
+					self.record(base, submissions.DecisionFailed, errors.New("receipt status not success"))
 					return
 				}
 				level.Info(self.logger).Log("msg", "successfully submited solution",
@@ -324,7 +391,10 @@ func (self *Submitter) Submit(newChallengeReplace context.Context, result *minin
 					level.Error(self.logger).Log("msg", "getting _SLOT_PROGRESS for saving gas used", "err", err)
 				} else {
 					self.reward.SaveGasUsed(slot, recieipt.GasUsed)
+					_slot := slot.Int64()
+					base.Slot = &_slot
 				}
+				self.record(base, submissions.DecisionSubmitted, nil)
 
 				return
 			}
@@ -332,16 +402,66 @@ func (self *Submitter) Submit(newChallengeReplace context.Context, result *minin
 	}(newChallengeReplace, result)
 }
 
-func (self *Submitter) requestVals(requestIDs [5]*big.Int) ([5]*big.Int, error) {
+// shadowSubmit builds and records the transaction that would have been sent without sending it.
+func (self *Submitter) shadowSubmit(ctx context.Context, f func(*bind.TransactOpts) (*types.Transaction, error), base submissions.Record, ids, vals [5]*big.Int) {
+	tx, err := self.transactor.Build(ctx, f)
+	if err != nil {
+		var errR transactor.ErrReverted
+		if errors.As(err, &errR) {
+			level.Info(self.logger).Log("msg", "shadow solution simulation reverted", "reason", errR.Reason, "retry", errR.Retry)
+			self.recordShadowDecision(shadow.DecisionSkip, err)
+			self.record(base, submissions.DecisionReverted, err)
+			return
+		}
+		level.Error(self.logger).Log("msg", "building shadow transaction", "err", err)
+		self.recordShadowDecision(shadow.DecisionFailed, err)
+		self.record(base, submissions.DecisionFailed, err)
+		return
+	}
+	if err := self.shadow.Submit(tx, ids[:], vals[:]); err != nil {
+		level.Error(self.logger).Log("msg", "recording shadow submit", "err", err)
+	}
+	base.SetTx(tx)
+	self.record(base, submissions.DecisionSubmitted, nil)
+}
+
+// record adds a decision to the submissions ledger.
+func (self *Submitter) record(record submissions.Record, decision string, reason error) {
+	record.Time = time.Now()
+	record.Contract = "tellor"
+	record.Account = self.account.Address.String()
+	record.Decision = decision
+	record.Shadow = self.cfg.Shadow
+	if reason != nil {
+		record.Reason = reason.Error()
+	}
+	if err := self.ledger.Add(record); err != nil {
+		level.Error(self.logger).Log("msg", "adding a submissions ledger record", "err", err)
+	}
+}
+
+func (self *Submitter) recordShadowDecision(decision string, reason error) {
+	if !self.cfg.Shadow {
+		return
+	}
+	if err := self.shadow.Decision(decision, reason); err != nil {
+		level.Error(self.logger).Log("msg", "recording shadow decision", "err", err)
+	}
+}
+
+func (self *Submitter) requestVals(requestIDs [5]*big.Int) ([5]*big.Int, []submissions.Value, error) {
 	var currentValues [5]*big.Int
+	var values []submissions.Value
 	for i, reqID := range requestIDs {
-		val, err := self.psr.GetValue(reqID.Int64(), time.Now())
+		val, info, err := self.psr.GetValue(reqID.Int64(), time.Now())
 		if err != nil {
-			return currentValues, errors.Wrapf(err, "getting value for request ID:%v", reqID)
+			return currentValues, nil, errors.Wrapf(err, "getting value for request ID:%v", reqID)
 		}
+		level.Debug(self.logger).Log("msg", "value for submit", "reqID", reqID, "val", val, "method", info.Method)
 		currentValues[i] = big.NewInt(int64(val))
+		values = append(values, submissions.Value{ReqID: reqID.Int64(), Value: val, Method: info.Method, Symbol: info.Symbol, Sources: info.Sources})
 	}
-	return currentValues, nil
+	return currentValues, values, nil
 }
 
 func (self *Submitter) minerStatus() (int64, error) {
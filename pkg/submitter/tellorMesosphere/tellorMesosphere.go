@@ -13,18 +13,20 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/format"
 	"github.com/tellor-io/telliot/pkg/logging"
 	mathU "github.com/tellor-io/telliot/pkg/math"
 	psr "github.com/tellor-io/telliot/pkg/psr/tellorMesosphere"
+	"github.com/tellor-io/telliot/pkg/submissions"
+	"github.com/tellor-io/telliot/pkg/submitter/shadow"
 	"github.com/tellor-io/telliot/pkg/transactor"
 )
 
@@ -35,8 +37,10 @@ const (
 type Config struct {
 	Enabled              bool
 	LogLevel             string
-	MinSubmitPeriod      format.Duration `help:"The time limit between each submit for a staked miner."`
-	MinSubmitPriceChange float64         `help:" Submit only if that price changed at least that much percent."`
+	MinSubmitPeriod      format.Duration       `help:"The time limit between each submit for a staked miner."`
+	MinSubmitPriceChange float64               `help:" Submit only if that price changed at least that much percent."`
+	Shadow               bool                  `help:"Run the full submit pipeline without sending any transactions. The transactions that would have been sent are logged and the decisions and values are recorded in the DB."`
+	Bump                 transactor.BumpConfig `help:"How to increase the fees of the retried send attempts."`
 }
 
 /**
@@ -51,7 +55,7 @@ type Submitter struct {
 	logger          log.Logger
 	cfg             Config
 	account         *ethereum.Account
-	client          *ethclient.Client
+	client          *ethereum.Client
 	contract        *contracts.ITellorMesosphere
 	transactor      transactor.Transactor
 	submitCount     prometheus.Counter
@@ -61,17 +65,21 @@ type Submitter struct {
 	lastSubmitValue map[int64]float64
 	lastSubmitTime  map[int64]time.Time
 	reqIDs          []int64
+	shadow          *shadow.Recorder
+	ledger          *submissions.Ledger
 }
 
 func New(
 	ctx context.Context,
 	logger log.Logger,
 	cfg Config,
-	client *ethclient.Client,
+	client *ethereum.Client,
 	contract *contracts.ITellorMesosphere,
 	account *ethereum.Account,
 	transactor transactor.Transactor,
 	psr *psr.Psr,
+	tsDB storage.Appendable,
+	ledger *submissions.Ledger,
 ) (*Submitter, error) {
 	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
 	if err != nil {
@@ -89,6 +97,7 @@ func New(
 		contract:        contract,
 		transactor:      transactor,
 		psr:             psr,
+		ledger:          ledger,
 		reqIDs:          []int64{1, 2},
 		lastSubmitValue: make(map[int64]float64),
 		lastSubmitTime:  make(map[int64]time.Time),
@@ -123,6 +132,11 @@ func New(
 		submitter.lastSubmitTime[reqID] = time.Unix(0, 0)
 	}
 
+	if cfg.Shadow {
+		level.Warn(logger).Log("msg", "running in shadow mode, no transactions will be sent")
+		submitter.shadow = shadow.New(logger, ctx, tsDB, "tellorMesosphere", account.Address)
+	}
+
 	return submitter, nil
 }
 
@@ -174,28 +188,55 @@ func (self *Submitter) Stop() {
 }
 
 func (self *Submitter) Submit(reqID int64) error {
+	record := submissions.Record{Received: time.Now()}
+	decision, err := self.submit(reqID, &record)
+	if self.cfg.Shadow && err != nil {
+		if errR := self.shadow.Decision(shadow.DecisionFailed, err); errR != nil {
+			level.Error(self.logger).Log("msg", "recording shadow decision", "err", errR)
+		}
+	}
+	self.record(record, decision, err)
+	// The stuck submit was replaced with a mined cancel as configured.
+	if errors.Is(err, transactor.ErrCancelled) {
+		level.Warn(self.logger).Log("msg", "submit was cancelled", "reqID", reqID, "err", err)
+		return nil
+	}
+	return err
+}
+
+// submit returns the decision for the submissions ledger and
+// fills the record with the details of the submission.
+func (self *Submitter) submit(reqID int64, record *submissions.Record) (string, error) {
 	ctx, cncl := context.WithTimeout(self.ctx, time.Minute)
 	defer cncl()
 	isReporter, err := self.contract.IsReporter(&bind.CallOpts{Context: ctx}, self.account.Address)
 	if err != nil {
-		return errors.Wrap(err, "checking reporter status")
+		return submissions.DecisionFailed, errors.Wrap(err, "checking reporter status")
 	}
 	if !isReporter {
-		return errors.New("addr not a reporter")
+		return submissions.DecisionSkipped, errors.New("addr not a reporter")
 	}
 
-	val, err := self.psr.GetValue(reqID, time.Now())
+	val, info, err := self.psr.GetValue(reqID, time.Now())
 	if err != nil {
-		return errors.Wrap(err, "getting the value from the aggregator")
+		return submissions.DecisionSkipped, errors.Wrap(err, "getting the value from the aggregator")
 	}
+	record.Values = []submissions.Value{{ReqID: reqID, Value: val, Method: info.Method, Symbol: info.Symbol, Sources: info.Sources}}
 
 	if !self.shouldSubmit(reqID, float64(val)) {
-		return nil
+		record.Reason = "value change below the threshold"
+		if self.cfg.Shadow {
+			if err := self.shadow.Decision(shadow.DecisionSkip, errors.Errorf("value change below the threshold reqID:%v", reqID)); err != nil {
+				level.Error(self.logger).Log("msg", "recording shadow decision", "err", err)
+			}
+		}
+		return submissions.DecisionSkipped, nil
 	}
 	level.Info(self.logger).Log(
 		"msg", "sending values to the chain",
 		"ID", reqID,
 		"val", val,
+		"method", info.Method,
 	)
 
 	f := func(auth *bind.TransactOpts) (*types.Transaction, error) {
@@ -203,15 +244,49 @@ func (self *Submitter) Submit(reqID int64) error {
 		_val := big.NewInt(val)
 		return self.contract.SubmitValue(auth, _reqID, _val)
 	}
+	if self.cfg.Shadow {
+		tx, err := self.transactor.Build(ctx, f)
+		if err != nil {
+			var errR transactor.ErrReverted
+			if errors.As(err, &errR) {
+				return submissions.DecisionReverted, errors.Wrap(err, "shadow submit simulation")
+			}
+			return submissions.DecisionFailed, errors.Wrap(err, "building shadow transaction")
+		}
+		if err := self.shadow.Submit(tx, []*big.Int{big.NewInt(reqID)}, []*big.Int{big.NewInt(val)}); err != nil {
+			level.Error(self.logger).Log("msg", "recording shadow submit", "err", err)
+		}
+		record.SetTx(tx)
+		// Track the values as if submitted so that
+		// the shadow instance makes the same decisions as a live one.
+		self.lastSubmitValue[reqID] = float64(val)
+		self.lastSubmitTime[reqID] = time.Now()
+		return submissions.DecisionSubmitted, nil
+	}
+
 	tx, recieipt, err := self.transactor.Transact(ctx, f)
 	if err != nil {
+		var errR transactor.ErrReverted
+		if errors.As(err, &errR) {
+			// Nothing was sent so it is not a failed submit.
+			return submissions.DecisionReverted, errors.Wrap(err, "submit simulation")
+		}
+		if errors.Is(err, transactor.ErrCancelled) {
+			return submissions.DecisionFailed, errors.Wrap(err, "submiting a solution")
+		}
 		self.submitFailCount.Inc()
-		return errors.Wrap(err, "submiting a solution")
+		return submissions.DecisionFailed, errors.Wrap(err, "submiting a solution")
 	}
 
+	record.SetTx(tx)
+	price, err := transactor.EffectiveGasPrice(ctx, self.client, tx, recieipt)
+	if err != nil {
+		level.Error(self.logger).Log("msg", "getting the effective gas price", "tx", tx.Hash(), "err", err)
+	}
+	record.SetReceipt(recieipt, price)
 	if recieipt.Status != types.ReceiptStatusSuccessful {
 		self.submitFailCount.Inc()
-		return errors.Wrapf(err, "submiting solution status not success status:%v, tx hash:%v", recieipt.Status, tx.Hash())
+		return submissions.DecisionFailed, errors.Errorf("submiting solution status not success status:%v, tx hash:%v", recieipt.Status, tx.Hash())
 	}
 	level.Info(self.logger).Log("msg", "successfully submited solution",
 		"txHash", tx.Hash().String(),
@@ -237,7 +312,22 @@ func (self *Submitter) Submit(reqID int64) error {
 		"lastSubmitValue", self.lastSubmitValue[reqID],
 		"lastSubmitTime", time.Since(self.lastSubmitTime[reqID]),
 	)
-	return nil
+	return submissions.DecisionSubmitted, nil
+}
+
+// record adds a decision to the submissions ledger.
+func (self *Submitter) record(record submissions.Record, decision string, reason error) {
+	record.Time = time.Now()
+	record.Contract = "tellorMesosphere"
+	record.Account = self.account.Address.String()
+	record.Decision = decision
+	record.Shadow = self.cfg.Shadow
+	if reason != nil {
+		record.Reason = reason.Error()
+	}
+	if err := self.ledger.Add(record); err != nil {
+		level.Error(self.logger).Log("msg", "adding a submissions ledger record", "err", err)
+	}
 }
 
 func (self *Submitter) shouldSubmit(reqID int64, newVal float64) bool {
@@ -12,7 +12,6 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -43,7 +42,7 @@ type Tasker struct {
 	logger          log.Logger
 	accounts        []*ethereum.Account
 	contract        *contracts.ITellor
-	client          *ethclient.Client
+	client          *ethereum.Client
 	workSinks       map[string]chan *mining.Work
 	SubmitCancelers []SubmitCanceler
 	txPending       context.CancelFunc
@@ -53,7 +52,7 @@ func New(
 	ctx context.Context,
 	logger log.Logger,
 	cfg Config,
-	client *ethclient.Client,
+	client *ethereum.Client,
 	contract *contracts.ITellor,
 	accounts []*ethereum.Account,
 ) (*Tasker, map[string]chan *mining.Work, error) {
@@ -10,7 +10,6 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -20,6 +19,7 @@ import (
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/logging"
 	"github.com/tellor-io/telliot/pkg/math"
 	psrTellor "github.com/tellor-io/telliot/pkg/psr/tellor"
@@ -27,7 +27,9 @@ import (
 
 const ComponentName = "disputeTracker"
 
-const reorgEventWait = 3 * time.Minute
+// ReorgEventWait is how long to wait before recording an event so that re-orgs can cancel it.
+// The PSR values are calculated for the time when the event was received.
+const ReorgEventWait = 3 * time.Minute
 
 type Config struct {
 	LogLevel string
@@ -39,7 +41,7 @@ type Dispute struct {
 	close         context.CancelFunc
 	cfg           Config
 	tsDB          *tsdb.DB
-	client        *ethclient.Client
+	client        *ethereum.Client
 	contract      *contracts.ITellor
 	pendingAppend map[string]context.CancelFunc
 	mtx           sync.Mutex
@@ -51,7 +53,7 @@ func New(
 	ctx context.Context,
 	cfg Config,
 	tsDB *tsdb.DB,
-	client *ethclient.Client,
+	client *ethereum.Client,
 	contract *contracts.ITellor,
 	psrTellor *psrTellor.Psr,
 ) (*Dispute, error) {
@@ -145,7 +147,7 @@ func (self *Dispute) Start() {
 			self.mtx.Unlock()
 
 			go func(ctx context.Context) {
-				ticker := time.NewTicker(reorgEventWait) // Wait this long for any re-org events that can cancel this append.
+				ticker := time.NewTicker(ReorgEventWait) // Wait this long for any re-org events that can cancel this append.
 				defer ticker.Stop()
 
 				select {
@@ -216,7 +218,7 @@ func (self *Dispute) addValTellor(event *tellor.TellorNonceSubmitted) (err error
 		if err != nil {
 			return errors.Wrap(err, "append values to the DB")
 		}
-		valExp, err := self.psrTellor.GetValue(event.RequestId[i].Int64(), time.Now().Add(-reorgEventWait))
+		valExp, info, err := self.psrTellor.GetValue(event.RequestId[i].Int64(), time.Now().Add(-ReorgEventWait))
 		if err != nil {
 			return errors.Wrapf(err, "getting value from the PSR id:%v", event.RequestId[i].Int64())
 		}
@@ -239,6 +241,7 @@ func (self *Dispute) addValTellor(event *tellor.TellorNonceSubmitted) (err error
 			"miner", event.Miner.String(),
 			"oracleValue", valAct,
 			"psrValue", valExp,
+			"psrMethod", info.Method,
 			"difference", math.PercentageDiff(float64(valAct.Int64()), float64(valExp)),
 		)
 	}
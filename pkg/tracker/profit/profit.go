@@ -14,7 +14,6 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -23,6 +22,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/logging"
 )
 
@@ -36,7 +36,7 @@ type Config struct {
 
 type ProfitTracker struct {
 	netID            *big.Int
-	client           *ethclient.Client
+	client           *ethereum.Client
 	logger           log.Logger
 	contractInstance *contracts.ITellor
 	abi              abi.ABI
@@ -59,7 +59,7 @@ func NewProfitTracker(
 	logger log.Logger,
 	ctx context.Context,
 	cfg Config,
-	client *ethclient.Client,
+	client *ethereum.Client,
 	contractInstance *contracts.ITellor,
 	addrs []common.Address,
 ) (*ProfitTracker, error) {
@@ -465,6 +465,13 @@ func (self *ProfitTracker) setCostWhenConfirmed(logger log.Logger, event *tellor
 	}
 }
 
+// AddCost adds the cost of a transaction that isn't tracked from the contract events,
+// for example one mined while the process wasn't running.
+func (self *ProfitTracker) AddCost(addr common.Address, cost float64) {
+	level.Debug(self.logger).Log("msg", "adding cost", "addr", addr, "amount", cost)
+	self.submitCost.With(prometheus.Labels{"addr": addr.String()}).(prometheus.Gauge).Add(cost)
+}
+
 func (self *ProfitTracker) setProfitWhenConfirmed(logger log.Logger, event *tellor.TellorTransferred) {
 	ticker := time.NewTicker(DefaultRetry)
 	defer ticker.Stop()
@@ -0,0 +1,181 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/reward"
+)
+
+// Bump strategies.
+const (
+	BumpLinear      = "linear"
+	BumpExponential = "exponential"
+	BumpDeadline    = "deadline"
+)
+
+type BumpConfig struct {
+	Strategy string          `help:"How to increase the fees of the send attempts - linear(Step percent of the first attempt fees for every attempt), exponential(Step percent of the previous attempt fees) or deadline(up to MaxMultiplier of the first attempt fees at the end of the Window)."`
+	Step     float64         `help:"Percent increase of the fees for every attempt in the linear and exponential strategy."`
+	Delay    format.Duration `help:"How long to wait before the next attempt."`
+	// Window is used only when the submit context has no deadline.
+	Window        format.Duration `help:"The deadline strategy time window for the transaction to get mined in, counted from the first attempt."`
+	MaxMultiplier float64         `help:"The deadline strategy fees multiplier at the end of the window."`
+	RewardCap     bool            `help:"Cap the fees so that the current reward still covers the transaction cost. Supported only by the tellor submitter."`
+}
+
+// Attempt describes a send attempt of a transaction.
+type Attempt struct {
+	// N is the attempt number and 0 is the first attempt.
+	N int
+	// Start is the time of the first attempt.
+	Start time.Time
+	// Base are the fees of the first attempt.
+	Base Fees
+	// Prev are the fees of the pending transaction with the same nonce that the attempt replaces
+	// like the last broadcast attempt and are empty when there is no such transaction.
+	Prev Fees
+}
+
+// BumpStrategy decides the fees of every send attempt of a transaction.
+type BumpStrategy interface {
+	// Fees returns the fees for the attempt and how long to wait before the next one.
+	Fees(ctx context.Context, attempt Attempt) (Fees, time.Duration, error)
+}
+
+// NewBumpStrategy returns the configured strategy.
+func NewBumpStrategy(cfg BumpConfig) (BumpStrategy, error) {
+	switch cfg.Strategy {
+	case BumpLinear:
+		return &Linear{step: cfg.Step, delay: cfg.Delay.Duration}, nil
+	case BumpExponential:
+		return &Exponential{step: cfg.Step, delay: cfg.Delay.Duration}, nil
+	case BumpDeadline:
+		return &Deadline{window: cfg.Window.Duration, maxMultiplier: cfg.MaxMultiplier, delay: cfg.Delay.Duration}, nil
+	default:
+		return nil, errors.Errorf("unknown bump strategy:%v", cfg.Strategy)
+	}
+}
+
+// Linear increases the fees by Step percent of the first attempt fees for every attempt.
+type Linear struct {
+	step  float64
+	delay time.Duration
+}
+
+func (self *Linear) Fees(_ context.Context, a Attempt) (Fees, time.Duration, error) {
+	return a.replacement(a.Base.scale(1 + self.step*float64(a.N)/100)), self.delay, nil
+}
+
+// Exponential increases the fees by Step percent of the previous attempt fees for every attempt.
+type Exponential struct {
+	step  float64
+	delay time.Duration
+}
+
+func (self *Exponential) Fees(_ context.Context, a Attempt) (Fees, time.Duration, error) {
+	return a.replacement(a.Base.scale(math.Pow(1+self.step/100, float64(a.N)))), self.delay, nil
+}
+
+// Deadline increases the fees with the time that has passed so that
+// they reach MaxMultiplier of the first attempt fees at the deadline.
+// The deadline is the submit context deadline or the end of the window when the context has none.
+type Deadline struct {
+	window        time.Duration
+	maxMultiplier float64
+	delay         time.Duration
+}
+
+func (self *Deadline) Fees(ctx context.Context, a Attempt) (Fees, time.Duration, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = a.Start.Add(self.window)
+	}
+	progress := 1.0
+	if total := deadline.Sub(a.Start); total > 0 {
+		progress = math.Min(1, float64(time.Since(a.Start))/float64(total))
+	}
+	fees := a.replacement(a.Base.scale(1 + (self.maxMultiplier-1)*progress))
+
+	// Don't wait past the deadline so that the last attempt is close to it.
+	delay := self.delay
+	if left := time.Until(deadline); left > 0 && left < delay {
+		delay = left
+	}
+	return fees, delay, nil
+}
+
+// RewardCapped caps the fees of another strategy at the
+// max gas price for which the current reward still covers the transaction cost.
+type RewardCapped struct {
+	strategy  BumpStrategy
+	reward    *reward.Reward
+	minProfit int64
+}
+
+func NewRewardCapped(strategy BumpStrategy, reward *reward.Reward, minProfit int64) *RewardCapped {
+	return &RewardCapped{strategy: strategy, reward: reward, minProfit: minProfit}
+}
+
+func (self *RewardCapped) Fees(ctx context.Context, a Attempt) (Fees, time.Duration, error) {
+	fees, delay, err := self.strategy.Fees(ctx, a)
+	if err != nil {
+		return Fees{}, 0, err
+	}
+	max, err := self.reward.MaxGasPrice(self.minProfit)
+	if err != nil {
+		// Without gas used data for the slot the submitter skips the profit check
+		// so also don't cap the fees.
+		if _, ok := errors.Cause(err).(reward.ErrNoDataForSlot); ok {
+			return fees, delay, nil
+		}
+		return Fees{}, 0, errors.Wrap(err, "getting the max gas price for the reward")
+	}
+	return fees.capped(max), delay, nil
+}
+
+// replacement returns the fees raised to at least priceBump percent above
+// the pending transaction so that the attempt can replace it.
+func (self Attempt) replacement(fees Fees) Fees {
+	if self.Prev.maxPrice() == nil {
+		return fees
+	}
+	min := self.Prev.bump(1, nil)
+	return Fees{
+		GasPrice:  higherPrice(fees.GasPrice, min.GasPrice),
+		GasFeeCap: higherPrice(fees.GasFeeCap, min.GasFeeCap),
+		GasTipCap: higherPrice(fees.GasTipCap, min.GasTipCap),
+	}
+}
+
+func higherPrice(a, b *big.Int) *big.Int {
+	if a == nil || (b != nil && b.Cmp(a) > 0) {
+		return b
+	}
+	return a
+}
+
+func (self Fees) scale(mul float64) Fees {
+	return Fees{
+		GasPrice:  scalePrice(self.GasPrice, mul),
+		GasFeeCap: scalePrice(self.GasFeeCap, mul),
+		GasTipCap: scalePrice(self.GasTipCap, mul),
+	}
+}
+
+func scalePrice(price *big.Int, mul float64) *big.Int {
+	if price == nil {
+		return nil
+	}
+	p := new(big.Float).Mul(new(big.Float).SetInt(price), big.NewFloat(mul))
+	// Round to the nearest to avoid float errors like 1000*1.4=1399.
+	rounded, _ := p.Add(p, big.NewFloat(0.5)).Int(nil)
+	return rounded
+}
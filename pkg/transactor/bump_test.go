@@ -0,0 +1,114 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/go-kit/kit/log"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/reward"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestBumpStrategies(t *testing.T) {
+	ctx := context.Background()
+	base := Fees{GasFeeCap: big.NewInt(1000), GasTipCap: big.NewInt(100)}
+
+	linear, err := NewBumpStrategy(BumpConfig{Strategy: BumpLinear, Step: 20, Delay: format.Duration{Duration: time.Second}})
+	testutil.Ok(t, err)
+	fees, delay, err := linear.Fees(ctx, Attempt{N: 0, Base: base})
+	testutil.Ok(t, err)
+	testutil.Equals(t, base, fees)
+	testutil.Equals(t, time.Second, delay)
+	fees, _, err = linear.Fees(ctx, Attempt{N: 2, Base: base, Prev: base.scale(1.2)})
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(1400), fees.GasFeeCap)
+	testutil.Equals(t, big.NewInt(140), fees.GasTipCap)
+
+	exponential, err := NewBumpStrategy(BumpConfig{Strategy: BumpExponential, Step: 20})
+	testutil.Ok(t, err)
+	fees, _, err = exponential.Fees(ctx, Attempt{N: 2, Base: base, Prev: base.scale(1.2)})
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(1440), fees.GasFeeCap)
+
+	// A small step is raised so that the attempt can replace the previous one.
+	small, err := NewBumpStrategy(BumpConfig{Strategy: BumpLinear, Step: 1})
+	testutil.Ok(t, err)
+	fees, _, err = small.Fees(ctx, Attempt{N: 1, Base: base, Prev: base})
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(1120), fees.GasFeeCap)
+	testutil.Equals(t, big.NewInt(112), fees.GasTipCap)
+	// Without a broadcast attempt there is nothing to replace.
+	fees, _, err = small.Fees(ctx, Attempt{N: 1, Base: base})
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(1010), fees.GasFeeCap)
+	// The first attempt replaces the pending transaction of an abandoned nonce.
+	fees, _, err = small.Fees(ctx, Attempt{N: 0, Base: base, Prev: base.scale(2)})
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(2240), fees.GasFeeCap)
+	testutil.Equals(t, big.NewInt(224), fees.GasTipCap)
+
+	deadline, err := NewBumpStrategy(BumpConfig{Strategy: BumpDeadline, MaxMultiplier: 3, Window: format.Duration{Duration: time.Minute}, Delay: format.Duration{Duration: time.Hour}})
+	testutil.Ok(t, err)
+	fees, delay, err = deadline.Fees(ctx, Attempt{N: 0, Start: time.Now().Add(-time.Hour), Base: base})
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(3000), fees.GasFeeCap)
+	testutil.Equals(t, time.Hour, delay)
+	fees, delay, err = deadline.Fees(ctx, Attempt{N: 0, Start: time.Now(), Base: base})
+	testutil.Ok(t, err)
+	testutil.Assert(t, fees.GasFeeCap.Cmp(big.NewInt(1100)) < 0, "fees should be close to the base at the start of the window")
+	testutil.Assert(t, delay <= time.Minute, "the delay shouldn't be past the deadline")
+
+	_, err = NewBumpStrategy(BumpConfig{Strategy: "unknown"})
+	testutil.NotOk(t, err)
+}
+
+type fakeAggr struct{}
+
+func (fakeAggr) TimeWeightedAvg(string, time.Time, time.Duration) (float64, float64, error) {
+	return 1, 1, nil
+}
+
+// fakeRewardContract is at slot 0 with a reward of 1 TRB.
+type fakeRewardContract struct{}
+
+func (fakeRewardContract) GetUintVar(*bind.CallOpts, [32]byte) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (fakeRewardContract) CurrentReward(*bind.CallOpts) (*big.Int, error) {
+	return big.NewInt(params.Ether), nil
+}
+
+func TestRewardCapped(t *testing.T) {
+	ctx := context.Background()
+	rewards, err := reward.New(log.NewNopLogger(), reward.Config{LogLevel: "info", Window: 1, Percentile: 50}, fakeAggr{}, fakeRewardContract{})
+	testutil.Ok(t, err)
+	linear, err := NewBumpStrategy(BumpConfig{Strategy: BumpLinear, Step: 100, Delay: format.Duration{Duration: time.Second}})
+	testutil.Ok(t, err)
+	capped := NewRewardCapped(linear, rewards, 100)
+	base := Fees{GasFeeCap: big.NewInt(4e12), GasTipCap: big.NewInt(1e12)}
+
+	// Without gas used data for the next slot the fees aren't capped.
+	fees, delay, err := capped.Fees(ctx, Attempt{N: 1, Base: base})
+	testutil.Ok(t, err)
+	testutil.Equals(t, Fees{GasFeeCap: big.NewInt(8e12), GasTipCap: big.NewInt(2e12)}, fees)
+	testutil.Equals(t, time.Second, delay)
+
+	// The reward of 1 ETH covers a cost of 0.5 ETH with a 100% profit
+	// so the max price is 0.5 ETH / 100000 gas.
+	rewards.SaveGasUsed(big.NewInt(1), 100000)
+	fees, _, err = capped.Fees(ctx, Attempt{N: 1, Base: base})
+	testutil.Ok(t, err)
+	testutil.Equals(t, Fees{GasFeeCap: big.NewInt(5e12), GasTipCap: big.NewInt(2e12)}, fees)
+	fees, _, err = capped.Fees(ctx, Attempt{N: 0, Base: base})
+	testutil.Ok(t, err)
+	testutil.Equals(t, base, fees)
+}
@@ -0,0 +1,187 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+)
+
+// Transaction types.
+const (
+	// TxTypeAuto uses dynamic fee transactions when the chain has a base fee.
+	TxTypeAuto    = "auto"
+	TxTypeLegacy  = "legacy"
+	TxTypeDynamic = "dynamic"
+)
+
+// priceBump is the percent by which all fees of a replacement transaction are increased.
+// Nodes reject replacements that don't increase both the fee cap and the tip by at least 10%.
+const priceBump = 12
+
+// Fees holds either the gas price of a legacy transaction
+// or the fee cap and the tip of a dynamic fee transaction.
+type Fees struct {
+	GasPrice  *big.Int
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+}
+
+func (self Fees) dynamic() bool {
+	return self.GasFeeCap != nil
+}
+
+// maxPrice is the max price per gas that the transaction can cost.
+func (self Fees) maxPrice() *big.Int {
+	if self.dynamic() {
+		return self.GasFeeCap
+	}
+	return self.GasPrice
+}
+
+func (self Fees) apply(auth *bind.TransactOpts) {
+	auth.GasPrice = self.GasPrice
+	auth.GasFeeCap = self.GasFeeCap
+	auth.GasTipCap = self.GasTipCap
+}
+
+// bump returns the fees increased by priceBump percent for every replacement.
+// All fees are capped at the max, nil max means no cap.
+func (self Fees) bump(replacements int, max *big.Int) Fees {
+	b := Fees{
+		GasPrice:  bumpPrice(self.GasPrice, replacements),
+		GasFeeCap: bumpPrice(self.GasFeeCap, replacements),
+		GasTipCap: bumpPrice(self.GasTipCap, replacements),
+	}
+	return b.capped(max)
+}
+
+// replaces returns whether the fees are high enough to replace a transaction with the previous fees.
+func (self Fees) replaces(prev Fees) bool {
+	min := prev.bump(1, nil)
+	return atLeast(self.GasPrice, min.GasPrice) &&
+		atLeast(self.GasFeeCap, min.GasFeeCap) &&
+		atLeast(self.GasTipCap, min.GasTipCap)
+}
+
+func atLeast(price, min *big.Int) bool {
+	return min == nil || (price != nil && price.Cmp(min) >= 0)
+}
+
+func (self Fees) capped(max *big.Int) Fees {
+	c := Fees{
+		GasPrice:  minPrice(self.GasPrice, max),
+		GasFeeCap: minPrice(self.GasFeeCap, max),
+		GasTipCap: self.GasTipCap,
+	}
+	if c.GasFeeCap != nil {
+		c.GasTipCap = minPrice(c.GasTipCap, c.GasFeeCap)
+	}
+	return c
+}
+
+func bumpPrice(price *big.Int, replacements int) *big.Int {
+	if price == nil {
+		return nil
+	}
+	p := new(big.Int).Set(price)
+	for i := 0; i < replacements; i++ {
+		// Round up so that small prices still increase.
+		p.Mul(p, big.NewInt(100+priceBump)).Add(p, big.NewInt(99)).Div(p, big.NewInt(100))
+	}
+	return p
+}
+
+func minPrice(price, max *big.Int) *big.Int {
+	if price == nil || max == nil || price.Cmp(max) <= 0 {
+		return price
+	}
+	return new(big.Int).Set(max)
+}
+
+// maxPrice returns the max gas price or max fee per gas from the config.
+func (self *TransactorDefault) maxPrice() *big.Int {
+	return maxPrice(self.cfg)
+}
+
+func maxPrice(cfg Config) *big.Int {
+	max := int64(100)
+	if cfg.GasMax > 0 {
+		max = int64(cfg.GasMax)
+	}
+	return new(big.Int).Mul(big.NewInt(params.GWei), big.NewInt(max))
+}
+
+// fees returns the fees for the first send attempt.
+// Dynamic fee transactions are used when configured or in auto mode when the chain has a base fee.
+func (self *TransactorDefault) fees(ctx context.Context) (Fees, error) {
+	if self.cfg.TxType != TxTypeLegacy {
+		head, err := self.client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return Fees{}, errors.Wrap(err, "getting last block header")
+		}
+		if head.BaseFee != nil {
+			return DynamicFees(ctx, self.client, self.cfg)
+		}
+		if self.cfg.TxType == TxTypeDynamic {
+			return Fees{}, errors.New("dynamic fee transactions are not supported by the chain")
+		}
+	}
+
+	gasPrice, err := self.gasPrice(ctx)
+	if err != nil {
+		return Fees{}, err
+	}
+	if gasPrice.Cmp(big.NewInt(0)) == 0 {
+		gasPrice = big.NewInt(100)
+	}
+	return Fees{GasPrice: gasPrice}.capped(self.maxPrice()), nil
+}
+
+// DynamicFees derives the fees of a dynamic fee transaction from the eth_feeHistory of the last FeeHistoryBlocks.
+// The tip is the median of the PriorityFeePercentile of the included transactions and
+// the fee cap allows the base fee to grow by BaseFeeMultiplier before the transaction is included.
+// All fees are capped at GasMax.
+// It is also used by the commands that send transactions directly so that they use the same fees.
+func DynamicFees(ctx context.Context, client Client, cfg Config) (Fees, error) {
+	history, err := client.FeeHistory(ctx, cfg.FeeHistoryBlocks, nil, []float64{cfg.PriorityFeePercentile})
+	if err != nil {
+		return Fees{}, errors.Wrap(err, "getting fee history")
+	}
+	if len(history.BaseFee) == 0 {
+		return Fees{}, errors.New("empty fee history")
+	}
+	// The last base fee is the one of the next block.
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+
+	var rewards []*big.Int
+	for _, r := range history.Reward {
+		if len(r) > 0 && r[0] != nil {
+			rewards = append(rewards, r[0])
+		}
+	}
+	tip := big.NewInt(0)
+	if len(rewards) > 0 {
+		sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+		tip = new(big.Int).Set(rewards[len(rewards)/2])
+	}
+	if cfg.MaxPriorityFee > 0 {
+		tip = minPrice(tip, new(big.Int).Mul(big.NewInt(params.GWei), big.NewInt(int64(cfg.MaxPriorityFee))))
+	}
+
+	mul := cfg.BaseFeeMultiplier
+	if mul < 1 {
+		mul = 1
+	}
+	baseFeeF, _ := new(big.Float).Mul(new(big.Float).SetInt(baseFee), big.NewFloat(mul)).Int(nil)
+	return Fees{
+		GasFeeCap: baseFeeF.Add(baseFeeF, tip),
+		GasTipCap: tip,
+	}.capped(maxPrice(cfg)), nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestFeesBump(t *testing.T) {
+	max := big.NewInt(1000)
+	f := Fees{GasFeeCap: big.NewInt(200), GasTipCap: big.NewInt(10)}
+
+	// Every replacement increases both fees by at least 10%.
+	prev := f
+	for i := 1; i <= 3; i++ {
+		b := f.bump(i, max)
+		testutil.Assert(t, b.GasFeeCap.Cmp(new(big.Int).Div(new(big.Int).Mul(prev.GasFeeCap, big.NewInt(110)), big.NewInt(100))) >= 0, "fee cap not bumped enough")
+		testutil.Assert(t, b.GasTipCap.Cmp(new(big.Int).Div(new(big.Int).Mul(prev.GasTipCap, big.NewInt(110)), big.NewInt(100))) >= 0, "tip not bumped enough")
+		testutil.Assert(t, b.GasPrice == nil, "dynamic fees shouldn't set the gas price")
+		prev = b
+	}
+
+	// The fee cap is capped at the max and the tip at the fee cap.
+	b := Fees{GasFeeCap: big.NewInt(990), GasTipCap: big.NewInt(990)}.bump(1, max)
+	testutil.Equals(t, max, b.GasFeeCap)
+	testutil.Equals(t, max, b.GasTipCap)
+
+	b = Fees{GasPrice: big.NewInt(900)}.bump(2, max)
+	testutil.Equals(t, max, b.GasPrice)
+	testutil.Equals(t, max, b.maxPrice())
+}
+
+// feeHistoryClient returns the fee history with the given tips of the last blocks.
+type feeHistoryClient struct {
+	*fakeClient
+	baseFee *big.Int
+	tips    []int64
+}
+
+func (self *feeHistoryClient) FeeHistory(context.Context, uint64, *big.Int, []float64) (*ethereum.FeeHistory, error) {
+	history := &ethereum.FeeHistory{BaseFee: []*big.Int{self.baseFee}}
+	for _, tip := range self.tips {
+		history.Reward = append(history.Reward, []*big.Int{big.NewInt(tip * params.GWei)})
+	}
+	return history, nil
+}
+
+func TestDynamicFees(t *testing.T) {
+	client := &feeHistoryClient{fakeClient: newFakeClient(0), baseFee: big.NewInt(100 * params.GWei), tips: []int64{3, 1, 2}}
+	gwei := func(v int64) *big.Int { return big.NewInt(v * params.GWei) }
+
+	type testcase struct {
+		name     string
+		cfg      Config
+		expected Fees
+	}
+	for _, tc := range []testcase{
+		{name: "median tip", cfg: Config{BaseFeeMultiplier: 2, GasMax: 1000}, expected: Fees{GasFeeCap: gwei(202), GasTipCap: gwei(2)}},
+		{name: "base fee multiplier", cfg: Config{BaseFeeMultiplier: 1.5, GasMax: 1000}, expected: Fees{GasFeeCap: gwei(152), GasTipCap: gwei(2)}},
+		{name: "max priority fee", cfg: Config{BaseFeeMultiplier: 2, MaxPriorityFee: 1, GasMax: 1000}, expected: Fees{GasFeeCap: gwei(201), GasTipCap: gwei(1)}},
+		{name: "capped at the gas max", cfg: Config{BaseFeeMultiplier: 2, GasMax: 150}, expected: Fees{GasFeeCap: gwei(150), GasTipCap: gwei(2)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fees, err := DynamicFees(context.Background(), client, tc.cfg)
+			testutil.Ok(t, err)
+			testutil.Equals(t, tc.expected, fees)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/contracts/tellorMesosphere"
+)
+
+const methodUnknown = "unknown"
+
+var gasEstimate = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "telliot",
+	Subsystem: ComponentName,
+	Name:      "gas_estimate",
+	Help:      "The last estimated gas of a transaction without the margin",
+},
+	[]string{"method"},
+)
+
+var (
+	methodsOnce sync.Once
+	methodABIs  []abi.ABI
+)
+
+// methodName returns the name of the oracle contract method called with the data.
+func methodName(data []byte) string {
+	if len(data) < 4 {
+		return methodUnknown
+	}
+	methodsOnce.Do(func() {
+		for _, a := range []string{tellor.ITellorABI, tellorMesosphere.TellorMesosphereABI} {
+			parsed, err := abi.JSON(strings.NewReader(a))
+			if err != nil {
+				continue
+			}
+			methodABIs = append(methodABIs, parsed)
+		}
+	})
+	for _, a := range methodABIs {
+		if m, err := a.MethodById(data[:4]); err == nil {
+			return m.RawName
+		}
+	}
+	return methodUnknown
+}
+
+// prepare simulates the contract call and returns its gas limit.
+// The call is built without signing so that remote signers sign only the final transaction.
+func (self *TransactorDefault) prepare(ctx context.Context, contractCall func(*bind.TransactOpts) (*types.Transaction, error), auth *bind.TransactOpts) (uint64, error) {
+	opts := *auth
+	opts.NoSend = true
+	opts.GasLimit = self.cfg.GasLimitMax
+	opts.Signer = func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		return tx, nil
+	}
+	tx, err := contractCall(&opts)
+	if err != nil {
+		return 0, errors.Wrap(err, "contract call")
+	}
+
+	// Simulate before sending so that reverts don't cost gas.
+	if err := self.simulate(ctx, tx); err != nil {
+		return 0, err
+	}
+	return self.gasLimit(ctx, tx)
+}
+
+// gasLimit estimates the gas of the transaction and adds the GasLimitMargin.
+// It fails when the estimate is above the method cap as the transaction would run out of gas.
+func (self *TransactorDefault) gasLimit(ctx context.Context, tx *types.Transaction) (uint64, error) {
+	method := methodName(tx.Data())
+	estimate, err := self.client.EstimateGas(ctx, self.callMsg(tx))
+	if err != nil {
+		if reason, ok := revertReason(err); ok {
+			return 0, newErrReverted(reason)
+		}
+		return 0, errors.Wrapf(err, "estimating gas method:%v", method)
+	}
+	gasEstimate.With(prometheus.Labels{"method": method}).Set(float64(estimate))
+
+	max := self.cfg.GasLimitMax
+	if c, ok := self.cfg.GasLimitCaps[method]; ok {
+		max = c
+	}
+	if estimate > max {
+		return 0, errors.Errorf("estimated gas is above the cap method:%v, estimate:%v, cap:%v", method, estimate, max)
+	}
+	limit := uint64(float64(estimate) * (1 + self.cfg.GasLimitMargin/100))
+	if limit > max {
+		limit = max
+	}
+	return limit, nil
+}
+
+// cost returns the max cost of a transaction with the options.
+func cost(auth *bind.TransactOpts) *big.Int {
+	price := auth.GasPrice
+	if auth.GasFeeCap != nil {
+		price = auth.GasFeeCap
+	}
+	c := new(big.Int).Mul(price, new(big.Int).SetUint64(auth.GasLimit))
+	if auth.Value != nil {
+		c.Add(c, auth.Value)
+	}
+	return c
+}
@@ -0,0 +1,97 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestMethodName(t *testing.T) {
+	selector := crypto.Keccak256([]byte("submitMiningSolution(string,uint256[5],uint256[5])"))[:4]
+	testutil.Equals(t, "submitMiningSolution", methodName(selector))
+	testutil.Equals(t, methodUnknown, methodName([]byte{1, 2, 3, 4}))
+	testutil.Equals(t, methodUnknown, methodName(nil))
+}
+
+func TestCost(t *testing.T) {
+	testutil.Equals(t, big.NewInt(2000), cost(&bind.TransactOpts{GasPrice: big.NewInt(10), GasLimit: 200}))
+	testutil.Equals(t, big.NewInt(4001), cost(&bind.TransactOpts{GasFeeCap: big.NewInt(20), GasTipCap: big.NewInt(1), GasLimit: 200, Value: big.NewInt(1)}))
+}
+
+// balanceClient fails the calls with fees that the balance doesn't cover the same way as the nodes.
+type balanceClient struct {
+	*fakeClient
+	balance  *big.Int
+	estimate uint64
+}
+
+func (self *balanceClient) check(msg ethereum.CallMsg) error {
+	price := msg.GasPrice
+	if msg.GasFeeCap != nil {
+		price = msg.GasFeeCap
+	}
+	if price == nil {
+		return nil
+	}
+	if cost := new(big.Int).Mul(price, new(big.Int).SetUint64(msg.Gas)); cost.Cmp(self.balance) > 0 {
+		return errors.Errorf("insufficient funds for gas * price + value: have %v want %v", self.balance, cost)
+	}
+	return nil
+}
+
+func (self *balanceClient) BalanceAt(context.Context, common.Address, *big.Int) (*big.Int, error) {
+	return self.balance, nil
+}
+
+func (self *balanceClient) PendingCallContract(_ context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	return nil, self.check(msg)
+}
+
+func (self *balanceClient) EstimateGas(_ context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return self.estimate, self.check(msg)
+}
+
+func TestPrepareLowBalance(t *testing.T) {
+	ctx := context.Background()
+	feeCap := big.NewInt(100 * params.GWei)
+	// The balance covers the estimated gas, but not the max gas limit.
+	client := &balanceClient{
+		fakeClient: newFakeClient(0),
+		balance:    new(big.Int).Mul(feeCap, big.NewInt(500000)),
+		estimate:   400000,
+	}
+	self := newTestTransactor(t, Config{GasLimitMax: 3000000, GasLimitMargin: 20}, client)
+
+	auth, err := self.transactOpts(ctx, 0, Fees{GasFeeCap: feeCap, GasTipCap: big.NewInt(params.GWei)})
+	testutil.Ok(t, err)
+	to := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	contractCall := func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return opts.Signer(opts.From, types.NewTx(&types.DynamicFeeTx{
+			Nonce:     opts.Nonce.Uint64(),
+			GasFeeCap: opts.GasFeeCap,
+			GasTipCap: opts.GasTipCap,
+			Gas:       opts.GasLimit,
+			To:        &to,
+			Value:     opts.Value,
+		}))
+	}
+
+	limit, err := self.prepare(ctx, contractCall, auth)
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(480000), limit)
+
+	auth.GasLimit = limit
+	testutil.Assert(t, cost(auth).Cmp(client.balance) <= 0, "the transaction with the estimated gas should be affordable")
+}
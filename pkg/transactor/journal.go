@@ -0,0 +1,265 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/logging"
+)
+
+// Journal states.
+const (
+	// JournalSent is recorded for every transaction sent with the nonce.
+	JournalSent = "sent"
+	// JournalMined closes the nonce when one of its transactions is mined.
+	JournalMined = "mined"
+	// JournalCancelled closes the nonce when its cancel transaction is mined.
+	JournalCancelled = "cancelled"
+	// JournalDropped closes the nonce when it was used by a transaction that isn't in the journal.
+	JournalDropped = "dropped"
+)
+
+const purposeCancel = "cancel"
+
+// JournalEntry is a single state change of an account nonce.
+type JournalEntry struct {
+	Time    time.Time `json:"time"`
+	Account string    `json:"account"`
+	Nonce   uint64    `json:"nonce"`
+	State   string    `json:"state"`
+	Hash    string    `json:"hash"`
+	// Raw is the signed transaction, set only for sent entries.
+	Raw string `json:"raw,omitempty"`
+	// Purpose is the contract method or cancel for the cancel transactions.
+	Purpose string `json:"purpose,omitempty"`
+	// Deadline is when the transaction is no longer useful.
+	Deadline time.Time `json:"deadline,omitempty"`
+}
+
+// Tx decodes the signed transaction of a sent entry.
+func (self JournalEntry) Tx() (*types.Transaction, error) {
+	raw, err := hexutil.Decode(self.Raw)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding raw transaction:%v", self.Hash)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal transaction:%v", self.Hash)
+	}
+	return tx, nil
+}
+
+// Journal keeps all sent transactions in an append only file with one json entry per line
+// so that the pending ones can be resumed after a restart.
+// It is shared by the transactors of all accounts.
+type Journal struct {
+	logger log.Logger
+	file   string
+	mtx    sync.Mutex
+	// resumed are the accounts which open nonces were already returned by Open.
+	resumed map[common.Address]bool
+}
+
+// NewJournal opens the journal and compacts it so that
+// it keeps only the entries of the open nonces.
+func NewJournal(logger log.Logger, cfg Config) (*Journal, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	if cfg.JournalFile == "" {
+		return nil, errors.New("missing journal file")
+	}
+	self := &Journal{
+		logger:  log.With(logger, "component", ComponentName),
+		file:    cfg.JournalFile,
+		resumed: make(map[common.Address]bool),
+	}
+	if err := self.compact(); err != nil {
+		return nil, errors.Wrap(err, "compacting journal")
+	}
+	return self, nil
+}
+
+// Sent records a sent transaction.
+func (self *Journal) Sent(account common.Address, tx *types.Transaction, purpose string, deadline time.Time) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "encoding transaction")
+	}
+	return self.add(JournalEntry{
+		Account:  account.Hex(),
+		Nonce:    tx.Nonce(),
+		State:    JournalSent,
+		Hash:     tx.Hash().Hex(),
+		Raw:      hexutil.Encode(raw),
+		Purpose:  purpose,
+		Deadline: deadline,
+	})
+}
+
+// Close records the final state of the nonce.
+func (self *Journal) Close(account common.Address, nonce uint64, state string, hash common.Hash) error {
+	return self.add(JournalEntry{
+		Account: account.Hex(),
+		Nonce:   nonce,
+		State:   state,
+		Hash:    hash.Hex(),
+	})
+}
+
+// Open returns the sent entries of the account nonces that are not closed yet by nonce.
+// It returns them only once per account so that
+// only one of the transactors of the account resumes them.
+func (self *Journal) Open(account common.Address) (map[uint64][]JournalEntry, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if self.resumed[account] {
+		return nil, nil
+	}
+	self.resumed[account] = true
+
+	entries, err := self.read()
+	if err != nil {
+		return nil, err
+	}
+	open := make(map[uint64][]JournalEntry)
+	for _, e := range openEntries(entries) {
+		if e.Account == account.Hex() {
+			open[e.Nonce] = append(open[e.Nonce], e)
+		}
+	}
+	return open, nil
+}
+
+func (self *Journal) add(entry JournalEntry) (err error) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "marshal entry")
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	f, err := os.OpenFile(self.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "open journal")
+	}
+	defer func() {
+		if errC := f.Close(); errC != nil && err == nil {
+			err = errors.Wrap(errC, "close journal")
+		}
+	}()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return errors.Wrap(err, "write entry")
+	}
+	level.Debug(self.logger).Log("msg", "added journal entry", "account", entry.Account, "nonce", entry.Nonce, "state", entry.State, "tx", entry.Hash)
+	return nil
+}
+
+func (self *Journal) read() ([]JournalEntry, error) {
+	f, err := os.Open(self.file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "open journal")
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A crash while writing leaves a partial last line.
+			level.Warn(self.logger).Log("msg", "skipping invalid journal line", "line", line, "err", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "read journal")
+	}
+	return entries, nil
+}
+
+// compact rewrites the journal with only the entries of the open nonces.
+func (self *Journal) compact() error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	entries, err := self.read()
+	if err != nil {
+		return err
+	}
+	open := openEntries(entries)
+	if len(open) == len(entries) {
+		return nil
+	}
+
+	tmp := self.file + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return errors.Wrap(err, "create journal")
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range open {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return errors.Wrap(err, "write entry")
+		}
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "close journal")
+	}
+	return errors.Wrap(os.Rename(tmp, self.file), "replace journal")
+}
+
+// openEntries returns the sent entries of the nonces without a closing entry
+// sorted by account, nonce and time.
+func openEntries(entries []JournalEntry) []JournalEntry {
+	type key struct {
+		account string
+		nonce   uint64
+	}
+	closed := make(map[key]bool)
+	for _, e := range entries {
+		if e.State != JournalSent {
+			closed[key{e.Account, e.Nonce}] = true
+		}
+	}
+	var open []JournalEntry
+	for _, e := range entries {
+		if e.State == JournalSent && !closed[key{e.Account, e.Nonce}] {
+			open = append(open, e)
+		}
+	}
+	sort.SliceStable(open, func(i, j int) bool {
+		if open[i].Account != open[j].Account {
+			return open[i].Account < open[j].Account
+		}
+		return open[i].Nonce < open[j].Nonce
+	})
+	return open
+}
@@ -0,0 +1,61 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestJournal(t *testing.T) {
+	cfg := Config{LogLevel: "info", JournalFile: filepath.Join(t.TempDir(), "txJournal.log")}
+	journal, err := NewJournal(log.NewNopLogger(), cfg)
+	testutil.Ok(t, err)
+
+	account := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	other := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	tx := func(nonce uint64, price int64) *types.Transaction {
+		return types.NewTransaction(nonce, other, big.NewInt(0), 21000, big.NewInt(price), []byte{1, 2, 3, 4})
+	}
+	deadline := time.Now().Add(time.Minute).Round(0)
+
+	testutil.Ok(t, journal.Sent(account, tx(1, 100), "submitMiningSolution", time.Time{}))
+	testutil.Ok(t, journal.Close(account, 1, JournalMined, tx(1, 100).Hash()))
+	testutil.Ok(t, journal.Sent(account, tx(2, 100), "submitMiningSolution", deadline))
+	testutil.Ok(t, journal.Sent(account, tx(2, 112), "submitMiningSolution", deadline))
+	testutil.Ok(t, journal.Sent(other, tx(2, 100), "submitValue", time.Time{}))
+
+	// Only the entries of the open nonces are kept after a restart.
+	journal, err = NewJournal(log.NewNopLogger(), cfg)
+	testutil.Ok(t, err)
+	data, err := ioutil.ReadFile(cfg.JournalFile)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, strings.Count(string(data), "\n"))
+
+	open, err := journal.Open(account)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(open))
+	testutil.Equals(t, 2, len(open[2]))
+	testutil.Equals(t, deadline.UTC(), open[2][1].Deadline.UTC())
+	replaced, err := open[2][1].Tx()
+	testutil.Ok(t, err)
+	testutil.Equals(t, tx(2, 112).Hash(), replaced.Hash())
+
+	// The open nonces are returned only to the first transactor of the account.
+	open, err = journal.Open(account)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(open))
+	open, err = journal.Open(other)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(open[2]))
+}
@@ -0,0 +1,309 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// RelaySigningKeyEnvName is the private key that signs the relay requests.
+// It is only the identity of the sender in the relay and doesn't need any funds.
+const RelaySigningKeyEnvName = "RELAY_SIGNING_KEY"
+
+// Relay modes.
+const (
+	// RelayModeBundle sends every transaction as a single transaction bundle with eth_sendBundle
+	// targeting the next block and resends it for every new block until it is included.
+	RelayModeBundle = "bundle"
+	// RelayModeRPC sends the transactions with eth_sendRawTransaction to a private RPC
+	// which keeps them out of the public mempool.
+	RelayModeRPC = "rpc"
+)
+
+// Relay results.
+const (
+	relaySent     = "sent"
+	relayIncluded = "included"
+	relayFallback = "fallback"
+	relayError    = "error"
+)
+
+const relayTimeout = 10 * time.Second
+
+// relayRetryDelay is how long to wait before retrying a failed relay send.
+const relayRetryDelay = time.Second
+
+var relayTxs = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "telliot",
+	Subsystem: ComponentName,
+	Name:      "relay_total",
+	Help:      "The total number of transactions sent to the private relay by the result",
+},
+	[]string{"result"},
+)
+
+type RelayConfig struct {
+	URL            string `help:"Private relay endpoint. The transactions are sent to the public mempool when not set."`
+	Mode           string `help:"How to send to the relay - bundle(Flashbots style eth_sendBundle) or rpc(eth_sendRawTransaction to a private RPC)."`
+	FallbackBlocks uint64 `help:"Send the transaction to the public mempool when the relay fails or doesn't include it for this many blocks, 0 never falls back."`
+}
+
+// Relay sends signed transactions to a private relay so that they
+// don't show up in the public mempool where they can be copied.
+type Relay struct {
+	logger log.Logger
+	cfg    RelayConfig
+	key    *ecdsa.PrivateKey
+	client *http.Client
+}
+
+func NewRelay(logger log.Logger, cfg RelayConfig) (*Relay, error) {
+	switch cfg.Mode {
+	case RelayModeBundle, RelayModeRPC:
+	default:
+		return nil, errors.Errorf("unknown relay mode:%v", cfg.Mode)
+	}
+
+	var key *ecdsa.PrivateKey
+	var err error
+	if k := os.Getenv(RelaySigningKeyEnvName); k != "" {
+		key, err = crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(k), "0x"))
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing the relay signing key")
+		}
+	} else {
+		// Relays rank the senders by the key so a random one starts without any reputation.
+		key, err = crypto.GenerateKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "generating the relay signing key")
+		}
+		level.Warn(logger).Log("msg", "relay signing key not set so using a random one", "env", RelaySigningKeyEnvName)
+	}
+
+	return &Relay{
+		logger: log.With(logger, "relay", cfg.URL),
+		cfg:    cfg,
+		key:    key,
+		client: &http.Client{Timeout: relayTimeout},
+	}, nil
+}
+
+// Send sends the signed transaction to the relay.
+// In bundle mode the bundle is valid only for the target block.
+func (self *Relay) Send(ctx context.Context, tx *types.Transaction, block uint64) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "encoding transaction")
+	}
+
+	method, params := "eth_sendRawTransaction", []interface{}{hexutil.Encode(raw)}
+	if self.cfg.Mode == RelayModeBundle {
+		method, params = "eth_sendBundle", []interface{}{bundle{
+			Txs:         []string{hexutil.Encode(raw)},
+			BlockNumber: hexutil.EncodeUint64(block),
+		}}
+	}
+
+	result, err := self.call(ctx, method, params)
+	if err != nil {
+		relayTxs.With(prometheus.Labels{"result": relayError}).Inc()
+		return errors.Wrapf(err, "relay %v", method)
+	}
+	relayTxs.With(prometheus.Labels{"result": relaySent}).Inc()
+	level.Debug(self.logger).Log("msg", "sent to the relay", "method", method, "tx", tx.Hash(), "block", block, "result", string(result))
+	return nil
+}
+
+type bundle struct {
+	Txs         []string `json:"txs"`
+	BlockNumber string   `json:"blockNumber"`
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call sends a JSON-RPC request signed with the relay key in the X-Flashbots-Signature header.
+func (self *Relay) call(ctx context.Context, method string, params []interface{}) (json.RawMessage, error) {
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding request")
+	}
+	signature, err := self.sign(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, self.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Flashbots-Signature", signature)
+
+	resp, err := self.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "sending request")
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("response status:%v, body:%v", resp.StatusCode, string(data))
+	}
+
+	var r rpcResponse
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, errors.Wrapf(err, "decoding response:%v", string(data))
+	}
+	if r.Error != nil {
+		return nil, errors.Errorf("code:%v, message:%v", r.Error.Code, r.Error.Message)
+	}
+	return r.Result, nil
+}
+
+// sign returns the address of the relay key and its signature
+// of the hex encoded keccak hash of the body as used by Flashbots.
+func (self *Relay) sign(body []byte) (string, error) {
+	hash := hexutil.Encode(crypto.Keccak256(body))
+	sig, err := crypto.Sign(accounts.TextHash([]byte(hash)), self.key)
+	if err != nil {
+		return "", errors.Wrap(err, "signing relay request")
+	}
+	return crypto.PubkeyToAddress(self.key.PublicKey).Hex() + ":" + hexutil.Encode(sig), nil
+}
+
+// relayedTx tracks a transaction sent to the relay until it is included or sent to the public mempool.
+type relayedTx struct {
+	tx *types.Transaction
+	// since is the block number when the transaction was first sent to the relay.
+	since uint64
+	// target is the block targeted by the last bundle.
+	target uint64
+	public bool
+}
+
+// send sends the transaction to the relay when it is set and otherwise to the public mempool.
+// Every sent transaction is recorded in the journal.
+func (self *TransactorDefault) send(ctx context.Context, tx *types.Transaction) (*relayedTx, error) {
+	r, err := self.sendTo(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	self.journalSent(ctx, tx)
+	return r, nil
+}
+
+func (self *TransactorDefault) sendTo(ctx context.Context, tx *types.Transaction) (*relayedTx, error) {
+	if self.relay == nil {
+		return nil, self.client.SendTransaction(ctx, tx)
+	}
+	since, err := self.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting block number")
+	}
+	// Failed relay sends are retried until FallbackBlocks have passed
+	// and then the transaction is sent to the public mempool.
+	block := since
+	for {
+		err := self.relay.Send(ctx, tx, block+1)
+		if err == nil {
+			level.Info(self.logger).Log("msg", "sent to the relay", "tx", tx.Hash(), "block", block)
+			return &relayedTx{tx: tx, since: since, target: block + 1}, nil
+		}
+		f := self.cfg.Relay.FallbackBlocks
+		if f == 0 {
+			return nil, err
+		}
+		if block-since >= f {
+			level.Warn(self.logger).Log("msg", "sending to the relay failed so sending to the public mempool", "tx", tx.Hash(), "blocks", block-since, "err", err)
+			relayTxs.With(prometheus.Labels{"result": relayFallback}).Inc()
+			return nil, self.client.SendTransaction(ctx, tx)
+		}
+		level.Warn(self.logger).Log("msg", "sending to the relay failed so will retry", "tx", tx.Hash(), "retryDelay", relayRetryDelay, "err", err)
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(err, "the context was canceled while retrying the relay")
+		case <-time.After(relayRetryDelay):
+		}
+		block, err = self.client.BlockNumber(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting block number")
+		}
+	}
+}
+
+// followRelay resends the bundle for every new block and
+// sends the transaction to the public mempool after FallbackBlocks.
+func (self *TransactorDefault) followRelay(ctx context.Context, r *relayedTx) {
+	if r == nil || r.public {
+		return
+	}
+	block, err := self.client.BlockNumber(ctx)
+	if err != nil {
+		level.Error(self.logger).Log("msg", "getting block number", "err", err)
+		return
+	}
+	if block < r.target {
+		return
+	}
+
+	if f := self.cfg.Relay.FallbackBlocks; f > 0 && block-r.since >= f {
+		r.public = true
+		relayTxs.With(prometheus.Labels{"result": relayFallback}).Inc()
+		level.Warn(self.logger).Log("msg", "not included by the relay so sending to the public mempool", "tx", r.tx.Hash(), "blocks", block-r.since)
+		if err := self.client.SendTransaction(ctx, r.tx); err != nil {
+			level.Error(self.logger).Log("msg", "sending to the public mempool", "tx", r.tx.Hash(), "err", err)
+		}
+		return
+	}
+
+	r.target = block + 1
+	if self.cfg.Relay.Mode != RelayModeBundle {
+		return
+	}
+	if err := self.relay.Send(ctx, r.tx, r.target); err != nil {
+		level.Error(self.logger).Log("msg", "resending the bundle", "tx", r.tx.Hash(), "block", r.target, "err", err)
+	}
+}
+
+// relayIncluded records the inclusion of a transaction that was sent only to the relay.
+func (self *TransactorDefault) relayIncluded(r *relayedTx, tx *types.Transaction, receipt *types.Receipt) {
+	if r == nil || r.public || r.tx.Hash() != tx.Hash() {
+		return
+	}
+	relayTxs.With(prometheus.Labels{"result": relayIncluded}).Inc()
+	level.Info(self.logger).Log("msg", "included by the relay", "tx", tx.Hash(), "block", receipt.BlockNumber, "blocks", receipt.BlockNumber.Uint64()-r.since)
+}
@@ -0,0 +1,180 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-kit/kit/log"
+	promTestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestRelay(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	defer os.Setenv(RelaySigningKeyEnvName, os.Getenv(RelaySigningKeyEnvName))
+	testutil.Ok(t, os.Setenv(RelaySigningKeyEnvName, hexutil.Encode(crypto.FromECDSA(key))))
+
+	var requests []rpcRequest
+	var signers []common.Address
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		testutil.Ok(t, err)
+		var req rpcRequest
+		testutil.Ok(t, json.Unmarshal(body, &req))
+		requests = append(requests, req)
+
+		parts := strings.Split(r.Header.Get("X-Flashbots-Signature"), ":")
+		testutil.Equals(t, 2, len(parts))
+		sig, err := hexutil.Decode(parts[1])
+		testutil.Ok(t, err)
+		pub, err := crypto.SigToPub(accounts.TextHash([]byte(hexutil.Encode(crypto.Keccak256(body)))), sig)
+		testutil.Ok(t, err)
+		testutil.Equals(t, parts[0], crypto.PubkeyToAddress(*pub).Hex())
+		signers = append(signers, crypto.PubkeyToAddress(*pub))
+
+		if req.Method == "eth_sendRawTransaction" {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"nonce too low"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x01"}}`))
+	}))
+	defer srv.Close()
+
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     1,
+		Gas:       21000,
+		GasFeeCap: big.NewInt(100),
+		GasTipCap: big.NewInt(10),
+	})
+	testutil.Ok(t, err)
+	raw, err := tx.MarshalBinary()
+	testutil.Ok(t, err)
+
+	relay, err := NewRelay(log.NewNopLogger(), RelayConfig{URL: srv.URL, Mode: RelayModeBundle})
+	testutil.Ok(t, err)
+	testutil.Ok(t, relay.Send(context.Background(), tx, 100))
+	testutil.Equals(t, "eth_sendBundle", requests[0].Method)
+	b := requests[0].Params[0].(map[string]interface{})
+	testutil.Equals(t, "0x64", b["blockNumber"])
+	testutil.Equals(t, []interface{}{hexutil.Encode(raw)}, b["txs"])
+	testutil.Equals(t, crypto.PubkeyToAddress(key.PublicKey), signers[0])
+
+	relay, err = NewRelay(log.NewNopLogger(), RelayConfig{URL: srv.URL, Mode: RelayModeRPC})
+	testutil.Ok(t, err)
+	err = relay.Send(context.Background(), tx, 100)
+	testutil.NotOk(t, err)
+	testutil.Assert(t, strings.Contains(err.Error(), "nonce too low"), "the relay error should be returned")
+	testutil.Equals(t, []interface{}{hexutil.Encode(raw)}, requests[1].Params)
+
+	_, err = NewRelay(log.NewNopLogger(), RelayConfig{URL: srv.URL, Mode: "public"})
+	testutil.NotOk(t, err)
+}
+
+func TestRelayFollow(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient(0)
+	client.setBlock(100)
+
+	var (
+		mtx    sync.Mutex
+		blocks []string
+		// fails is how many of the next sends fail.
+		fails int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(&req))
+		mtx.Lock()
+		defer mtx.Unlock()
+		if fails > 0 {
+			fails--
+			// A new block is mined while the relay is failing.
+			block, err := client.BlockNumber(ctx)
+			testutil.Ok(t, err)
+			client.setBlock(block + 1)
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"relay is busy"}}`))
+			return
+		}
+		blocks = append(blocks, req.Params[0].(map[string]interface{})["blockNumber"].(string))
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x01"}}`))
+	}))
+	defer srv.Close()
+
+	cfg := Config{Relay: RelayConfig{URL: srv.URL, Mode: RelayModeBundle, FallbackBlocks: 3}}
+	self := newTestTransactor(t, cfg, client)
+	var err error
+	self.relay, err = NewRelay(log.NewNopLogger(), cfg.Relay)
+	testutil.Ok(t, err)
+	tx, err := self.account.Signer.SignTx(ctx, types.NewTransaction(1, common.Address{}, big.NewInt(0), cancelGasLimit, big.NewInt(1), nil), self.netID)
+	testutil.Ok(t, err)
+	fallbacks := promTestutil.ToFloat64(relayTxs.WithLabelValues(relayFallback))
+	included := promTestutil.ToFloat64(relayTxs.WithLabelValues(relayIncluded))
+
+	r, err := self.send(ctx, tx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"0x65"}, blocks)
+
+	// The bundle is resent for every new block.
+	self.followRelay(ctx, r)
+	testutil.Equals(t, 1, len(blocks))
+	client.setBlock(101)
+	self.followRelay(ctx, r)
+	testutil.Equals(t, []string{"0x65", "0x66"}, blocks)
+	testutil.Equals(t, 0, len(client.sentTxs()))
+
+	// A transaction included by the relay is counted only once.
+	receipt := &types.Receipt{BlockNumber: big.NewInt(102)}
+	self.relayIncluded(r, tx, receipt)
+	testutil.Equals(t, included+1, promTestutil.ToFloat64(relayTxs.WithLabelValues(relayIncluded)))
+
+	// Not included after the fallback blocks so sent to the public mempool.
+	client.setBlock(103)
+	self.followRelay(ctx, r)
+	testutil.Equals(t, true, r.public)
+	testutil.Equals(t, []*types.Transaction{tx}, client.sentTxs())
+	testutil.Equals(t, fallbacks+1, promTestutil.ToFloat64(relayTxs.WithLabelValues(relayFallback)))
+	self.followRelay(ctx, r)
+	self.relayIncluded(r, tx, receipt)
+	testutil.Equals(t, 2, len(blocks))
+	testutil.Equals(t, 1, len(client.sentTxs()))
+	testutil.Equals(t, included+1, promTestutil.ToFloat64(relayTxs.WithLabelValues(relayIncluded)))
+
+	// A failed send is retried and the fallback blocks are counted from the first try.
+	mtx.Lock()
+	fails = 1
+	mtx.Unlock()
+	r, err = self.send(ctx, tx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(103), r.since)
+	testutil.Equals(t, uint64(105), r.target)
+	testutil.Equals(t, "0x69", blocks[len(blocks)-1])
+
+	// The relay keeps failing so the transaction is sent to the public mempool.
+	self.cfg.Relay.FallbackBlocks = 1
+	mtx.Lock()
+	fails = 2
+	mtx.Unlock()
+	r, err = self.send(ctx, tx)
+	testutil.Ok(t, err)
+	testutil.Assert(t, r == nil, "the transaction shouldn't be followed after the fallback")
+	testutil.Equals(t, 2, len(client.sentTxs()))
+	testutil.Equals(t, fallbacks+2, promTestutil.ToFloat64(relayTxs.WithLabelValues(relayFallback)))
+}
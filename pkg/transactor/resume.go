@@ -0,0 +1,202 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+)
+
+// CostTracker records the cost of transactions which are not tracked from the contract events
+// like the ones mined while the process wasn't running and the cancel transactions.
+// The transactor doesn't record the costs when its tracker is nil.
+type CostTracker interface {
+	AddCost(addr common.Address, cost float64)
+}
+
+// Resume continues monitoring the transactions of the account left pending by a previous run.
+// A transaction past its deadline or which would revert now, for example for a stale challenge,
+// is cancelled and all others are resent and monitored until they are mined.
+// It returns after the pending nonces are reserved so that new transactions don't replace them.
+func (self *TransactorDefault) Resume(ctx context.Context) error {
+	if self.journal == nil {
+		return nil
+	}
+	open, err := self.journal.Open(self.account.Address)
+	if err != nil {
+		return errors.Wrap(err, "reading journal")
+	}
+	if len(open) == 0 {
+		return nil
+	}
+	chainNonce, err := self.client.NonceAt(ctx, self.account.Address, nil)
+	if err != nil {
+		return errors.Wrap(err, "getting nonce")
+	}
+
+	nonces := self.account.Nonces(self.client)
+	for nonce, entries := range open {
+		var txs []*types.Transaction
+		for _, e := range entries {
+			tx, err := e.Tx()
+			if err != nil {
+				level.Error(self.logger).Log("msg", "skipping journal entry", "nonce", nonce, "err", err)
+				continue
+			}
+			txs = append(txs, tx)
+		}
+		if len(txs) == 0 {
+			continue
+		}
+
+		if self.resumeMined(ctx, nonce, entries, txs) {
+			continue
+		}
+		if nonce < chainNonce {
+			level.Warn(self.logger).Log("msg", "journal nonce was used by another transaction", "nonce", nonce)
+			self.journalClose(nonce, JournalDropped, common.Hash{})
+			continue
+		}
+
+		last := txs[len(txs)-1]
+		nonces.Sent(nonce, last.Hash())
+		go self.resume(ctx, entries[len(entries)-1], last)
+	}
+	return nil
+}
+
+// resumeMined closes the nonce when one of its transactions was mined while the process wasn't running.
+func (self *TransactorDefault) resumeMined(ctx context.Context, nonce uint64, entries []JournalEntry, txs []*types.Transaction) bool {
+	for i, tx := range txs {
+		receipt, err := self.client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			continue
+		}
+		state := JournalMined
+		if entries[i].Purpose == purposeCancel {
+			state = JournalCancelled
+		}
+		level.Info(self.logger).Log("msg", "journal transaction was mined while not running", "tx", tx.Hash(), "nonce", nonce, "state", state, "status", receipt.Status)
+		self.journalClose(nonce, state, tx.Hash())
+		self.addCost(ctx, tx, receipt)
+		return true
+	}
+	return false
+}
+
+func (self *TransactorDefault) resume(ctx context.Context, entry JournalEntry, tx *types.Transaction) {
+	nonces := self.account.Nonces(self.client)
+
+	reason := ""
+	if !entry.Deadline.IsZero() && time.Now().After(entry.Deadline) {
+		reason = "deadline passed"
+	} else if entry.Purpose != purposeCancel {
+		if _, err := self.client.CallContract(ctx, self.callMsg(tx), nil); err != nil {
+			reason = err.Error()
+			if r, ok := revertReason(err); ok {
+				reason = r
+			}
+		}
+	}
+
+	// The bump strategy continues from the fees of the resumed transaction.
+	attempt := Attempt{Start: entry.Time, Base: txFees(tx)}
+	if reason != "" {
+		level.Warn(self.logger).Log("msg", "journal transaction is no longer useful so cancelling it", "tx", tx.Hash(), "nonce", tx.Nonce(), "reason", reason)
+		attempt.N, attempt.Prev = 1, txFees(tx)
+		cancel, err := self.replace(ctx, tx, attempt, true)
+		if err == nil {
+			_, err = self.send(ctx, cancel)
+		}
+		if err != nil {
+			level.Error(self.logger).Log("msg", "cancelling journal transaction", "tx", tx.Hash(), "err", err)
+			return
+		}
+		nonces.Sent(cancel.Nonce(), cancel.Hash())
+		tx = cancel
+	} else {
+		level.Info(self.logger).Log("msg", "resuming journal transaction", "tx", tx.Hash(), "nonce", tx.Nonce(), "purpose", entry.Purpose)
+		if err := self.client.SendTransaction(ctx, tx); err != nil && !strings.Contains(strings.ToLower(err.Error()), "already known") {
+			level.Warn(self.logger).Log("msg", "resending journal transaction", "tx", tx.Hash(), "err", err)
+		}
+	}
+
+	// A mined cancel transaction is a final state like any other mined transaction.
+	mined, receipt, err := self.waitMined(ctx, tx, nil, attempt)
+	if err != nil && !errors.Is(err, ErrCancelled) {
+		level.Error(self.logger).Log("msg", "waiting for journal transaction", "tx", tx.Hash(), "err", err)
+		return
+	}
+	nonces.Done(tx.Nonce())
+	state := JournalMined
+	// The cancels are the only self transfers.
+	if to := mined.To(); to != nil && *to == self.account.Address {
+		state = JournalCancelled
+		// Only the cancel cost isn't tracked from the contract events.
+		self.addCost(ctx, mined, receipt)
+	}
+	self.journalClose(mined.Nonce(), state, mined.Hash())
+	level.Info(self.logger).Log("msg", "journal transaction mined", "tx", mined.Hash(), "state", state, "status", receipt.Status)
+}
+
+// addCost records the cost of the mined transaction in ETH.
+func (self *TransactorDefault) addCost(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) {
+	if self.costs == nil {
+		return
+	}
+	price, err := EffectiveGasPrice(ctx, self.client, tx, receipt)
+	if err != nil {
+		level.Error(self.logger).Log("msg", "getting the transaction cost", "tx", tx.Hash(), "err", err)
+		return
+	}
+	cost, _ := new(big.Float).Mul(new(big.Float).SetInt(price), new(big.Float).SetUint64(receipt.GasUsed)).Float64()
+	self.costs.AddCost(self.account.Address, cost/1e18)
+}
+
+// EffectiveGasPrice returns the gas price paid by a mined transaction.
+// A dynamic fee transaction pays the base fee of its block and the tip up to its fee cap.
+func EffectiveGasPrice(ctx context.Context, client Client, tx *types.Transaction, receipt *types.Receipt) (*big.Int, error) {
+	if tx.Type() != types.DynamicFeeTxType {
+		return tx.GasPrice(), nil
+	}
+	header, err := client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the block of the transaction")
+	}
+	if header.BaseFee == nil {
+		return tx.GasPrice(), nil
+	}
+	return new(big.Int).Add(header.BaseFee, tx.EffectiveGasTipValue(header.BaseFee)), nil
+}
+
+// journalSent records a sent transaction with the context deadline.
+func (self *TransactorDefault) journalSent(ctx context.Context, tx *types.Transaction) {
+	if self.journal == nil {
+		return
+	}
+	purpose := methodName(tx.Data())
+	if len(tx.Data()) == 0 && tx.To() != nil && *tx.To() == self.account.Address {
+		purpose = purposeCancel
+	}
+	deadline, _ := ctx.Deadline()
+	if err := self.journal.Sent(self.account.Address, tx, purpose, deadline); err != nil {
+		level.Error(self.logger).Log("msg", "adding transaction to the journal", "tx", tx.Hash(), "err", err)
+	}
+}
+
+func (self *TransactorDefault) journalClose(nonce uint64, state string, hash common.Hash) {
+	if self.journal == nil {
+		return
+	}
+	if err := self.journal.Close(self.account.Address, nonce, state, hash); err != nil {
+		level.Error(self.logger).Log("msg", "closing the journal nonce", "nonce", nonce, "err", err)
+	}
+}
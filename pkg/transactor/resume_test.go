@@ -0,0 +1,250 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	tEthereum "github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// fakeClient is a node that mines only the sent transactions accepted by mine.
+type fakeClient struct {
+	mtx   sync.Mutex
+	nonce uint64
+	block uint64
+	mine  func(*types.Transaction) bool
+	// revert is the call data of the calls that revert.
+	revert   []byte
+	sent     []*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+}
+
+func newFakeClient(nonce uint64) *fakeClient {
+	return &fakeClient{nonce: nonce, receipts: make(map[common.Hash]*types.Receipt)}
+}
+
+func (self *fakeClient) setMine(mine func(*types.Transaction) bool) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.mine = mine
+}
+
+func (self *fakeClient) setBlock(block uint64) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.block = block
+}
+
+func (self *fakeClient) sentTxs() []*types.Transaction {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return append([]*types.Transaction(nil), self.sent...)
+}
+
+func (self *fakeClient) NetworkID(context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (self *fakeClient) BlockNumber(context.Context) (uint64, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.block, nil
+}
+
+func (self *fakeClient) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return &types.Header{Number: new(big.Int).SetUint64(self.block), BaseFee: big.NewInt(params.GWei)}, nil
+}
+
+func (self *fakeClient) BalanceAt(context.Context, common.Address, *big.Int) (*big.Int, error) {
+	return big.NewInt(params.Ether), nil
+}
+
+func (self *fakeClient) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	return self.nonce, nil
+}
+
+func (self *fakeClient) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return self.nonce, nil
+}
+
+func (self *fakeClient) FeeHistory(context.Context, uint64, *big.Int, []float64) (*ethereum.FeeHistory, error) {
+	return &ethereum.FeeHistory{BaseFee: []*big.Int{big.NewInt(params.GWei)}}, nil
+}
+
+func (self *fakeClient) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	if self.revert != nil && bytes.Equal(msg.Data, self.revert) {
+		return nil, errors.New("execution reverted: challenge already solved")
+	}
+	return nil, nil
+}
+
+func (self *fakeClient) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	return self.CallContract(ctx, msg, nil)
+}
+
+func (self *fakeClient) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	return cancelGasLimit, nil
+}
+
+func (self *fakeClient) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.sent = append(self.sent, tx)
+	return nil
+}
+
+func (self *fakeClient) TransactionByHash(context.Context, common.Hash) (*types.Transaction, bool, error) {
+	return &types.Transaction{}, true, nil
+}
+
+func (self *fakeClient) TransactionReceipt(_ context.Context, hash common.Hash) (*types.Receipt, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if r, ok := self.receipts[hash]; ok {
+		return r, nil
+	}
+	if self.mine == nil {
+		return nil, ethereum.NotFound
+	}
+	for _, tx := range self.sent {
+		if tx.Hash() == hash && self.mine(tx) {
+			r := &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: tx.Gas(), BlockNumber: new(big.Int).SetUint64(self.block)}
+			self.receipts[hash] = r
+			return r, nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+type fakeCosts struct {
+	mtx   sync.Mutex
+	costs []float64
+}
+
+func (self *fakeCosts) AddCost(_ common.Address, cost float64) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.costs = append(self.costs, cost)
+}
+
+// newTestTransactor returns a transactor with a new account.
+func newTestTransactor(t *testing.T, cfg Config, client Client) *TransactorDefault {
+	key, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	return &TransactorDefault{
+		netID:  big.NewInt(1),
+		cfg:    cfg,
+		logger: log.NewNopLogger(),
+		client: client,
+		bump:   &Linear{},
+		account: &tEthereum.Account{
+			Address:    crypto.PubkeyToAddress(key.PublicKey),
+			PrivateKey: key,
+			Signer:     tEthereum.NewKeySigner(key),
+		},
+	}
+}
+
+func TestResume(t *testing.T) {
+	ctx, cncl := context.WithCancel(context.Background())
+	defer cncl()
+	// Nonces 0 and 1 are used on chain.
+	client := newFakeClient(2)
+	client.revert = []byte{1, 2, 3, 4}
+	self := newTestTransactor(t, Config{}, client)
+	var err error
+	self.journal, err = NewJournal(log.NewNopLogger(), Config{LogLevel: "info", JournalFile: filepath.Join(t.TempDir(), "txJournal.log")})
+	testutil.Ok(t, err)
+
+	to := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	var txs []*types.Transaction
+	for nonce := uint64(0); nonce < 5; nonce++ {
+		var data []byte
+		deadline := time.Now().Add(time.Minute)
+		switch nonce {
+		case 2:
+			deadline = time.Now().Add(-time.Minute)
+		case 3:
+			data = client.revert
+		}
+		tx, err := self.account.Signer.SignTx(ctx, types.NewTransaction(nonce, to, big.NewInt(0), cancelGasLimit, big.NewInt(params.GWei), data), self.netID)
+		testutil.Ok(t, err)
+		testutil.Ok(t, self.journal.Sent(self.account.Address, tx, "submitMiningSolution", deadline))
+		txs = append(txs, tx)
+	}
+	// Nonce 0 was used by another transaction and nonce 1 was mined while not running.
+	client.receipts[txs[1].Hash()] = &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: cancelGasLimit, BlockNumber: big.NewInt(1)}
+
+	costs := &fakeCosts{}
+	self.costs = costs
+	testutil.Ok(t, self.Resume(ctx))
+
+	// The pending nonces are reserved so that new transactions don't replace them.
+	nonces := self.account.Nonces(client)
+	next, err := nonces.Next(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(5), next)
+	nonces.Release(next)
+
+	client.setMine(func(*types.Transaction) bool { return true })
+	states := make(map[uint64]string)
+	for start := time.Now(); len(states) < 5; time.Sleep(100 * time.Millisecond) {
+		testutil.Assert(t, time.Since(start) < 10*time.Second, "the journal nonces weren't closed:%v", states)
+		entries, err := self.journal.read()
+		testutil.Ok(t, err)
+		for _, e := range entries {
+			if e.State != JournalSent {
+				states[e.Nonce] = e.State
+			}
+		}
+	}
+	testutil.Equals(t, map[uint64]string{
+		0: JournalDropped,
+		1: JournalMined,
+		2: JournalCancelled,
+		3: JournalCancelled,
+		4: JournalMined,
+	}, states)
+	testutil.Equals(t, map[uint64]common.Hash{}, nonces.Pending())
+
+	// The past deadline and reverting transactions are replaced with cancels
+	// and the others are resent.
+	sent := make(map[uint64]*types.Transaction)
+	for _, tx := range client.sentTxs() {
+		sent[tx.Nonce()] = tx
+	}
+	testutil.Equals(t, 3, len(sent))
+	testutil.Equals(t, self.account.Address, *sent[2].To())
+	testutil.Equals(t, self.account.Address, *sent[3].To())
+	testutil.Equals(t, txs[4].Hash(), sent[4].Hash())
+
+	// The costs are added for the transaction mined while not running and the cancels.
+	cost := func(tx *types.Transaction) float64 {
+		return float64(tx.GasPrice().Int64()*int64(tx.Gas())) / 1e18
+	}
+	exp := []float64{cost(txs[1]), cost(sent[2]), cost(sent[3])}
+	sort.Float64s(exp)
+	costs.mtx.Lock()
+	defer costs.mtx.Unlock()
+	sort.Float64s(costs.costs)
+	testutil.Equals(t, exp, costs.costs)
+}
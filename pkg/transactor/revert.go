@@ -0,0 +1,97 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// retryReasons are the exact revert reasons of the oracle contracts caused by a condition
+// that clears with time so the same call can succeed later.
+// The TellorMesosphere contract has no such reverts.
+var retryReasons = map[string]bool{
+	// Tellor submitMiningSolution when the miner won a reward in the last 15 minutes.
+	"Miner can only win rewards once per 15 min": true,
+}
+
+// ErrReverted is returned when the simulation of a transaction reverts.
+type ErrReverted struct {
+	Reason string
+	// Retry is true when the same call can succeed later.
+	// Otherwise the call will never succeed, for example the challenge
+	// is already completed, and it should be skipped.
+	Retry bool
+}
+
+func (self ErrReverted) Error() string {
+	action := "skip"
+	if self.Retry {
+		action = "retry"
+	}
+	return fmt.Sprintf("transaction reverted action:%v, reason:%v", action, self.Reason)
+}
+
+func newErrReverted(reason string) ErrReverted {
+	return ErrReverted{Reason: reason, Retry: retryReasons[reason]}
+}
+
+// simulate executes the transaction with eth_call against the pending state
+// and returns ErrReverted with the decoded revert reason when it would fail.
+func (self *TransactorDefault) simulate(ctx context.Context, tx *types.Transaction) error {
+	if _, err := self.client.PendingCallContract(ctx, self.callMsg(tx)); err != nil {
+		if reason, ok := revertReason(err); ok {
+			return newErrReverted(reason)
+		}
+		return errors.Wrap(err, "simulating transaction")
+	}
+	return nil
+}
+
+// callMsg returns the call of the transaction without the fees.
+// With the fees the node checks that the balance covers the full gas limit
+// so a call with the max gas limit fails on a low balance account
+// even when the transaction with the estimated gas is affordable.
+// The balance is checked against the cost with the estimated gas before sending.
+func (self *TransactorDefault) callMsg(tx *types.Transaction) ethereum.CallMsg {
+	return ethereum.CallMsg{
+		From:  self.account.Address,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+}
+
+// revertReason extracts the revert reason from an eth_call error.
+// Nodes return the ABI encoded reason in the error data and
+// some of them include it only in the error message.
+func revertReason(err error) (string, bool) {
+	var errD rpc.DataError
+	if errors.As(err, &errD) {
+		if data, ok := errD.ErrorData().(string); ok {
+			if b, err := hexutil.Decode(data); err == nil {
+				if reason, err := abi.UnpackRevert(b); err == nil {
+					return reason, true
+				}
+			}
+		}
+	}
+
+	const reverted = "execution reverted"
+	msg := err.Error()
+	i := strings.Index(msg, reverted)
+	if i < 0 {
+		return "", false
+	}
+	return strings.TrimPrefix(msg[i+len(reverted):], ": "), true
+}
@@ -0,0 +1,63 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+type dataError struct {
+	data interface{}
+}
+
+func (self dataError) Error() string          { return "execution reverted" }
+func (self dataError) ErrorData() interface{} { return self.data }
+
+func TestRevertReason(t *testing.T) {
+	typ, err := abi.NewType("string", "", nil)
+	testutil.Ok(t, err)
+	encoded, err := abi.Arguments{{Type: typ}}.Pack("Miner can only win rewards once per 15 min")
+	testutil.Ok(t, err)
+	data := hexutil.Encode(append([]byte{0x08, 0xc3, 0x79, 0xa0}, encoded...))
+
+	reason, ok := revertReason(errors.Wrap(dataError{data: data}, "call"))
+	testutil.Assert(t, ok, "should decode the error data")
+	testutil.Equals(t, "Miner can only win rewards once per 15 min", reason)
+
+	reason, ok = revertReason(errors.New("execution reverted: Incorrect nonce for current challenge"))
+	testutil.Assert(t, ok, "should parse the error message")
+	testutil.Equals(t, "Incorrect nonce for current challenge", reason)
+
+	_, ok = revertReason(errors.New("connection refused"))
+	testutil.Assert(t, !ok, "not a revert")
+}
+
+// TestRetryReasons uses the revert reasons from the bytecode of the deployed contracts.
+func TestRetryReasons(t *testing.T) {
+	type testcase struct {
+		reason string
+		retry  bool
+	}
+	for _, tc := range []testcase{
+		// Tellor.
+		{reason: "Miner can only win rewards once per 15 min", retry: true},
+		{reason: "Incorrect nonce for current challenge"},
+		{reason: "Miner already submitted the value"},
+		{reason: "Miner status is not staker"},
+		// TellorMesosphere.
+		{reason: "Restricted to reporters."},
+		// Only the exact reasons are retried.
+		{reason: "Miner can only win rewards once per 15 minutes"},
+		{reason: "too soon"},
+	} {
+		t.Run(tc.reason, func(t *testing.T) {
+			testutil.Equals(t, tc.retry, newErrReverted(tc.reason).Retry)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Actions for transactions pending longer than the stuck timeout.
+const (
+	StuckActionBump   = "bump"
+	StuckActionCancel = "cancel"
+)
+
+// cancelGasLimit is the gas of a plain transfer.
+const cancelGasLimit = 21000
+
+var stuckTxs = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "telliot",
+	Subsystem: ComponentName,
+	Name:      "stuck_total",
+	Help:      "The total number of transactions pending longer than the stuck timeout by the action taken",
+},
+	[]string{"action"},
+)
+
+// ErrCancelled is returned when a stuck transaction is replaced with a cancel transaction.
+var ErrCancelled = errors.New("the stuck transaction was cancelled")
+
+// SpeedUp returns the transaction with the fees
+// so that it replaces the pending transaction with the same nonce.
+func SpeedUp(tx *types.Transaction, fees Fees) *types.Transaction {
+	return newTx(fees, tx.Nonce(), tx.To(), tx.Gas(), tx.Value(), tx.Data(), tx.ChainId())
+}
+
+// Cancel returns a 0 value self transfer with the nonce of the transaction
+// and the fees so that it replaces the pending transaction.
+func Cancel(tx *types.Transaction, from common.Address, fees Fees) *types.Transaction {
+	return newTx(fees, tx.Nonce(), &from, cancelGasLimit, big.NewInt(0), nil, tx.ChainId())
+}
+
+// MinReplacement returns the lowest fees with which the nodes accept a replacement of the transaction.
+// The fees are capped at the max, nil max means no cap.
+func MinReplacement(tx *types.Transaction, max *big.Int) Fees {
+	return txFees(tx).bump(1, max)
+}
+
+func txFees(tx *types.Transaction) Fees {
+	if tx.Type() == types.DynamicFeeTxType {
+		return Fees{GasFeeCap: tx.GasFeeCap(), GasTipCap: tx.GasTipCap()}
+	}
+	return Fees{GasPrice: tx.GasPrice()}
+}
+
+func newTx(fees Fees, nonce uint64, to *common.Address, gas uint64, value *big.Int, data []byte, chainID *big.Int) *types.Transaction {
+	if fees.dynamic() {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			To:        to,
+			Gas:       gas,
+			GasFeeCap: fees.GasFeeCap,
+			GasTipCap: fees.GasTipCap,
+			Value:     value,
+			Data:      data,
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		To:       to,
+		Gas:      gas,
+		GasPrice: fees.GasPrice,
+		Value:    value,
+		Data:     data,
+	})
+}
+
+// waitMined waits until one of the transactions sent with the nonce is mined.
+// When the last one is pending longer than the StuckTimeout it is replaced with higher fees
+// or cancelled after StuckMaxBumps replacements or when the StuckAction is cancel.
+// The replacement fees are from the bump strategy continuing from the attempt of the transaction.
+// Transactions sent to the relay are followed until they are included or sent to the public mempool.
+// When the cancel transaction is mined it returns it with its receipt and ErrCancelled and
+// when the context is done it returns the last transaction sent with the nonce.
+func (self *TransactorDefault) waitMined(ctx context.Context, tx *types.Transaction, relayed *relayedTx, attempt Attempt) (*types.Transaction, *types.Receipt, error) {
+	sent := []*types.Transaction{tx}
+	var cancelTx *types.Transaction
+	bumps := 0
+	lastSent := time.Now()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		for _, t := range sent {
+			receipt, err := self.client.TransactionReceipt(ctx, t.Hash())
+			if err != nil {
+				continue
+			}
+			self.relayIncluded(relayed, t, receipt)
+			if t == cancelTx {
+				return t, receipt, errors.Wrapf(ErrCancelled, "cancel tx:%v", t.Hash())
+			}
+			return t, receipt, nil
+		}
+
+		if self.cfg.StuckTimeout.Duration > 0 && cancelTx == nil && time.Since(lastSent) > self.cfg.StuckTimeout.Duration {
+			last := sent[len(sent)-1]
+			action := StuckActionCancel
+			if self.cfg.StuckAction == StuckActionBump && bumps < self.cfg.StuckMaxBumps {
+				action = StuckActionBump
+			}
+			stuckTxs.With(prometheus.Labels{"action": action}).Inc()
+			level.Warn(self.logger).Log("msg", "transaction is stuck", "action", action, "tx", last.Hash(), "nonce", last.Nonce(), "pending", time.Since(lastSent))
+
+			next := attempt
+			next.N++
+			next.Prev = txFees(last)
+			signed, err := self.replace(ctx, last, next, action == StuckActionCancel)
+			var r *relayedTx
+			if err == nil {
+				r, err = self.send(ctx, signed)
+			}
+			if err != nil {
+				level.Error(self.logger).Log("msg", "replacing stuck transaction", "action", action, "err", err)
+			} else {
+				relayed = r
+				attempt = next
+				sent = append(sent, signed)
+				self.account.Nonces(self.client).Sent(signed.Nonce(), signed.Hash())
+				if action == StuckActionCancel {
+					cancelTx = signed
+				} else {
+					bumps++
+				}
+				level.Info(self.logger).Log("msg", "replaced stuck transaction", "action", action, "tx", signed.Hash())
+			}
+			lastSent = time.Now()
+		}
+
+		self.followRelay(ctx, relayed)
+
+		select {
+		case <-ctx.Done():
+			return sent[len(sent)-1], nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// replace returns the signed replacement of the transaction or its cancel
+// with the fees of the attempt from the bump strategy.
+// The fees are capped at the max price and the replacement fails
+// when the cap doesn't allow fees high enough to replace the transaction.
+func (self *TransactorDefault) replace(ctx context.Context, tx *types.Transaction, attempt Attempt, cancel bool) (*types.Transaction, error) {
+	fees, _, err := self.bump.Fees(ctx, attempt)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the replacement fees")
+	}
+	fees = fees.capped(self.maxPrice())
+	if !fees.replaces(attempt.Prev) {
+		return nil, errors.Errorf("the capped fees are too low to replace the transaction maxPrice:%v", fees.maxPrice())
+	}
+	next := SpeedUp(tx, fees)
+	if cancel {
+		next = Cancel(tx, self.account.Address, fees)
+	}
+	return self.account.Signer.SignTx(ctx, next, self.netID)
+}
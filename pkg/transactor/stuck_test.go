@@ -0,0 +1,167 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/reward"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestReplacements(t *testing.T) {
+	to := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	from := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     7,
+		To:        &to,
+		Gas:       300000,
+		GasFeeCap: big.NewInt(100),
+		GasTipCap: big.NewInt(10),
+		Value:     big.NewInt(0),
+		Data:      []byte{1, 2, 3},
+	})
+
+	speedUp := SpeedUp(tx, MinReplacement(tx, nil))
+	testutil.Equals(t, tx.Nonce(), speedUp.Nonce())
+	testutil.Equals(t, tx.Data(), speedUp.Data())
+	testutil.Equals(t, big.NewInt(112), speedUp.GasFeeCap())
+	testutil.Equals(t, big.NewInt(12), speedUp.GasTipCap())
+
+	cancel := Cancel(tx, from, MinReplacement(tx, big.NewInt(110)))
+	testutil.Equals(t, tx.Nonce(), cancel.Nonce())
+	testutil.Equals(t, from, *cancel.To())
+	testutil.Equals(t, uint64(cancelGasLimit), cancel.Gas())
+	testutil.Equals(t, 0, len(cancel.Data()))
+	testutil.Equals(t, big.NewInt(110), cancel.GasFeeCap())
+
+	legacyTx := types.NewTransaction(3, to, big.NewInt(0), 21000, big.NewInt(1000), nil)
+	legacy := SpeedUp(legacyTx, MinReplacement(legacyTx, nil))
+	testutil.Equals(t, uint8(types.LegacyTxType), legacy.Type())
+	testutil.Equals(t, big.NewInt(1120), legacy.GasPrice())
+}
+
+func TestWaitMinedStuck(t *testing.T) {
+	ctx, cncl := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cncl()
+	client := newFakeClient(0)
+	// Only the cancel transaction is mined.
+	client.setMine(func(tx *types.Transaction) bool { return len(tx.Data()) == 0 })
+	self := newTestTransactor(t, Config{
+		StuckTimeout:  format.Duration{Duration: 100 * time.Millisecond},
+		StuckAction:   StuckActionBump,
+		StuckMaxBumps: 2,
+	}, client)
+
+	to := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	tx, err := self.account.Signer.SignTx(ctx, types.NewTransaction(3, to, big.NewInt(0), 300000, big.NewInt(params.GWei), []byte{1, 2, 3, 4}), self.netID)
+	testutil.Ok(t, err)
+
+	_, _, err = self.waitMined(ctx, tx, nil, Attempt{Start: time.Now(), Base: txFees(tx)})
+	testutil.Assert(t, errors.Is(err, ErrCancelled), "unexpected error:%v", err)
+
+	// Every replacement has higher fees and the last one is the cancel.
+	sent := client.sentTxs()
+	testutil.Equals(t, 3, len(sent))
+	for i, s := range sent {
+		testutil.Equals(t, tx.Nonce(), s.Nonce())
+		testutil.Equals(t, bumpPrice(tx.GasPrice(), i+1), s.GasPrice())
+	}
+	testutil.Equals(t, tx.Data(), sent[0].Data())
+	testutil.Equals(t, tx.Data(), sent[1].Data())
+	testutil.Equals(t, self.account.Address, *sent[2].To())
+	testutil.Equals(t, 0, len(sent[2].Data()))
+	testutil.Equals(t, map[uint64]common.Hash{3: sent[2].Hash()}, self.account.Nonces(client).Pending())
+}
+
+func TestWaitMinedRewardCapped(t *testing.T) {
+	ctx, cncl := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cncl()
+	client := newFakeClient(0)
+	client.setMine(func(tx *types.Transaction) bool { return false })
+	self := newTestTransactor(t, Config{
+		StuckTimeout:  format.Duration{Duration: 100 * time.Millisecond},
+		StuckAction:   StuckActionBump,
+		StuckMaxBumps: 5,
+		GasMax:        10000,
+	}, client)
+
+	// The reward of 1 ETH covers a cost of 0.5 ETH with a 100% profit
+	// so the max price is 0.5 ETH / 100000 gas.
+	rewards, err := reward.New(log.NewNopLogger(), reward.Config{LogLevel: "info", Window: 1, Percentile: 50}, fakeAggr{}, fakeRewardContract{})
+	testutil.Ok(t, err)
+	rewards.SaveGasUsed(big.NewInt(1), 100000)
+	linear, err := NewBumpStrategy(BumpConfig{Strategy: BumpLinear, Step: 100})
+	testutil.Ok(t, err)
+	self.bump = NewRewardCapped(linear, rewards, 100)
+
+	to := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	tx, err := self.account.Signer.SignTx(ctx, types.NewTransaction(3, to, big.NewInt(0), 300000, big.NewInt(4e12), []byte{1, 2, 3, 4}), self.netID)
+	testutil.Ok(t, err)
+
+	_, _, err = self.waitMined(ctx, tx, nil, Attempt{Start: time.Now(), Base: txFees(tx)})
+	testutil.Assert(t, errors.Is(err, context.DeadlineExceeded), "unexpected error:%v", err)
+
+	// The first speed-up is capped at the reward max price instead of doubling the fees
+	// and the cap is too low for any further replacement.
+	sent := client.sentTxs()
+	testutil.Equals(t, 1, len(sent))
+	testutil.Equals(t, big.NewInt(5e12), sent[0].GasPrice())
+	testutil.Equals(t, tx.Data(), sent[0].Data())
+}
+
+func TestTransactStuckCancel(t *testing.T) {
+	ctx, cncl := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cncl()
+	client := newFakeClient(3)
+	// Only the cancel transaction is mined.
+	client.setMine(func(tx *types.Transaction) bool { return len(tx.Data()) == 0 })
+	self := newTestTransactor(t, Config{
+		GasLimitMax:  1000000,
+		StuckTimeout: format.Duration{Duration: 100 * time.Millisecond},
+		StuckAction:  StuckActionCancel,
+	}, client)
+	costs := &fakeCosts{}
+	self.costs = costs
+
+	to := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	_, _, err := self.Transact(ctx, func(auth *bind.TransactOpts) (*types.Transaction, error) {
+		return auth.Signer(auth.From, types.NewTx(&types.DynamicFeeTx{
+			ChainID:   self.netID,
+			Nonce:     auth.Nonce.Uint64(),
+			To:        &to,
+			Gas:       auth.GasLimit,
+			GasFeeCap: auth.GasFeeCap,
+			GasTipCap: auth.GasTipCap,
+			Data:      []byte{1, 2, 3, 4},
+		}))
+	})
+	testutil.Assert(t, errors.Is(err, ErrCancelled), "unexpected error:%v", err)
+
+	// The cancel is final so the nonce is done and its cost is recorded.
+	sent := client.sentTxs()
+	testutil.Equals(t, 2, len(sent))
+	cancel := sent[1]
+	testutil.Equals(t, self.account.Address, *cancel.To())
+	testutil.Equals(t, map[uint64]common.Hash{}, self.account.Nonces(client).Pending())
+	receipt, err := client.TransactionReceipt(ctx, cancel.Hash())
+	testutil.Ok(t, err)
+	price, err := EffectiveGasPrice(ctx, client, cancel, receipt)
+	testutil.Ok(t, err)
+	cost, _ := new(big.Float).Mul(new(big.Float).SetInt(price), new(big.Float).SetUint64(cancelGasLimit)).Float64()
+	costs.mtx.Lock()
+	defer costs.mtx.Unlock()
+	testutil.Equals(t, []float64{cost / 1e18}, costs.costs)
+}
@@ -11,12 +11,11 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/params"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/format"
 	"github.com/tellor-io/telliot/pkg/gasPrice"
 	"github.com/tellor-io/telliot/pkg/logging"
 )
@@ -24,14 +23,32 @@ import (
 const ComponentName = "transactor"
 
 type Config struct {
-	LogLevel      string
-	GasMax        uint
-	GasMultiplier int
+	LogLevel              string
+	GasMax                uint              `help:"Max gas price in legacy mode and max fee per gas in dynamic mode in gwei."`
+	GasMultiplier         int               `help:"Gas price multiplier in legacy mode."`
+	TxType                string            `help:"Transaction type - legacy(a single gas price), dynamic(EIP-1559 with a max fee and a priority fee) or auto(dynamic on chains with a base fee and legacy otherwise)."`
+	FeeHistoryBlocks      uint64            `help:"How many blocks of the eth_feeHistory to use for the priority fee in dynamic mode."`
+	PriorityFeePercentile float64           `help:"Percentile of the priority fees paid in the fee history blocks. The median of all blocks is used as the priority fee."`
+	MaxPriorityFee        uint              `help:"Max priority fee in gwei, 0 means no limit."`
+	BaseFeeMultiplier     float64           `help:"The max fee is the next block base fee multiplied by this plus the priority fee so that the transaction stays valid when the base fee grows."`
+	GasLimitMargin        float64           `help:"Percent added to the estimated gas to get the gas limit."`
+	GasLimitMax           uint64            `help:"Max gas limit of methods without a cap in GasLimitCaps."`
+	GasLimitCaps          map[string]uint64 `help:"Max gas limit per contract method name. Transactions with an estimate above the cap are not sent."`
+	StuckTimeout          format.Duration   `help:"How long to wait for a transaction to be mined before replacing it. 0 waits until the submit is canceled."`
+	StuckAction           string            `help:"What to do with a stuck transaction - bump(resend it with higher fees) or cancel(replace it with a 0 value self transfer)."`
+	StuckMaxBumps         int               `help:"How many times to bump a stuck transaction before cancelling it."`
+	JournalFile           string            `help:"Journal of all sent transactions used to resume the pending ones after a restart."`
+	Relay                 RelayConfig
 }
 
 // Transactor takes care of sending transactions over the blockchain network.
 type Transactor interface {
+	// Transact simulates the transaction against the pending state and sends it when it succeeds.
+	// A simulation that reverts returns ErrReverted without sending the transaction.
 	Transact(context.Context, func(*bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, *types.Receipt, error)
+	// Build returns the signed transaction that the first Transact attempt would send without sending it.
+	// Same as Transact it returns ErrReverted when the simulation reverts.
+	Build(context.Context, func(*bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error)
 }
 
 // TransactorDefault implements the Transactor interface.
@@ -40,21 +57,50 @@ type TransactorDefault struct {
 	cfg             Config
 	logger          log.Logger
 	gasPriceQuerier gasPrice.GasPriceQuerier
-	client          *ethclient.Client
+	client          Client
 	account         *ethereum.Account
+	bump            BumpStrategy
+	relay           *Relay
+	journal         *Journal
+	costs           CostTracker
 }
 
 func New(
 	logger log.Logger,
 	cfg Config,
 	gasPriceQuerier gasPrice.GasPriceQuerier,
-	client *ethclient.Client,
+	client Client,
 	account *ethereum.Account,
+	bump BumpStrategy,
+	journal *Journal,
+	costs CostTracker,
 ) (*TransactorDefault, error) {
 	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "apply filter logger")
 	}
+	switch cfg.TxType {
+	case TxTypeAuto, TxTypeLegacy, TxTypeDynamic:
+	default:
+		return nil, errors.Errorf("unknown transaction type:%v", cfg.TxType)
+	}
+	switch cfg.StuckAction {
+	case StuckActionBump, StuckActionCancel:
+	default:
+		return nil, errors.Errorf("unknown stuck transaction action:%v", cfg.StuckAction)
+	}
+	if cfg.GasLimitMax == 0 {
+		return nil, errors.New("the gas limit max can't be 0")
+	}
+	logger = log.With(logger, "component", ComponentName)
+
+	var relay *Relay
+	if cfg.Relay.URL != "" {
+		relay, err = NewRelay(logger, cfg.Relay)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating relay")
+		}
+	}
 
 	ctx, cncl := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cncl()
@@ -66,94 +112,111 @@ func New(
 	return &TransactorDefault{
 		netID:           netID,
 		cfg:             cfg,
-		logger:          log.With(logger, "component", ComponentName),
+		logger:          logger,
 		gasPriceQuerier: gasPriceQuerier,
 		client:          client,
 		account:         account,
+		bump:            bump,
+		relay:           relay,
+		journal:         journal,
+		costs:           costs,
 	}, nil
 }
 
 func (self *TransactorDefault) Transact(ctx context.Context, contractCall func(*bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, *types.Receipt, error) {
-	nonce, err := self.client.NonceAt(ctx, self.account.Address, nil)
+	fees, err := self.fees(ctx)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "getting nonce for miner address")
+		return nil, nil, err
 	}
 
-	// Use the same nonce in case there is a stuck transaction so that it resubmits the same TX with higher gas price.
-	IntNonce := int64(nonce)
-
-	gasPrice, err := self.gasPriceQuerier.Query(ctx)
+	// The nonce manager is shared with all other transactors of the account
+	// so that their transactions don't replace each other.
+	nonces := self.account.Nonces(self.client)
+	nonce, err := nonces.Next(ctx)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "getting data from the db")
-	}
-
-	mul := self.cfg.GasMultiplier
-	if mul > 0 {
-		level.Info(self.logger).Log("msg", "settings gas price multiplier", "value", mul)
-		gasPrice = gasPrice.Mul(gasPrice, big.NewInt(int64(mul)))
+		return nil, nil, errors.Wrap(err, "getting nonce for miner address")
 	}
+	sent := false
+	defer func() {
+		if !sent {
+			nonces.Release(nonce)
+		}
+	}()
 
+	start := time.Now()
 	var finalError error
 	for i := 0; i <= 5; i++ {
-		balance, err := self.client.BalanceAt(ctx, self.account.Address, nil)
+		// An attempt is broadcast only when it is sent successfully and then it isn't retried
+		// so the attempt Prev is set only when the nonce has a pending abandoned transaction.
+		// Stuck replacements of the broadcast attempt continue from it in waitMined.
+		attempt := Attempt{N: i, Start: start, Base: fees}
+		if abandoned := nonces.Abandoned(nonce); abandoned != nil {
+			attempt.Prev = txFees(abandoned)
+		}
+		attemptFees, delay, err := self.bump.Fees(ctx, attempt)
 		if err != nil {
-			finalError = err
-			continue
+			return nil, nil, errors.Wrap(err, "getting the attempt fees")
 		}
-
-		cost := big.NewInt(1)
-		cost = cost.Mul(gasPrice, big.NewInt(200000))
-		if balance.Cmp(cost) < 0 {
-			finalError = errors.Errorf("insufficient funds to send transaction: %v < %v", balance, cost)
-			continue
+		attemptFees = attemptFees.capped(self.maxPrice())
+		if i > 0 {
+			level.Info(self.logger).Log("msg", "increased the fees", "attempt", i, "gasPrice", attemptFees.GasPrice, "maxFee", attemptFees.GasFeeCap, "priorityFee", attemptFees.GasTipCap)
 		}
 
-		auth, err := bind.NewKeyedTransactorWithChainID(self.account.PrivateKey, self.netID)
+		auth, err := self.transactOpts(ctx, int64(nonce), attemptFees)
 		if err != nil {
-			return nil, nil, errors.Wrap(err, "creating transactor")
-		}
-		auth.Nonce = big.NewInt(IntNonce)
-		auth.Value = big.NewInt(0)      // in weiF
-		auth.GasLimit = uint64(3000000) // in units
-		if gasPrice.Cmp(big.NewInt(0)) == 0 {
-			gasPrice = big.NewInt(100)
+			return nil, nil, err
 		}
-		if i > 1 {
-			gasPrice1 := new(big.Int).Set(gasPrice)
-			gasPrice1.Mul(gasPrice1, big.NewInt(int64(i*11))).Div(gasPrice1, big.NewInt(int64(100)))
-			auth.GasPrice = gasPrice1.Add(gasPrice, gasPrice1)
-		} else {
-			// First time, try base gas price.
-			auth.GasPrice = gasPrice
-		}
-		max := self.cfg.GasMax
-		var maxGasPrice *big.Int
-		gasPrice1 := big.NewInt(params.GWei)
-		if max > 0 {
-			maxGasPrice = gasPrice1.Mul(gasPrice1, big.NewInt(int64(max)))
-		} else {
-			maxGasPrice = gasPrice1.Mul(gasPrice1, big.NewInt(int64(100)))
+		auth.NoSend = true
+
+		auth.GasLimit, err = self.prepare(ctx, contractCall, auth)
+		if err != nil {
+			// A revert fails the same way on every attempt.
+			var errR ErrReverted
+			if errors.As(err, &errR) {
+				return nil, nil, err
+			}
+			finalError = errors.Wrap(err, "preparing transaction")
+			level.Info(self.logger).Log("msg", "will retry a send", "retryDelay", delay, "err", err)
+			select {
+			case <-ctx.Done():
+				return nil, nil, errors.New("the submit context was canceled")
+			case <-time.After(delay):
+				continue
+			}
 		}
 
-		if auth.GasPrice.Cmp(maxGasPrice) > 0 {
-			level.Info(self.logger).Log("msg", "gas price too high, will default to the max price", "current", auth.GasPrice, "defaultMax", maxGasPrice)
-			auth.GasPrice = maxGasPrice
+		balance, err := self.client.BalanceAt(ctx, self.account.Address, nil)
+		if err != nil {
+			finalError = err
+			continue
+		}
+		if cost := cost(auth); balance.Cmp(cost) < 0 {
+			finalError = errors.Errorf("insufficient funds to send transaction: %v < %v", balance, cost)
+			continue
 		}
 
+		var relayed *relayedTx
 		tx, err := contractCall(auth)
+		if err == nil {
+			relayed, err = self.send(ctx, tx)
+		}
 		if err != nil {
 			if strings.Contains(strings.ToLower(err.Error()), "nonce too low") { // Can't use error type matching because of the way the eth client is implemented.
-				IntNonce = IntNonce + 1
-				level.Warn(self.logger).Log("msg", "last transaction has been confirmed so will increase the nonce and resend the transaction.")
+				level.Warn(self.logger).Log("msg", "the nonce is already used so will re-sync the nonce and resend the transaction.")
+				nonces.Release(nonce)
+				nonces.Reset()
+				nonce, err = nonces.Next(ctx)
+				if err != nil {
+					return nil, nil, errors.Wrap(err, "re-sync nonce")
+				}
 
 			} else if strings.Contains(strings.ToLower(err.Error()), "replacement transaction underpriced") { // Can't use error type matching because of the way the eth client is implemented.
-				level.Warn(self.logger).Log("msg", "last transaction is stuck so will increase the gas price and try to resend")
+				level.Warn(self.logger).Log("msg", "last transaction is stuck so will increase the fees and try to resend")
 				finalError = err
 			} else {
 				finalError = errors.Wrap(err, "contract call")
 			}
 
-			delay := 15 * time.Second
 			level.Info(self.logger).Log("msg", "will retry a send", "retryDelay", delay)
 			select {
 			case <-ctx.Done():
@@ -162,12 +225,82 @@ func (self *TransactorDefault) Transact(ctx context.Context, contractCall func(*
 				continue
 			}
 		}
+		sent = true
+		nonces.Sent(nonce, tx.Hash())
 
-		receipt, err := bind.WaitMined(ctx, self.client, tx)
+		mined, receipt, err := self.waitMined(ctx, tx, relayed, attempt)
+		if errors.Is(err, ErrCancelled) {
+			// The mined cancel is the final state of the nonce.
+			nonces.Done(nonce)
+			self.journalClose(nonce, JournalCancelled, mined.Hash())
+			self.addCost(ctx, mined, receipt)
+		} else if err != nil {
+			// The transaction isn't waited for anymore, but it is still pending
+			// so the next transaction with the nonce replaces it with higher fees.
+			nonces.Abandon(nonce, mined)
+		}
 		if err != nil {
 			return nil, nil, errors.Wrapf(err, "transaction result tx:%v", tx.Hash())
 		}
-		return tx, receipt, nil
+		nonces.Done(nonce)
+		self.journalClose(nonce, JournalMined, mined.Hash())
+		return mined, receipt, nil
 	}
 	return nil, nil, errors.Wrapf(finalError, "submit tx after 5 attempts")
 }
+
+func (self *TransactorDefault) Build(ctx context.Context, contractCall func(*bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error) {
+	fees, err := self.fees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The nonce is only peeked as the transaction is never sent.
+	nonces := self.account.Nonces(self.client)
+	nonce, err := nonces.Next(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting nonce for miner address")
+	}
+	nonces.Release(nonce)
+	auth, err := self.transactOpts(ctx, int64(nonce), fees)
+	if err != nil {
+		return nil, err
+	}
+	auth.NoSend = true
+
+	auth.GasLimit, err = self.prepare(ctx, contractCall, auth)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := contractCall(auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "contract call")
+	}
+	return tx, nil
+}
+
+// gasPrice returns the base gas price for a transaction.
+func (self *TransactorDefault) gasPrice(ctx context.Context) (*big.Int, error) {
+	gasPrice, err := self.gasPriceQuerier.Query(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting data from the db")
+	}
+
+	mul := self.cfg.GasMultiplier
+	if mul > 0 {
+		level.Info(self.logger).Log("msg", "settings gas price multiplier", "value", mul)
+		gasPrice = gasPrice.Mul(gasPrice, big.NewInt(int64(mul)))
+	}
+	return gasPrice, nil
+}
+
+// transactOpts returns the transaction options with the nonce and fees.
+func (self *TransactorDefault) transactOpts(ctx context.Context, nonce int64, fees Fees) (*bind.TransactOpts, error) {
+	auth, err := self.account.TransactOpts(ctx, self.netID)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating transactor")
+	}
+	auth.Nonce = big.NewInt(nonce)
+	auth.Value = big.NewInt(0) // in wei
+	fees.apply(auth)
+	return auth, nil
+}
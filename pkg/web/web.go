@@ -32,6 +32,11 @@ type Config struct {
 	ReadTimeout format.Duration
 }
 
+// Registerer adds additional endpoints to the api.
+type Registerer interface {
+	Register(r *route.Router)
+}
+
 type Web struct {
 	logger log.Logger
 	cfg    Config
@@ -40,7 +45,7 @@ type Web struct {
 	srv    *http.Server
 }
 
-func New(logger log.Logger, ctx context.Context, tsDB storage.SampleAndChunkQueryable, cfg Config) (*Web, error) {
+func New(logger log.Logger, ctx context.Context, tsDB storage.SampleAndChunkQueryable, cfg Config, registerers ...Registerer) (*Web, error) {
 	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "apply filter logger")
@@ -64,6 +69,9 @@ func New(logger log.Logger, ctx context.Context, tsDB storage.SampleAndChunkQuer
 
 	api := api.New(logger, ctx, engine, tsDB)
 	api.Register(router.WithPrefix("/api/v1"))
+	for _, r := range registerers {
+		r.Register(router.WithPrefix("/api/v1"))
+	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/", router)